@@ -1,75 +1,377 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"ethereum-tx-parser/internal/api"
+	"ethereum-tx-parser/internal/grpcapi"
+	"ethereum-tx-parser/internal/logging"
+	"ethereum-tx-parser/internal/model"
 	"ethereum-tx-parser/internal/service"
-	"log"
+	"ethereum-tx-parser/internal/tracing"
+	"flag"
+	"log/slog"
 	"os"
 	"os/signal"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
+var processorTracer = tracing.Tracer("processor")
+
 func main() {
-	// Initialize logging
-	logger := log.New(os.Stdout, "ethereum-parser: ", log.LstdFlags|log.Lshortfile)
+	flag.BoolVar(&service.AutoSubscribeContracts, "auto-subscribe-contracts", false, "subscribe contracts deployed by subscribed addresses")
+	mempoolInterval := flag.Duration("mempool-interval", 0, "poll the node's txpool for pending transactions at this interval (0 disables)")
+	monitorInterval := flag.Duration("monitor-interval", 0, "check subscribed senders for nonce gaps and stuck transactions at this interval (0 disables)")
+	flag.DurationVar(&service.StuckAfter, "stuck-after", service.StuckAfter, "report transactions pending longer than this as stuck")
+	flag.StringVar(&service.VerificationMode, "verify-transactions", service.VerifyOff, "verify hash and sender of matched transactions: off, flag or reject")
+	flag.Uint64Var(&service.HomesteadBlock, "homestead-block", service.HomesteadBlock, "first block whose legacy transactions must have low-s signatures")
+	flag.BoolVar(&service.VerifyBlocks, "verify-blocks", false, "verify each block's transactions root and header hash before advancing")
+	flag.StringVar(&service.WebhookStatePath, "webhook-state", "", "persist webhooks and their delivery queue to this file (empty keeps them in memory)")
+	flag.IntVar(&service.WebhookMaxAttempts, "webhook-max-attempts", service.WebhookMaxAttempts, "dead-letter webhook deliveries after this many failed attempts")
+	flag.IntVar(&service.WebhookDeadHistory, "webhook-dead-history", service.WebhookDeadHistory, "keep at most this many dead-lettered webhook deliveries, dropping the oldest")
+	flag.BoolVar(&service.WebhookAllowPrivate, "webhook-allow-private", false, "allow webhooks to loopback, private and link-local addresses")
+	flag.BoolVar(&service.RequireAPIKey, "require-api-key", false, "reject requests without a valid API key")
+	flag.StringVar(&service.AdminToken, "admin-token", os.Getenv("TXPARSER_ADMIN_TOKEN"), "token for the /v1/admin key management endpoints (default from $TXPARSER_ADMIN_TOKEN; empty disables them)")
+	flag.StringVar(&service.APIKeyStatePath, "api-key-state", "", "persist API key hashes to this file (empty keeps them in memory)")
+	flag.Float64Var(&service.RateLimitPerSecond, "rate-limit", 0, "requests per second allowed to each API key or client address (0 disables)")
+	flag.IntVar(&service.RateLimitBurst, "rate-limit-burst", service.RateLimitBurst, "requests a client may make at once before -rate-limit applies")
+	flag.IntVar(&service.MaxSubscriptionsPerTenant, "max-subscriptions", 0, "addresses each tenant may subscribe to (0 means no limit)")
+	dataDir := flag.String("data-dir", "", "load and periodically snapshot storage in this directory (empty keeps it in memory)")
+	grpcAddr := flag.String("grpc-addr", ":9090", "serve the gRPC API on this address (empty disables)")
+	metricsAddr := flag.String("metrics-addr", "", "also serve /metrics without authentication on this address (empty serves it only on the API)")
+	flag.Int64Var(&service.ReadyMaxLag, "ready-max-lag", service.ReadyMaxLag, "report not ready in /readyz when more than this many blocks behind the chain head")
+	flag.IntVar(&service.MaxConcurrentBackfills, "max-concurrent-backfills", service.MaxConcurrentBackfills, "backfill jobs scanning blocks at once; later jobs wait queued")
+	flag.IntVar(&service.MaxQueuedBackfills, "max-queued-backfills", service.MaxQueuedBackfills, "backfill jobs that may wait for a free slot; further subscribe requests with a backfill are refused")
+	flag.Uint64Var(&service.MaxBackfillBlocks, "max-backfill-blocks", service.MaxBackfillBlocks, "most blocks one backfill requested on subscribe may cover")
+	flag.Uint64Var(&service.MaxReprocessBlocks, "max-reprocess-blocks", service.MaxReprocessBlocks, "most blocks one /v1/admin/processor/reprocess request may cover")
+	flag.DurationVar(&service.ReadyRPCWindow, "ready-rpc-window", service.ReadyRPCWindow, "report not ready in /readyz when no call to the node succeeded for this long")
+	httpAddr := flag.String("http-addr", ":8080", "serve the HTTP API on this address")
+	var tlsConfig api.TLSConfig
+	flag.StringVar(&tlsConfig.CertFile, "tls-cert", "", "serve HTTPS and gRPC over TLS with this PEM certificate chain (reloaded on SIGHUP)")
+	flag.StringVar(&tlsConfig.KeyFile, "tls-key", "", "PEM private key of -tls-cert")
+	flag.StringVar(&tlsConfig.ClientCAFile, "tls-client-ca", "", "verify client certificates against this PEM CA bundle")
+	flag.BoolVar(&tlsConfig.RequireClientCert, "tls-require-client-cert", false, "reject TLS clients without a certificate signed by -tls-client-ca")
+	corsOrigins := flag.String("cors-origins", strings.Join(api.CORS.AllowedOrigins, ","), "comma-separated origins allowed to make cross-origin requests, or * for any (empty disables CORS)")
+	corsMethods := flag.String("cors-methods", strings.Join(api.CORS.AllowedMethods, ","), "comma-separated methods allowed in cross-origin requests")
+	corsHeaders := flag.String("cors-headers", strings.Join(api.CORS.AllowedHeaders, ","), "comma-separated request headers allowed in cross-origin requests")
+	flag.DurationVar(&api.CORS.MaxAge, "cors-max-age", api.CORS.MaxAge, "how long browsers may cache a CORS preflight response")
+	flag.BoolVar(&api.SecurityHeaders, "security-headers", true, "send nosniff, frame, referrer and content security policy headers")
+	flag.DurationVar(&api.HSTSMaxAge, "hsts-max-age", api.HSTSMaxAge, "Strict-Transport-Security max-age sent over TLS (0 omits it)")
+	logFormat := flag.String("log-format", logging.FormatText, "write logs to stderr as text or json")
+	logLevel := flag.String("log-level", "info", "log records at or above this level: debug, info, warn or error (changeable at runtime through /v1/admin/log-level)")
+	traceConfig := tracing.Config{ServiceName: "ethereum-tx-parser", ServiceVersion: service.Version}
+	flag.StringVar(&traceConfig.Exporter, "trace-exporter", tracing.ExporterNone, "export trace spans: none, stdout or otlp")
+	flag.StringVar(&traceConfig.Endpoint, "trace-endpoint", "", "OTLP/HTTP endpoint URL for -trace-exporter otlp (default from $OTEL_EXPORTER_OTLP_ENDPOINT, or http://localhost:4318)")
+	flag.StringVar(&traceConfig.File, "trace-file", "", "append spans to this file instead of stdout with -trace-exporter stdout")
+	flag.Float64Var(&traceConfig.SampleRatio, "trace-sample-ratio", 1, "fraction of new traces to record; requests carrying a traceparent follow the caller's decision")
+	flag.Parse()
+
+	logger := logging.For("server")
+	if err := logging.Setup(os.Stderr, *logFormat, *logLevel); err != nil {
+		fatal(logger, "invalid logging flags", err)
+	}
+	shutdownTracing, err := tracing.Setup(context.Background(), traceConfig)
+	if err != nil {
+		fatal(logger, "failed to set up tracing", err)
+	}
 
-	// Initialize in-memory storage
-	service.InitializeModelLayer()
+	api.CORS.AllowedOrigins = splitList(*corsOrigins)
+	api.CORS.AllowedMethods = splitList(*corsMethods)
+	api.CORS.AllowedHeaders = splitList(*corsHeaders)
+
+	switch service.VerificationMode {
+	case service.VerifyOff, service.VerifyFlag, service.VerifyReject:
+	default:
+		fatal(logger, "invalid -verify-transactions mode", nil, "mode", service.VerificationMode)
+	}
+
+	if err := service.LoadWebhooks(); err != nil {
+		fatal(logger, "failed to load webhooks", err)
+	}
+	if err := service.LoadAPIKeys(); err != nil {
+		fatal(logger, "failed to load API keys", err)
+	}
+
+	var certs *api.CertReloader
+	if tlsConfig.CertFile != "" || tlsConfig.KeyFile != "" {
+		var err error
+		if certs, err = api.NewCertReloader(tlsConfig); err != nil {
+			fatal(logger, "failed to load TLS certificates", err)
+		}
+		go reloadCertsOnHangup(logger, certs)
+	}
+
+	// Initialize storage, from the last snapshot if a data directory is given. The lock keeps
+	// txparser from writing the snapshot this server overwrites; from here on the server exits
+	// through the shutdown below, which releases it.
+	unlock := func() error { return nil }
+	if *dataDir != "" {
+		if unlock, err = model.LockDataDir(*dataDir); err != nil {
+			fatal(logger, "failed to lock data directory", err, "dir", *dataDir)
+		}
+		if err := service.LoadModelLayer(*dataDir); err != nil {
+			unlock()
+			fatal(logger, "failed to load storage", err, "dir", *dataDir)
+		}
+	} else {
+		service.InitializeModelLayer()
+	}
+
+	// Run until a termination signal, or until a server fails
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ctx, cancel := context.WithCancel(ctx)
+	var workers sync.WaitGroup
+	failed := make(chan error, 1)
+	runServer := func(msg string, serve func(context.Context) error) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			if err := serve(ctx); err != nil {
+				logger.Error(msg, "error", err)
+				select {
+				case failed <- err:
+				default:
+				}
+			}
+		}()
+	}
 
 	// Start the block processing service in the background
-	stopChan := make(chan os.Signal, 1)
-	signal.Notify(stopChan, os.Interrupt, syscall.SIGTERM)
+	go startBlockProcessingService(logging.For("processor"))
+	if *mempoolInterval > 0 {
+		go startMempoolWatcher(logging.For("mempool"), *mempoolInterval)
+	}
+	if *monitorInterval > 0 {
+		go startPendingMonitor(logging.For("monitor"), *monitorInterval)
+	}
+	go startWebhookDispatcher(logging.For("webhook"))
+	if *dataDir != "" {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			startSnapshotter(ctx, logging.For("storage"), *dataDir, 5*time.Second)
+		}()
+	}
+	if *grpcAddr != "" {
+		var opts []grpc.ServerOption
+		if certs != nil {
+			opts = append(opts, grpc.Creds(credentials.NewTLS(certs.TLSConfig())))
+		}
+		runServer("gRPC server failed", func(ctx context.Context) error {
+			return grpcapi.StartServer(ctx, *grpcAddr, opts...)
+		})
+	}
+	if *metricsAddr != "" {
+		runServer("metrics server failed", func(ctx context.Context) error {
+			return api.StartMetricsServer(ctx, *metricsAddr)
+		})
+	}
+	runServer("server failed", func(ctx context.Context) error {
+		return api.StartServer(ctx, *httpAddr, certs)
+	})
 
-	go startBlockProcessingService(logger)
+	// Stop the servers, letting in-flight requests finish, then save the webhook queue, the final
+	// storage snapshot and flush buffered spans
+	var failure error
+	select {
+	case <-ctx.Done():
+		logger.Info("shutting down gracefully")
+	case failure = <-failed:
+		logger.Error("shutting down after a server failure")
+	}
+	cancel()
+	workers.Wait()
+	if err := service.SaveWebhooks(); err != nil {
+		logger.Error("failed to save webhook state", "path", service.WebhookStatePath, "error", err)
+	}
+	if *dataDir != "" {
+		if err := service.SaveModelLayer(*dataDir); err != nil {
+			logger.Error("failed to snapshot storage", "dir", *dataDir, "error", err)
+		}
+	}
+	if err := unlock(); err != nil {
+		logger.Error("failed to unlock data directory", "dir", *dataDir, "error", err)
+	}
+	flushCtx, flushCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer flushCancel()
+	if err := shutdownTracing(flushCtx); err != nil {
+		logger.Error("failed to flush traces", "error", err)
+	}
+	if failure != nil {
+		os.Exit(1)
+	}
+}
 
-	// Start the HTTP server
-	if err := api.StartServer(); err != nil {
-		logger.Fatalf("failed to start server: %v", err)
+// startBlockProcessingService runs the block fetching and transaction filtering loop. Each cycle
+// runs through service.RunProcessorCycle, so admins can pause the loop and rewind or reprocess
+// blocks between cycles.
+func startBlockProcessingService(logger *slog.Logger) {
+	for {
+		delay := time.Second // While paused, check again after this long
+		service.RunProcessorCycle(func() {
+			ctx, span := processorTracer.Start(context.Background(), "process block")
+			var err error
+			delay, err = processNextBlock(ctx, logger)
+			tracing.End(span, err)
+		})
+		time.Sleep(delay)
 	}
+}
 
-	// Wait for termination signal
-	<-stopChan
-	logger.Println("shutting down gracefully...")
+// processNextBlock runs one cycle of the processing loop, tracing each step in a child span of
+// ctx, and returns how long to wait before the next cycle
+func processNextBlock(ctx context.Context, logger *slog.Logger) (time.Duration, error) {
+	span := trace.SpanFromContext(ctx)
+	currentBlockNum, _ := service.GetBlockNumber()
+	span.SetAttributes(attribute.String("block.number", currentBlockNum))
+	if currentBlockNum == "0x0" {
+		logger.WarnContext(ctx, "failed to get current block number")
+		latestBlock, _ := service.GetLatestETHBlockContext(ctx)
+		service.SaveLatestBlock(latestBlock)
+		return 0, nil
+	}
 
+	latestBlock, err := service.GetLatestETHBlockContext(ctx)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to get latest Ethereum block", "error", err)
+		service.RecordSyncError(err)
+		return 2 * time.Second, err
+	}
+	span.SetAttributes(attribute.String("chain.head", latestBlock))
+	if latestBlock < currentBlockNum {
+		return time.Second, nil
+	}
+
+	var block model.Block
+	err = tracing.Run(ctx, processorTracer, "fetch block", func(ctx context.Context) (err error) {
+		block, err = service.GetEthBlockByNumberContext(ctx, currentBlockNum)
+		return err
+	})
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to fetch block", "block", currentBlockNum, "error", err)
+		service.RecordSyncError(err)
+		return 2 * time.Second, err
+	}
+
+	filterErr := tracing.Run(ctx, processorTracer, "filter transactions", func(ctx context.Context) error {
+		trace.SpanFromContext(ctx).SetAttributes(attribute.Int("block.transactions", len(block.Transactions)))
+		return service.FilterTransactionsByAddressContext(ctx, block.Transactions)
+	})
+	if filterErr != nil {
+		logger.ErrorContext(ctx, "failed to filter transactions", "block", currentBlockNum, "error", filterErr)
+		service.RecordSyncError(filterErr)
+	}
+	tracing.Run(ctx, processorTracer, "save block", func(context.Context) error {
+		service.RecordProcessedBlock(block)
+		return nil
+	})
+	logger.DebugContext(ctx, "processed block", "block", currentBlockNum, "transactions", len(block.Transactions), "head", latestBlock)
+
+	advanceErr := tracing.Run(ctx, processorTracer, "advance cursor", func(context.Context) error {
+		return service.AdvancePastBlock(block)
+	})
+	if advanceErr != nil {
+		logger.ErrorContext(ctx, "failed to increment block number", "block", currentBlockNum, "error", advanceErr)
+	}
+	return time.Second, errors.Join(filterErr, advanceErr)
 }
 
-// startBlockProcessingService runs the block fetching and transaction filtering loop
-func startBlockProcessingService(logger *log.Logger) {
+// startMempoolWatcher periodically records pending transactions for subscribed addresses
+func startMempoolWatcher(logger *slog.Logger, interval time.Duration) {
 	for {
-		currentBlockNum, _ := service.GetBlockNumber()
-		if currentBlockNum == "0x0" {
-			logger.Printf("failed to get current block number")
-			latestBlock, _ := service.GetLatestETHBlock()
-			service.SaveLatestBlock(latestBlock)
+		if err := service.PollPendingTransactions(); err != nil {
+			logger.Error("failed to poll pending transactions", "error", err)
+		}
+		time.Sleep(interval)
+	}
+}
 
-			continue
+// startPendingMonitor periodically checks subscribed senders for nonce gaps and stuck transactions
+func startPendingMonitor(logger *slog.Logger, interval time.Duration) {
+	go func() {
+		// Resubscribe if the listener is ever dropped for falling behind
+		for {
+			events, _ := service.SubscribeEvents(100)
+			for event := range events {
+				if event.Type == model.EventAlert {
+					logger.Warn("alert", "address", event.Address, "alert", event.Data)
+				}
+			}
 		}
+	}()
 
-		latestBlock, err := service.GetLatestETHBlock()
-		if err != nil {
-			logger.Printf("failed to get latest Ethereum block: %v", err)
-			time.Sleep(2 * time.Second)
-			continue
+	for {
+		if _, err := service.CheckPendingTransactions(); err != nil {
+			logger.Error("failed to check pending transactions", "error", err)
 		}
+		time.Sleep(interval)
+	}
+}
 
-		if latestBlock >= currentBlockNum {
-			block, err := service.GetEthBlockByNumber(currentBlockNum)
-			if err != nil {
-				logger.Printf("failed to fetch block %s: %v", currentBlockNum, err)
-				time.Sleep(2 * time.Second)
-				continue
-			}
+// startSnapshotter periodically writes the store to the data directory until ctx is done
+func startSnapshotter(ctx context.Context, logger *slog.Logger, dataDir string, interval time.Duration) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+		if err := service.SaveModelLayer(dataDir); err != nil {
+			logger.Error("failed to snapshot storage", "dir", dataDir, "error", err)
+		}
+	}
+}
 
-			if err := service.FilterTransactionsByAddress(block.Transactions); err != nil {
-				logger.Printf("failed to filter transactions for block %s: %v", currentBlockNum, err)
-			}
+// startWebhookDispatcher sends queued webhook deliveries as they become due, and saves the
+// webhook state after each round
+func startWebhookDispatcher(logger *slog.Logger) {
+	for {
+		service.DeliverDueWebhooks()
+		if err := service.SaveWebhooks(); err != nil {
+			logger.Error("failed to save webhook state", "path", service.WebhookStatePath, "error", err)
+		}
+		time.Sleep(time.Second)
+	}
+}
 
-			if err := service.IncrementBlockNumber(currentBlockNum); err != nil {
-				logger.Printf("failed to increment block number: %v", err)
-			}
+// reloadCertsOnHangup reloads the TLS certificates whenever the process receives SIGHUP
+func reloadCertsOnHangup(logger *slog.Logger, certs *api.CertReloader) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	for range hangup {
+		if err := certs.Reload(); err != nil {
+			logger.Error("failed to reload TLS certificates, keeping the current ones", "error", err)
+			continue
 		}
+		logger.Info("reloaded TLS certificates")
+	}
+}
 
-		time.Sleep(1 * time.Second)
+// fatal logs an error that prevents the server from running and exits
+func fatal(logger *slog.Logger, msg string, err error, args ...any) {
+	if err != nil {
+		args = append(args, "error", err)
+	}
+	logger.Error(msg, args...)
+	os.Exit(1)
+}
+
+// splitList splits a comma-separated flag value, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
 	}
+	return items
 }
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"ethereum-tx-parser/internal/model"
+	"ethereum-tx-parser/internal/service"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// localBackend runs commands against the storage snapshot a server with -data-dir writes.
+// Changes are saved back to the snapshot, which a server loads when it next starts; they are
+// refused while a server holds the directory, as it would overwrite them.
+type localBackend struct {
+	dataDir string
+
+	// pollInterval is how often Watch rereads the snapshot
+	pollInterval time.Duration
+}
+
+func newLocalBackend(dataDir string) *localBackend {
+	return &localBackend{dataDir: dataDir, pollInterval: 2 * time.Second}
+}
+
+// GetCurrentBlock returns the last block recorded in the snapshot
+func (b *localBackend) GetCurrentBlock() (int, error) {
+	if err := service.LoadModelLayer(b.dataDir); err != nil {
+		return 0, err
+	}
+	return service.LocalParser{}.GetCurrentBlock()
+}
+
+// Subscribe adds an address to the snapshot. Returns false if it was already subscribed.
+func (b *localBackend) Subscribe(address string) (bool, error) {
+	return b.update(service.LocalParser{}.Subscribe, address)
+}
+
+// Unsubscribe removes an address from the snapshot. Returns false if it was not subscribed.
+func (b *localBackend) Unsubscribe(address string) (bool, error) {
+	return b.update(service.LocalParser{}.Unsubscribe, address)
+}
+
+// GetTransactions returns the transactions of an address recorded in the snapshot
+func (b *localBackend) GetTransactions(address string) ([]model.Transaction, error) {
+	if err := service.LoadModelLayer(b.dataDir); err != nil {
+		return nil, err
+	}
+	return service.LocalParser{}.GetTransactions(address)
+}
+
+// WatchTransactions polls the snapshot and calls fn for each transaction of the addresses that
+// is new or has changed status since the previous poll. Transactions already present when the
+// watch starts are not reported.
+func (b *localBackend) WatchTransactions(ctx context.Context, addresses []string, fn func(model.Transaction) error) error {
+	for _, address := range addresses {
+		if err := service.ValidateAddress(address); err != nil {
+			return err
+		}
+	}
+
+	seen, err := b.poll(addresses, nil, nil)
+	if err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(b.pollInterval):
+		}
+		if seen, err = b.poll(addresses, seen, fn); err != nil {
+			return err
+		}
+	}
+}
+
+// poll reloads the snapshot and reports transactions whose status differs from seen, which maps
+// transaction hashes to the status last reported. It returns the updated map.
+func (b *localBackend) poll(addresses []string, seen map[string]string, fn func(model.Transaction) error) (map[string]string, error) {
+	if err := service.LoadModelLayer(b.dataDir); err != nil {
+		return seen, err
+	}
+
+	current := make(map[string]string)
+	for _, address := range addresses {
+		for _, tx := range model.SharedStore().GetTransactions(strings.ToLower(address)) {
+			if _, reported := current[tx.Hash]; reported {
+				continue
+			}
+			current[tx.Hash] = tx.Status
+			if status, ok := seen[tx.Hash]; fn != nil && (!ok || status != tx.Status) {
+				if err := fn(tx); err != nil {
+					return current, err
+				}
+			}
+		}
+	}
+	return current, nil
+}
+
+// update loads the snapshot, applies a subscription change and saves it if anything changed.
+// The data directory stays locked throughout.
+func (b *localBackend) update(change func(string) (bool, error), address string) (bool, error) {
+	unlock, err := model.LockDataDir(b.dataDir)
+	if errors.Is(err, model.ErrDataDirLocked) {
+		return false, fmt.Errorf("%s is in use by a running server; change subscriptions through it with -server", b.dataDir)
+	}
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	if err := service.LoadModelLayer(b.dataDir); err != nil {
+		return false, err
+	}
+	changed, err := change(address)
+	if err != nil || !changed {
+		return changed, err
+	}
+	return true, service.SaveModelLayer(b.dataDir)
+}
@@ -0,0 +1,153 @@
+// Command txparser queries and manages a transaction parser, either a running server through
+// pkg/client or, with -data-dir, the storage snapshot of a stopped server directly.
+package main
+
+import (
+	"context"
+	"errors"
+	"ethereum-tx-parser/internal/model"
+	"ethereum-tx-parser/internal/parser"
+	"ethereum-tx-parser/pkg/client"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+const usage = `usage: txparser [flags] <command> [args]
+
+commands:
+  current-block              print the last parsed block number
+  subscribe <address>        observe an address for transactions
+  unsubscribe <address>      stop observing an address
+  transactions <address>     print the stored transactions of an address
+  watch <address>...         print transactions of the addresses as they arrive
+
+flags:
+`
+
+// backend is a parser the commands run against
+type backend interface {
+	parser.Parser
+
+	// Unsubscribe stops observing an address. Returns false if it was not subscribed.
+	Unsubscribe(address string) (bool, error)
+
+	// WatchTransactions calls fn for each transaction of the addresses until ctx is done or fn fails
+	WatchTransactions(ctx context.Context, addresses []string, fn func(model.Transaction) error) error
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	os.Exit(run(ctx, os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes one command line and returns the process exit code
+func run(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("txparser", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		flags.PrintDefaults()
+	}
+
+	server := os.Getenv("TXPARSER_SERVER")
+	if server == "" {
+		server = "http://localhost:8080"
+	}
+	flags.StringVar(&server, "server", server, "base URL of the server (default from $TXPARSER_SERVER)")
+	apiKey := flags.String("api-key", os.Getenv("TXPARSER_API_KEY"), "API key sent to the server (default from $TXPARSER_API_KEY)")
+	dataDir := flags.String("data-dir", "", "work offline against the storage snapshot in this directory instead of a server")
+	format := flags.String("o", formatTable, "output format: table, json or csv")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	out, err := newPrinter(*format, stdout)
+	if err != nil {
+		fmt.Fprintf(stderr, "txparser: %v\n", err)
+		return 2
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	var b backend
+	if *dataDir != "" {
+		b = newLocalBackend(*dataDir)
+	} else {
+		b = client.New(server, client.WithAPIKey(*apiKey))
+	}
+
+	err = runCommand(ctx, b, out, flags.Arg(0), flags.Args()[1:])
+	var usageErr usageError
+	switch {
+	case errors.As(err, &usageErr):
+		fmt.Fprintf(stderr, "txparser: %v\n", err)
+		flags.Usage()
+		return 2
+	case err != nil:
+		fmt.Fprintf(stderr, "txparser: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// usageError reports a command line that does not match any command
+type usageError string
+
+func (e usageError) Error() string { return string(e) }
+
+// runCommand runs a single subcommand against the backend
+func runCommand(ctx context.Context, b backend, out printer, command string, args []string) error {
+	switch command {
+	case "current-block":
+		if len(args) != 0 {
+			return usageError("current-block takes no arguments")
+		}
+		number, err := b.GetCurrentBlock()
+		if err != nil {
+			return err
+		}
+		return out.block(number)
+
+	case "subscribe", "unsubscribe":
+		if len(args) != 1 {
+			return usageError(command + " takes exactly one address")
+		}
+		change := b.Subscribe
+		if command == "unsubscribe" {
+			change = b.Unsubscribe
+		}
+		changed, err := change(args[0])
+		if err != nil {
+			return err
+		}
+		return out.subscription(command, args[0], changed)
+
+	case "transactions":
+		if len(args) != 1 {
+			return usageError("transactions takes exactly one address")
+		}
+		transactions, err := b.GetTransactions(args[0])
+		if err != nil {
+			return err
+		}
+		return out.transactions(transactions)
+
+	case "watch":
+		if len(args) == 0 {
+			return usageError("watch takes at least one address")
+		}
+		err := b.WatchTransactions(ctx, args, out.transaction)
+		if errors.Is(err, context.Canceled) {
+			return nil
+		}
+		return err
+	}
+	return usageError(fmt.Sprintf("unknown command %q", command))
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ethereum-tx-parser/internal/api"
+	"ethereum-tx-parser/internal/model"
+	"ethereum-tx-parser/internal/service"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testAddress = "0x1234567890abcdef1234567890abcdef12345678"
+
+var testTransactions = []model.Transaction{
+	{Hash: "0xaaa", From: testAddress, To: "0xbbb", Value: "0x1", BlockNumber: "0x10"},
+	{Hash: "0xccc", From: "0xddd", To: testAddress, Value: "0x2", BlockNumber: "0x11"},
+}
+
+// runArgs runs a command line and returns its exit code, stdout and stderr
+func runArgs(args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+// startServer serves the API over a fresh store holding the test transactions
+func startServer(t *testing.T) string {
+	t.Helper()
+	service.InitializeModelLayer()
+	service.SaveLatestBlock("0x11")
+	service.Subscribe(testAddress)
+	service.FilterTransactionsByAddress(testTransactions)
+
+	server := httptest.NewServer(api.NewRouter())
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func TestHTTPCommands(t *testing.T) {
+	server := startServer(t)
+
+	code, out, _ := runArgs("-server", server, "current-block")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "17\n", out)
+
+	code, out, _ = runArgs("-server", server, "subscribe", testAddress)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, testAddress+" is already subscribed\n", out)
+
+	code, out, _ = runArgs("-server", server, "-o", "json", "unsubscribe", testAddress)
+	assert.Equal(t, 0, code)
+	assert.JSONEq(t, `{"address":"`+testAddress+`","unsubscribed":true}`, out)
+
+	code, out, _ = runArgs("-server", server, "-o", "csv", "subscribe", testAddress)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "address,subscribed\n"+testAddress+",true\n", out)
+
+	code, _, errOut := runArgs("-server", server, "subscribe", "invalid")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, errOut, "400 Bad Request")
+}
+
+func TestTransactionsOutput(t *testing.T) {
+	server := startServer(t)
+
+	code, out, _ := runArgs("-server", server, "transactions", testAddress)
+	assert.Equal(t, 0, code)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	assert.Len(t, lines, 3)
+	assert.Equal(t, []string{"HASH", "BLOCK", "FROM", "TO", "VALUE", "STATUS"}, strings.Fields(lines[0]))
+	assert.Equal(t, []string{"0xaaa", "16", testAddress, "0xbbb", "0x1", "mined"}, strings.Fields(lines[1]))
+
+	code, out, _ = runArgs("-server", server, "-o", "csv", "transactions", testAddress)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "hash,block,from,to,value,status\n"+
+		"0xaaa,16,"+testAddress+",0xbbb,0x1,mined\n"+
+		"0xccc,17,0xddd,"+testAddress+",0x2,mined\n", out)
+
+	code, out, _ = runArgs("-server", server, "-o", "json", "transactions", testAddress)
+	assert.Equal(t, 0, code)
+	var transactions []model.Transaction
+	assert.NoError(t, json.Unmarshal([]byte(out), &transactions))
+	assert.Len(t, transactions, 2)
+}
+
+func TestOfflineCommands(t *testing.T) {
+	dir := t.TempDir()
+	storage := model.NewBlockStorage()
+	storage.SaveBlock("0x11")
+	storage.Subscribe(testAddress)
+	for _, tx := range testTransactions {
+		tx.Status = model.TxStatusMined
+		storage.SaveTransaction(testAddress, tx)
+	}
+	assert.NoError(t, storage.SaveFile(model.StatePath(dir)))
+
+	code, out, _ := runArgs("-data-dir", dir, "current-block")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "17\n", out)
+
+	code, out, _ = runArgs("-data-dir", dir, "-o", "csv", "transactions", testAddress)
+	assert.Equal(t, 0, code)
+	assert.Contains(t, out, "0xccc,17,0xddd,"+testAddress+",0x2,mined\n")
+
+	code, out, _ = runArgs("-data-dir", dir, "unsubscribe", testAddress)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "unsubscribed "+testAddress+"\n", out)
+
+	loaded, err := model.LoadBlockStorage(model.StatePath(dir))
+	assert.NoError(t, err)
+	assert.Empty(t, loaded.GetAllSubscriptions())
+	assert.Len(t, loaded.GetTransactions(testAddress), 2)
+}
+
+func TestOfflineRefusesLockedDataDir(t *testing.T) {
+	dir := t.TempDir()
+	storage := model.NewBlockStorage()
+	storage.Subscribe(testAddress)
+	assert.NoError(t, storage.SaveFile(model.StatePath(dir)))
+
+	unlock, err := model.LockDataDir(dir)
+	assert.NoError(t, err)
+	defer unlock()
+
+	code, _, errOut := runArgs("-data-dir", dir, "unsubscribe", testAddress)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, errOut, "in use by a running server")
+
+	code, _, _ = runArgs("-data-dir", dir, "current-block")
+	assert.Equal(t, 0, code, "Reading a locked snapshot should still work")
+
+	loaded, err := model.LoadBlockStorage(model.StatePath(dir))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{testAddress: true}, loaded.GetAllSubscriptions())
+}
+
+func TestOfflineWatch(t *testing.T) {
+	dir := t.TempDir()
+	storage := model.NewBlockStorage()
+	storage.SaveTransaction(testAddress, testTransactions[0])
+	assert.NoError(t, storage.SaveFile(model.StatePath(dir)))
+
+	backend := newLocalBackend(dir)
+	seen, err := backend.poll([]string{testAddress}, nil, nil)
+	assert.NoError(t, err)
+
+	storage.SaveTransaction(testAddress, testTransactions[1])
+	assert.NoError(t, storage.SaveFile(model.StatePath(dir)))
+
+	var reported []string
+	_, err = backend.poll([]string{testAddress}, seen, func(tx model.Transaction) error {
+		reported = append(reported, tx.Hash)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"0xccc"}, reported)
+}
+
+func TestUsageErrors(t *testing.T) {
+	code, _, errOut := runArgs("frobnicate")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, errOut, `unknown command "frobnicate"`)
+
+	code, _, _ = runArgs("-o", "yaml", "current-block")
+	assert.Equal(t, 2, code)
+
+	code, _, _ = runArgs("transactions")
+	assert.Equal(t, 2, code)
+}
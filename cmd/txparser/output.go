@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"ethereum-tx-parser/internal/model"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// Output formats
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatCSV   = "csv"
+)
+
+// transactionColumns are the fields of a transaction shown in table and CSV output
+var transactionColumns = []string{"HASH", "BLOCK", "FROM", "TO", "VALUE", "STATUS"}
+
+// printer writes command results in one output format
+type printer struct {
+	format string
+	w      io.Writer
+
+	headerDone bool // Whether watch output has written its header
+}
+
+func newPrinter(format string, w io.Writer) (printer, error) {
+	switch format {
+	case formatTable, formatJSON, formatCSV:
+		return printer{format: format, w: w}, nil
+	}
+	return printer{}, fmt.Errorf("unknown output format %q", format)
+}
+
+// block prints the current block number
+func (p printer) block(number int) error {
+	hex := fmt.Sprintf("0x%x", number)
+	switch p.format {
+	case formatJSON:
+		return json.NewEncoder(p.w).Encode(map[string]interface{}{"number": number, "hex": hex})
+	case formatCSV:
+		return p.csv([][]string{{"number", "hex"}, {strconv.Itoa(number), hex}})
+	}
+	_, err := fmt.Fprintln(p.w, number)
+	return err
+}
+
+// subscription prints the outcome of a subscribe or unsubscribe command
+func (p printer) subscription(command string, address string, changed bool) error {
+	switch p.format {
+	case formatJSON:
+		key := "subscribed"
+		if command == "unsubscribe" {
+			key = "unsubscribed"
+		}
+		return json.NewEncoder(p.w).Encode(map[string]interface{}{"address": address, key: changed})
+	case formatCSV:
+		return p.csv([][]string{{"address", command + "d"}, {address, strconv.FormatBool(changed)}})
+	}
+
+	message := command + "d " + address
+	if !changed && command == "subscribe" {
+		message = address + " is already subscribed"
+	} else if !changed {
+		message = address + " is not subscribed"
+	}
+	_, err := fmt.Fprintln(p.w, message)
+	return err
+}
+
+// transactions prints a list of transactions
+func (p printer) transactions(transactions []model.Transaction) error {
+	switch p.format {
+	case formatJSON:
+		if transactions == nil {
+			transactions = []model.Transaction{}
+		}
+		encoder := json.NewEncoder(p.w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(transactions)
+	case formatCSV:
+		records := [][]string{csvHeader()}
+		for _, tx := range transactions {
+			records = append(records, transactionRow(tx))
+		}
+		return p.csv(records)
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	writeTableRow(tw, transactionColumns)
+	for _, tx := range transactions {
+		writeTableRow(tw, transactionRow(tx))
+	}
+	return tw.Flush()
+}
+
+// transaction prints one transaction of a watch. JSON output is one object per line.
+func (p *printer) transaction(tx model.Transaction) error {
+	switch p.format {
+	case formatJSON:
+		return json.NewEncoder(p.w).Encode(tx)
+	case formatCSV:
+		records := [][]string{transactionRow(tx)}
+		if !p.headerDone {
+			records = append([][]string{csvHeader()}, records...)
+		}
+		p.headerDone = true
+		return p.csv(records)
+	}
+
+	// Rows are written as they arrive, so columns are padded to a fixed width instead of
+	// being aligned across rows
+	if !p.headerDone {
+		if err := writeWatchRow(p.w, transactionColumns); err != nil {
+			return err
+		}
+		p.headerDone = true
+	}
+	return writeWatchRow(p.w, transactionRow(tx))
+}
+
+func (p printer) csv(records [][]string) error {
+	w := csv.NewWriter(p.w)
+	if err := w.WriteAll(records); err != nil {
+		return err
+	}
+	return w.Error()
+}
+
+func csvHeader() []string {
+	header := make([]string, len(transactionColumns))
+	for i, column := range transactionColumns {
+		header[i] = strings.ToLower(column)
+	}
+	return header
+}
+
+// transactionRow returns the values of transactionColumns for a transaction
+func transactionRow(tx model.Transaction) []string {
+	block := tx.BlockNumber
+	if number, err := strconv.ParseInt(strings.TrimPrefix(block, "0x"), 16, 64); err == nil {
+		block = strconv.FormatInt(number, 10)
+	}
+	return []string{tx.Hash, block, tx.From, tx.To, tx.Value, tx.Status}
+}
+
+func writeWatchRow(w io.Writer, row []string) error {
+	_, err := fmt.Fprintf(w, "%-66s  %-10s  %-42s  %-42s  %-22s  %s\n", row[0], row[1], row[2], row[3], row[4], row[5])
+	return err
+}
+
+func writeTableRow(w io.Writer, values []string) {
+	fmt.Fprintln(w, strings.Join(values, "\t"))
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"ethereum-tx-parser/internal/model"
+	"ethereum-tx-parser/internal/service"
+	"net/http"
+	"strings"
+)
+
+// APIKeyHeader carries an API key for clients that cannot set the Authorization header
+const APIKeyHeader = "X-API-Key"
+
+// authenticate resolves the API key of each request to its tenant and applies the rate limit of
+// the key, or of the client address for requests without a valid key. Requests with an invalid
+// or revoked key are always rejected; requests without one are rejected only when RequireAPIKey
+// is set. The /v1/admin endpoints take the admin token instead of an API key, and the health
+// probes take nothing.
+func authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if probePaths[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+		key := requestAPIKey(r)
+		ipClient := "ip:" + clientIP(r)
+
+		if strings.HasPrefix(r.URL.Path, "/v1/admin/") {
+			switch {
+			case service.AdminToken == "":
+				writeProblem(w, r, http.StatusNotFound, "the admin API is disabled")
+			case !service.IsAdminToken(key):
+				if allowRequest(w, r, ipClient) {
+					w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+					writeProblem(w, r, http.StatusUnauthorized, "a valid admin token is required")
+				}
+			default:
+				next.ServeHTTP(w, r)
+			}
+			return
+		}
+
+		if key == "" {
+			if !allowRequest(w, r, ipClient) {
+				return
+			}
+			if service.RequireAPIKey {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="txparser"`)
+				writeProblem(w, r, http.StatusUnauthorized, "an API key is required")
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(withClient(r.Context(), ipClient)))
+			return
+		}
+
+		record, ok := service.AuthenticateAPIKey(key)
+		if !ok {
+			if allowRequest(w, r, ipClient) {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="txparser", error="invalid_token"`)
+				writeProblem(w, r, http.StatusUnauthorized, "invalid or revoked API key")
+			}
+			return
+		}
+		keyClient := "key:" + record.ID
+		if !allowRequest(w, r, keyClient) {
+			return
+		}
+		ctx := withClient(service.WithTenant(r.Context(), record.Tenant), keyClient)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// requestAPIKey returns the bearer token or X-API-Key header of a request
+func requestAPIKey(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); len(auth) > 7 && strings.EqualFold(auth[:7], "Bearer ") {
+		return strings.TrimSpace(auth[7:])
+	}
+	return r.Header.Get(APIKeyHeader)
+}
+
+// issuedKey is the response to issuing a key, the only time the key itself is returned
+type issuedKey struct {
+	model.APIKey
+	Key string `json:"key"`
+}
+
+// CreateAPIKeyV1 issues a key for the tenant in a JSON body of tenant and an optional name
+func CreateAPIKeyV1(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Tenant string `json:"tenant"`
+		Name   string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, "request body must be a JSON object with a tenant")
+		return
+	}
+
+	record, key, err := service.IssueAPIKey(req.Tenant, req.Name)
+	if errors.Is(err, service.ErrInvalidTenant) {
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		writeProblem(w, r, http.StatusInternalServerError, "failed to issue API key")
+		return
+	}
+
+	writeData(w, http.StatusCreated, issuedKey{APIKey: record, Key: key}, nil)
+}
+
+// ListAPIKeysV1 returns the issued keys without the keys themselves, optionally for one tenant
+func ListAPIKeysV1(w http.ResponseWriter, r *http.Request) {
+	writeData(w, http.StatusOK, service.GetAPIKeys(r.URL.Query().Get("tenant")), nil)
+}
+
+// RevokeAPIKeyV1 revokes a key
+func RevokeAPIKeyV1(w http.ResponseWriter, r *http.Request) {
+	if err := service.RevokeAPIKey(r.PathValue("id")); err != nil {
+		writeProblem(w, r, http.StatusNotFound, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,152 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ethereum-tx-parser/internal/model"
+	"ethereum-tx-parser/internal/service"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// doAuthRequest sends a request through the router with an API key or admin token
+func doAuthRequest(t *testing.T, method string, target string, body string, key string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+	rec := httptest.NewRecorder()
+	NewRouter().ServeHTTP(rec, req)
+	return rec
+}
+
+// issueTestKey issues a key for a tenant through the admin API
+func issueTestKey(t *testing.T, tenant string) string {
+	t.Helper()
+	rec := doAuthRequest(t, http.MethodPost, "/v1/admin/keys", `{"tenant":"`+tenant+`"}`, service.AdminToken)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	var created struct{ Data issuedKey }
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	return created.Data.Key
+}
+
+// withAdminToken enables the admin API for a test
+func withAdminToken(t *testing.T) {
+	service.AdminToken = "test-admin-token"
+	t.Cleanup(func() { service.AdminToken = "" })
+}
+
+func TestAdminKeysV1(t *testing.T) {
+	service.InitializeModelLayer()
+	rec := doAuthRequest(t, http.MethodGet, "/v1/admin/keys", "", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	withAdminToken(t)
+	rec = doAuthRequest(t, http.MethodGet, "/v1/admin/keys", "", "wrong")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.NotEmpty(t, rec.Header().Get("WWW-Authenticate"))
+
+	rec = doAuthRequest(t, http.MethodPost, "/v1/admin/keys", `{"tenant":"Bad Tenant"}`, service.AdminToken)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	key := issueTestKey(t, "admin-test")
+	assert.True(t, strings.HasPrefix(key, "txp_"))
+
+	rec = doAuthRequest(t, http.MethodGet, "/v1/admin/keys?tenant=admin-test", "", service.AdminToken)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), key)
+	var listed struct{ Data []model.APIKey }
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &listed))
+	assert.Len(t, listed.Data, 1)
+	assert.Empty(t, listed.Data[0].Hash)
+
+	rec = doAuthRequest(t, http.MethodGet, "/v1/blocks/current", "", key)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = doAuthRequest(t, http.MethodDelete, "/v1/admin/keys/"+listed.Data[0].ID, "", service.AdminToken)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	rec = doAuthRequest(t, http.MethodDelete, "/v1/admin/keys/missing", "", service.AdminToken)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = doAuthRequest(t, http.MethodGet, "/v1/blocks/current", "", key)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestRequireAPIKey(t *testing.T) {
+	service.InitializeModelLayer()
+	service.RequireAPIKey = true
+	t.Cleanup(func() { service.RequireAPIKey = false })
+
+	rec := doAuthRequest(t, http.MethodGet, "/v1/blocks/current", "", "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+
+	rec = doAuthRequest(t, http.MethodGet, "/v1/blocks/current", "", "txp_unknown")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestTenantIsolationV1(t *testing.T) {
+	service.InitializeModelLayer()
+	withAdminToken(t)
+	acme := issueTestKey(t, "acme")
+	other := issueTestKey(t, "other")
+
+	rec := doAuthRequest(t, http.MethodPost, "/v1/subscriptions", `{"address":"`+testAddress+`"}`, acme)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	service.FilterTransactionsByAddress([]model.Transaction{{Hash: "0xabc", From: testAddress, To: "0x0000000000000000000000000000000000000002", BlockNumber: "0x1"}})
+
+	rec = doAuthRequest(t, http.MethodGet, "/v1/addresses/"+testAddress+"/transactions", "", acme)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "0xabc")
+
+	for _, target := range []string{
+		"/v1/addresses/" + testAddress + "/transactions",
+		"/v1/addresses/" + testAddress + "/contracts",
+		"/v1/stream/transactions?address=" + testAddress,
+		"/api/transactions?address=" + testAddress,
+	} {
+		rec = doAuthRequest(t, http.MethodGet, target, "", other)
+		assert.Equal(t, http.StatusNotFound, rec.Code, target)
+	}
+
+	rec = doAuthRequest(t, http.MethodDelete, "/v1/subscriptions/"+testAddress, "", other)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	// JSON-RPC shares the tenant's view
+	rec = doAuthRequest(t, http.MethodPost, "/rpc", `{"jsonrpc":"2.0","id":1,"method":"parser_getTransactions","params":["`+testAddress+`"]}`, other)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"error"`)
+
+	// Requests without a key act for the default tenant, which doesn't subscribe to the address
+	rec = doAuthRequest(t, http.MethodGet, "/v1/addresses/"+testAddress+"/transactions", "", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	service.Subscribe(testAddress)
+	rec = doAuthRequest(t, http.MethodGet, "/v1/addresses/"+testAddress+"/transactions", "", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestTenantWebhooksV1(t *testing.T) {
+	withAdminToken(t)
+	acme := issueTestKey(t, "acme")
+	other := issueTestKey(t, "other")
+
+	rec := doAuthRequest(t, http.MethodPost, "/v1/webhooks", `{"url":"http://example.com/hook"}`, acme)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	var created struct{ Data model.Webhook }
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	assert.Equal(t, "acme", created.Data.Tenant)
+	t.Cleanup(func() { service.DeleteWebhook(created.Data.ID) })
+
+	rec = doAuthRequest(t, http.MethodGet, "/v1/webhooks", "", other)
+	assert.NotContains(t, rec.Body.String(), created.Data.ID)
+	rec = doAuthRequest(t, http.MethodDelete, "/v1/webhooks/"+created.Data.ID, "", other)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = doAuthRequest(t, http.MethodGet, "/v1/webhooks", "", acme)
+	assert.Contains(t, rec.Body.String(), created.Data.ID)
+}
@@ -0,0 +1,591 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"ethereum-tx-parser/internal/graphql"
+	"ethereum-tx-parser/internal/model"
+	"ethereum-tx-parser/internal/service"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// blockNode is the value behind the GraphQL Block type. Only the number is always known;
+// the other fields are filled from block events or the processed block history.
+type blockNode struct {
+	Number           uint64
+	Hash             string
+	ParentHash       string
+	Timestamp        string
+	TransactionCount *int
+}
+
+// graphQLSchema is the schema served at /v1/graphql
+var graphQLSchema = newGraphQLSchema()
+
+// Limits on GraphQL operations. Costs estimate how many values list fields resolve to: a page of
+// a connection, or the stored transactions of a block, which are found by scanning the store.
+const (
+	graphQLMaxDepth      = 10
+	graphQLMaxComplexity = 5000
+	listCost             = 10
+)
+
+// newGraphQLSchema builds the GraphQL schema over the store and the event bus
+func newGraphQLSchema() *graphql.Schema {
+	blockType := &graphql.Object{Name: "Block"}
+	transactionType := &graphql.Object{Name: "Transaction"}
+	tokenTransferType := &graphql.Object{Name: "TokenTransfer"}
+	addressType := &graphql.Object{Name: "Address"}
+
+	pageInfoType := &graphql.Object{Name: "PageInfo", Fields: map[string]*graphql.FieldDef{
+		"hasNextPage": {},
+		"endCursor":   {},
+	}}
+	connection := func(name string, node *graphql.Object) *graphql.Object {
+		edge := &graphql.Object{Name: name + "Edge", Fields: map[string]*graphql.FieldDef{
+			"cursor": {},
+			"node":   {Type: node},
+		}}
+		return &graphql.Object{Name: name + "Connection", Fields: map[string]*graphql.FieldDef{
+			"edges":    {Type: edge, Cost: defaultPageLimit},
+			"nodes":    {Type: node, Cost: defaultPageLimit},
+			"pageInfo": {Type: pageInfoType},
+		}}
+	}
+
+	decodedCallType := &graphql.Object{Name: "DecodedCall", Fields: map[string]*graphql.FieldDef{
+		"selector": {Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(model.DecodedCall).Selector, nil }},
+		"method":   {Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(model.DecodedCall).Method, nil }},
+		"params": {
+			Cost: listCost,
+			Type: &graphql.Object{Name: "DecodedParam", Fields: map[string]*graphql.FieldDef{
+				"name":  {Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(model.DecodedParam).Name, nil }},
+				"type":  {Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(model.DecodedParam).Type, nil }},
+				"value": {Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(model.DecodedParam).Value, nil }},
+			}},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(model.DecodedCall).Params, nil },
+		},
+	}}
+
+	alertType := &graphql.Object{Name: "Alert", Fields: map[string]*graphql.FieldDef{
+		"type":    {Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(model.Alert).Type, nil }},
+		"address": {Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(model.Alert).Address, nil }},
+		"nonce":   {Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(model.Alert).Nonce, nil }},
+		"txHash":  {Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(model.Alert).TxHash, nil }},
+		"detail":  {Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(model.Alert).Detail, nil }},
+		"detectedAt": {Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(model.Alert).DetectedAt.Unix(), nil
+		}},
+	}}
+
+	blockType.Fields = map[string]*graphql.FieldDef{
+		"number": {Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(blockNode).Number, nil }},
+		"hex": {Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return fmt.Sprintf("0x%x", p.Source.(blockNode).Number), nil
+		}},
+		"hash": {Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			block := p.Source.(blockNode)
+			if block.Hash != "" {
+				return block.Hash, nil
+			}
+			if hash, ok := service.ProcessedBlockHash(block.Number); ok {
+				return hash, nil
+			}
+			return nil, nil
+		}},
+		"parentHash": {Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return emptyToNil(p.Source.(blockNode).ParentHash), nil
+		}},
+		"timestamp": {Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return hexToInt(p.Source.(blockNode).Timestamp), nil
+		}},
+		"transactionCount": {Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(blockNode).TransactionCount, nil }},
+		"transactions": {
+			Type: transactionType,
+			Cost: defaultPageLimit,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return blockTransactions(p.Context, p.Source.(blockNode).Number), nil
+			},
+		},
+	}
+
+	txString := func(get func(model.Transaction) string) *graphql.FieldDef {
+		return &graphql.FieldDef{Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return emptyToNil(get(p.Source.(model.Transaction))), nil
+		}}
+	}
+	transactionType.Fields = map[string]*graphql.FieldDef{
+		"hash":  txString(func(tx model.Transaction) string { return tx.Hash }),
+		"from":  txString(func(tx model.Transaction) string { return tx.From }),
+		"to":    txString(func(tx model.Transaction) string { return tx.To }),
+		"value": txString(func(tx model.Transaction) string { return tx.Value }),
+		"nonce": {Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return hexToInt(p.Source.(model.Transaction).Nonce), nil
+		}},
+		"input":    txString(func(tx model.Transaction) string { return tx.Input }),
+		"type":     txString(func(tx model.Transaction) string { return tx.Type }),
+		"gas":      txString(func(tx model.Transaction) string { return tx.Gas }),
+		"gasPrice": txString(func(tx model.Transaction) string { return tx.GasPrice }),
+		"blockNumber": {Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return hexToInt(p.Source.(model.Transaction).BlockNumber), nil
+		}},
+		"transactionIndex": {Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return hexToInt(p.Source.(model.Transaction).TransactionIndex), nil
+		}},
+		"timestamp": {Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return zeroToNil(p.Source.(model.Transaction).Timestamp), nil
+		}},
+		"seenAt": {Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return zeroToNil(p.Source.(model.Transaction).SeenAt), nil
+		}},
+		"status":            txString(func(tx model.Transaction) string { return tx.Status }),
+		"contractAddress":   txString(func(tx model.Transaction) string { return tx.ContractAddress }),
+		"replaces":          txString(func(tx model.Transaction) string { return tx.Replaces }),
+		"replacedBy":        txString(func(tx model.Transaction) string { return tx.ReplacedBy }),
+		"replacementType":   txString(func(tx model.Transaction) string { return tx.ReplacementType }),
+		"verificationError": txString(func(tx model.Transaction) string { return tx.VerificationError }),
+		"decodedCall": {
+			Type: decodedCallType,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				if call, ok := service.DecodeCall(p.Source.(model.Transaction)); ok {
+					return call, nil
+				}
+				return nil, nil
+			},
+		},
+		"tokenTransfer": {
+			Type: tokenTransferType,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				if transfer, ok := service.DecodeTokenTransfer(p.Source.(model.Transaction)); ok {
+					return transfer, nil
+				}
+				return nil, nil
+			},
+		},
+		"block": {
+			Type: blockType,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				number, ok := hexToInt(p.Source.(model.Transaction).BlockNumber).(uint64)
+				if !ok {
+					return nil, nil
+				}
+				return blockNode{Number: number}, nil
+			},
+		},
+	}
+
+	tokenTransferType.Fields = map[string]*graphql.FieldDef{
+		"token":  {Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(model.TokenTransfer).Token, nil }},
+		"from":   {Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(model.TokenTransfer).From, nil }},
+		"to":     {Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(model.TokenTransfer).To, nil }},
+		"amount": {Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(model.TokenTransfer).Amount, nil }},
+		"txHash": {Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(model.TokenTransfer).TxHash, nil }},
+		"blockNumber": {Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return hexToInt(p.Source.(model.TokenTransfer).BlockNumber), nil
+		}},
+		"transaction": {
+			Type: transactionType,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return findStoredTransaction(p.Context, p.Source.(model.TokenTransfer).TxHash), nil
+			},
+		},
+	}
+
+	addressType.Fields = map[string]*graphql.FieldDef{
+		"address": {Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(string), nil }},
+		"subscribed": {Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return service.ParserFor(p.Context).Subscriptions()[p.Source.(string)], nil
+		}},
+		"transactions": {
+			Type: connection("Transaction", transactionType),
+			Args: map[string]string{
+				"first": "Int", "after": "String", "direction": "String", "status": "String",
+				"fromBlock": "Int", "toBlock": "Int", "counterparty": "String", "sort": "String", "order": "String",
+			},
+			Resolve: resolveAddressTransactions,
+		},
+		"tokenTransfers": {
+			Type:    connection("TokenTransfer", tokenTransferType),
+			Args:    map[string]string{"first": "Int", "after": "String"},
+			Resolve: resolveAddressTokenTransfers,
+		},
+		"contracts": {Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			contracts := model.SharedStore().GetContracts(p.Source.(string))
+			if contracts == nil {
+				contracts = []string{}
+			}
+			return contracts, nil
+		}},
+		"alerts": {Type: alertType, Cost: listCost, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			alerts := service.GetAlerts(p.Source.(string))
+			if alerts == nil {
+				alerts = []model.Alert{}
+			}
+			return alerts, nil
+		}},
+	}
+
+	query := &graphql.Object{Name: "Query", Fields: map[string]*graphql.FieldDef{
+		"currentBlock": {Type: blockType, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			number, err := service.LocalParser{}.GetCurrentBlock()
+			if err != nil {
+				return nil, err
+			}
+			return blockNode{Number: uint64(number)}, nil
+		}},
+		"block": {Type: blockType, Args: map[string]string{"number": "Int!"}, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			number := p.Args["number"].(int64)
+			if number < 0 {
+				return nil, errors.New("block number cannot be negative")
+			}
+			return blockNode{Number: uint64(number)}, nil
+		}},
+		"address": {Type: addressType, Args: map[string]string{"address": "String!"}, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			address := p.Args["address"].(string)
+			if err := service.ValidateAddress(address); err != nil {
+				return nil, err
+			}
+			if !service.CanReadAddress(p.Context, address) {
+				return nil, fmt.Errorf("address %s is not subscribed", address)
+			}
+			return strings.ToLower(address), nil
+		}},
+		"subscriptions": {Type: addressType, Cost: listCost, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return setKeys(service.ParserFor(p.Context).Subscriptions()), nil
+		}},
+		"transaction": {Type: transactionType, Args: map[string]string{"hash": "String!"}, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return findStoredTransaction(p.Context, p.Args["hash"].(string)), nil
+		}},
+	}}
+
+	mutation := &graphql.Object{Name: "Mutation", Fields: map[string]*graphql.FieldDef{
+		"subscribe": {Args: map[string]string{"address": "String!"}, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return service.ParserFor(p.Context).Subscribe(p.Args["address"].(string))
+		}},
+	}}
+
+	subscription := &graphql.Object{Name: "Subscription", Fields: map[string]*graphql.FieldDef{
+		"transactions": {
+			Type:      transactionType,
+			Args:      map[string]string{"addresses": "[String!]"},
+			Subscribe: subscribeEvents(model.EventTransaction),
+		},
+		"tokenTransfers": {
+			Type:      tokenTransferType,
+			Args:      map[string]string{"addresses": "[String!]"},
+			Subscribe: subscribeEvents(model.EventTokenTransfer),
+		},
+		"blocks": {
+			Type:      blockType,
+			Subscribe: subscribeEvents(model.EventBlock),
+		},
+	}}
+
+	return &graphql.Schema{
+		Query:         query,
+		Mutation:      mutation,
+		Subscription:  subscription,
+		MaxDepth:      graphQLMaxDepth,
+		MaxComplexity: graphQLMaxComplexity,
+	}
+}
+
+// resolveAddressTransactions pages the stored transactions of an address as a connection
+func resolveAddressTransactions(p graphql.ResolveParams) (interface{}, error) {
+	query := model.TransactionQuery{Limit: defaultPageLimit}
+	if first, ok := p.Args["first"].(int64); ok {
+		if first < 1 || first > maxPageLimit {
+			return nil, fmt.Errorf("first must be between 1 and %d", maxPageLimit)
+		}
+		query.Limit = int(first)
+	}
+	query.Cursor, _ = p.Args["after"].(string)
+	query.Direction, _ = p.Args["direction"].(string)
+	query.Status, _ = p.Args["status"].(string)
+	query.Counterparty, _ = p.Args["counterparty"].(string)
+	query.SortBy, _ = p.Args["sort"].(string)
+	query.Order, _ = p.Args["order"].(string)
+	for name, bound := range map[string]**uint64{"fromBlock": &query.FromBlock, "toBlock": &query.ToBlock} {
+		if n, ok := p.Args[name].(int64); ok {
+			block := uint64(n)
+			*bound = &block
+		}
+	}
+
+	page, err := model.SharedStore().QueryTransactions(p.Source.(string), query)
+	if err != nil {
+		return nil, err
+	}
+
+	edges := make([]interface{}, len(page.Transactions))
+	for i, tx := range page.Transactions {
+		edges[i] = map[string]interface{}{"cursor": query.CursorOf(tx), "node": tx}
+	}
+	return connectionValue(edges, page.Transactions, page.NextCursor != "", lastCursor(edges)), nil
+}
+
+// resolveAddressTokenTransfers pages the stored token transfers of an address as a connection.
+// Cursors are opaque transaction hashes.
+func resolveAddressTokenTransfers(p graphql.ResolveParams) (interface{}, error) {
+	transfers := model.SharedStore().GetTokenTransfers(p.Source.(string))
+
+	if after, _ := p.Args["after"].(string); after != "" {
+		hash, err := base64.RawURLEncoding.DecodeString(after)
+		if err != nil {
+			return nil, model.ErrInvalidCursor
+		}
+		start := -1
+		for i, transfer := range transfers {
+			if transfer.TxHash == string(hash) {
+				start = i + 1
+			}
+		}
+		if start < 0 {
+			return nil, model.ErrInvalidCursor
+		}
+		transfers = transfers[start:]
+	}
+
+	limit := defaultPageLimit
+	if first, ok := p.Args["first"].(int64); ok {
+		if first < 1 || first > maxPageLimit {
+			return nil, fmt.Errorf("first must be between 1 and %d", maxPageLimit)
+		}
+		limit = int(first)
+	}
+	hasNext := len(transfers) > limit
+	if hasNext {
+		transfers = transfers[:limit]
+	}
+
+	edges := make([]interface{}, len(transfers))
+	for i, transfer := range transfers {
+		edges[i] = map[string]interface{}{"cursor": base64.RawURLEncoding.EncodeToString([]byte(transfer.TxHash)), "node": transfer}
+	}
+	if transfers == nil {
+		transfers = []model.TokenTransfer{}
+	}
+	return connectionValue(edges, transfers, hasNext, lastCursor(edges)), nil
+}
+
+// connectionValue is the value behind a connection type; its fields use the default resolver
+func connectionValue(edges []interface{}, nodes interface{}, hasNext bool, endCursor interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"edges":    edges,
+		"nodes":    nodes,
+		"pageInfo": map[string]interface{}{"hasNextPage": hasNext, "endCursor": endCursor},
+	}
+}
+
+func lastCursor(edges []interface{}) interface{} {
+	if len(edges) == 0 {
+		return nil
+	}
+	return edges[len(edges)-1].(map[string]interface{})["cursor"]
+}
+
+// subscribeEvents returns a subscription resolver streaming one event type, optionally only for
+// the addresses argument
+func subscribeEvents(eventType string) func(p graphql.ResolveParams) (<-chan interface{}, error) {
+	return func(p graphql.ResolveParams) (<-chan interface{}, error) {
+		addresses := make(map[string]bool)
+		list, _ := p.Args["addresses"].([]interface{})
+		for _, address := range list {
+			if err := service.ValidateAddress(address.(string)); err != nil {
+				return nil, err
+			}
+			if !service.CanReadAddress(p.Context, address.(string)) {
+				return nil, fmt.Errorf("address %s is not subscribed", address)
+			}
+			addresses[strings.ToLower(address.(string))] = true
+		}
+
+		events, unsubscribe := service.SubscribeEvents(256)
+		values := make(chan interface{})
+		go func() {
+			defer close(values)
+			defer unsubscribe()
+			for {
+				select {
+				case <-p.Context.Done():
+					return
+				case event, ok := <-events:
+					if !ok {
+						return
+					}
+					if event.Type != eventType || (len(addresses) > 0 && !addresses[event.Address]) {
+						continue
+					}
+					if event.Address != "" && !service.CanReadAddress(p.Context, event.Address) {
+						continue
+					}
+					select {
+					case values <- eventValue(event):
+					case <-p.Context.Done():
+						return
+					}
+				}
+			}
+		}()
+		return values, nil
+	}
+}
+
+// eventValue converts an event payload into the value of its GraphQL type
+func eventValue(event model.Event) interface{} {
+	summary, ok := event.Data.(model.BlockSummary)
+	if !ok {
+		return event.Data
+	}
+	number, _ := hexToInt(summary.Number).(uint64)
+	count := summary.TransactionCount
+	return blockNode{
+		Number:           number,
+		Hash:             summary.Hash,
+		ParentHash:       summary.ParentHash,
+		Timestamp:        summary.Timestamp,
+		TransactionCount: &count,
+	}
+}
+
+// blockTransactions returns the stored transactions in a block of every subscribed address the
+// request may read
+func blockTransactions(ctx context.Context, number uint64) []model.Transaction {
+	seen := make(map[string]bool)
+	transactions := []model.Transaction{}
+	for _, address := range setKeys(service.ParserFor(ctx).Subscriptions()) {
+		for _, tx := range model.SharedStore().GetTransactions(address) {
+			if n, ok := hexToInt(tx.BlockNumber).(uint64); ok && n == number && !seen[tx.Hash] {
+				seen[tx.Hash] = true
+				transactions = append(transactions, tx)
+			}
+		}
+	}
+	return transactions
+}
+
+// findStoredTransaction looks a transaction up by hash among the subscribed addresses the request
+// may read
+func findStoredTransaction(ctx context.Context, hash string) interface{} {
+	for _, address := range setKeys(service.ParserFor(ctx).Subscriptions()) {
+		for _, tx := range model.SharedStore().GetTransactions(address) {
+			if strings.EqualFold(tx.Hash, hash) {
+				return tx
+			}
+		}
+	}
+	return nil
+}
+
+// hexToInt parses a hex quantity, returning nil if it is empty or invalid
+func hexToInt(value string) interface{} {
+	n, err := strconv.ParseUint(strings.TrimPrefix(value, "0x"), 16, 64)
+	if err != nil || value == "" {
+		return nil
+	}
+	return n
+}
+
+func emptyToNil(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+func zeroToNil(value int64) interface{} {
+	if value == 0 {
+		return nil
+	}
+	return value
+}
+
+// GraphQLV1 executes GraphQL queries and mutations sent as a JSON body, or queries sent as query,
+// variables and operationName parameters of a GET request; mutations must be POSTed, so that
+// links and cross-site requests cannot change anything. Requests accepting text/event-stream run
+// a subscription, streamed as one "next" event per result and a "complete" event at the end.
+func GraphQLV1(w http.ResponseWriter, r *http.Request) {
+	var req graphql.Request
+	if r.Method == http.MethodGet {
+		req.Query = r.URL.Query().Get("query")
+		req.OperationName = r.URL.Query().Get("operationName")
+		if variables := r.URL.Query().Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				writeProblem(w, r, http.StatusBadRequest, "variables must be a JSON object")
+				return
+			}
+		}
+	} else if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, "request body must be a JSON object with a query")
+		return
+	}
+	if req.Query == "" {
+		writeProblem(w, r, http.StatusBadRequest, "missing query")
+		return
+	}
+	if r.Method == http.MethodGet && graphql.OperationType(req) == "mutation" {
+		w.Header().Set("Allow", http.MethodPost)
+		writeProblem(w, r, http.StatusMethodNotAllowed, "mutations must be sent with POST")
+		return
+	}
+
+	if strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		streamGraphQL(w, r, req)
+		return
+	}
+
+	writeGraphQL(w, graphql.Execute(r.Context(), graphQLSchema, req))
+}
+
+// streamGraphQL runs a subscription and writes each result as a Server-Sent Event
+func streamGraphQL(w http.ResponseWriter, r *http.Request, req graphql.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeProblem(w, r, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	results, failure := graphql.Subscribe(ctx, graphQLSchema, req)
+	if failure != nil {
+		writeGraphQL(w, failure)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for result := range results {
+		data, err := json.Marshal(result)
+		if err != nil {
+			httpLog.ErrorContext(r.Context(), "encoding GraphQL result failed", "error", err)
+			return
+		}
+		if _, err := fmt.Fprintf(w, "event: next\ndata: %s\n\n", data); err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+	fmt.Fprint(w, "event: complete\ndata:\n\n")
+	flusher.Flush()
+}
+
+// writeGraphQL writes a GraphQL result. Requests that failed before execution get a 400;
+// field errors are reported alongside data with a 200, as usual for GraphQL.
+func writeGraphQL(w http.ResponseWriter, result *graphql.Result) {
+	setJSONResponseHeaders(w)
+	if result.Data == nil {
+		w.WriteHeader(http.StatusBadRequest)
+	}
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		httpLog.Error("encoding GraphQL response failed", "error", err)
+	}
+}
@@ -0,0 +1,165 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"ethereum-tx-parser/internal/model"
+	"ethereum-tx-parser/internal/service"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const tokenContract = "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+
+// graphQLRequest posts a GraphQL query and returns the decoded response
+func graphQLRequest(t *testing.T, query string, variables map[string]interface{}) (int, map[string]interface{}) {
+	t.Helper()
+	body, _ := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	rec := doRequest(t, http.MethodPost, "/v1/graphql", string(body))
+
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	return rec.Code, response
+}
+
+func seedGraphQLStore(t *testing.T) {
+	service.InitializeModelLayer()
+	service.Subscribe(testAddress)
+
+	transfer := "0xa9059cbb" +
+		"000000000000000000000000abcdefabcdefabcdefabcdefabcdefabcdefabcd" +
+		"00000000000000000000000000000000000000000000000000000000000f4240"
+	store := model.SharedStore()
+	store.SaveTransaction(testAddress, model.Transaction{Hash: "0x01", From: testAddress, To: tokenContract, BlockNumber: "0x10", Input: transfer, Status: model.TxStatusMined})
+	store.SaveTransaction(testAddress, model.Transaction{Hash: "0x02", From: testAddress, To: "0x0000000000000000000000000000000000000002", BlockNumber: "0x11", Value: "0x1"})
+	store.SaveTokenTransfer(testAddress, model.TokenTransfer{Token: tokenContract, From: testAddress, To: "0xabcdefabcdefabcdefabcdefabcdefabcdefabcd", Amount: "1000000", TxHash: "0x01", BlockNumber: "0x10"})
+}
+
+func TestGraphQLV1Query(t *testing.T) {
+	seedGraphQLStore(t)
+
+	query := `query ($address: String!, $after: String) {
+		address(address: $address) {
+			subscribed
+			transactions(first: 1, after: $after) {
+				edges { cursor node { hash blockNumber decodedCall { method params { name value } } tokenTransfer { amount } } }
+				pageInfo { hasNextPage endCursor }
+			}
+			tokenTransfers { nodes { token amount transaction { hash } } }
+		}
+	}`
+	code, response := graphQLRequest(t, query, map[string]interface{}{"address": "0x" + strings.ToUpper(testAddress[2:])})
+	assert.Equal(t, http.StatusOK, code)
+	assert.Nil(t, response["errors"])
+
+	address := response["data"].(map[string]interface{})["address"].(map[string]interface{})
+	assert.Equal(t, true, address["subscribed"])
+
+	transactions := address["transactions"].(map[string]interface{})
+	edges := transactions["edges"].([]interface{})
+	assert.Len(t, edges, 1)
+	node := edges[0].(map[string]interface{})["node"].(map[string]interface{})
+	assert.Equal(t, "0x01", node["hash"])
+	assert.Equal(t, float64(16), node["blockNumber"])
+	assert.Equal(t, "transfer(address,uint256)", node["decodedCall"].(map[string]interface{})["method"])
+	assert.Equal(t, "1000000", node["tokenTransfer"].(map[string]interface{})["amount"])
+
+	pageInfo := transactions["pageInfo"].(map[string]interface{})
+	assert.Equal(t, true, pageInfo["hasNextPage"])
+
+	transfers := address["tokenTransfers"].(map[string]interface{})["nodes"].([]interface{})
+	assert.Len(t, transfers, 1)
+	assert.Equal(t, "0x01", transfers[0].(map[string]interface{})["transaction"].(map[string]interface{})["hash"])
+
+	// The end cursor continues with the next transaction
+	_, response = graphQLRequest(t, query, map[string]interface{}{"address": testAddress, "after": pageInfo["endCursor"]})
+	edges = response["data"].(map[string]interface{})["address"].(map[string]interface{})["transactions"].(map[string]interface{})["edges"].([]interface{})
+	assert.Len(t, edges, 1)
+	assert.Equal(t, "0x02", edges[0].(map[string]interface{})["node"].(map[string]interface{})["hash"])
+}
+
+func TestGraphQLV1BlockAndMutation(t *testing.T) {
+	seedGraphQLStore(t)
+
+	code, response := graphQLRequest(t, `mutation { subscribe(address: "0x0000000000000000000000000000000000000002") }`, nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, map[string]interface{}{"subscribe": true}, response["data"])
+
+	_, response = graphQLRequest(t, `{ block(number: 17) { hex transactions { hash } } subscriptions { address } }`, nil)
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"hex": "0x11", "transactions": []interface{}{map[string]interface{}{"hash": "0x02"}}}, data["block"])
+	assert.Len(t, data["subscriptions"], 2)
+
+	_, response = graphQLRequest(t, `{ address(address: "0x12") { subscribed } }`, nil)
+	assert.Equal(t, map[string]interface{}{"address": nil}, response["data"])
+	assert.Len(t, response["errors"], 1)
+}
+
+func TestGraphQLV1Errors(t *testing.T) {
+	service.InitializeModelLayer()
+	code, response := graphQLRequest(t, `{ address(address: "`+testAddress+`") { balance } }`, nil)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Nil(t, response["data"])
+	assert.Contains(t, response["errors"].([]interface{})[0].(map[string]interface{})["message"], `"balance"`)
+
+	rec := doRequest(t, http.MethodGet, "/v1/graphql?query="+`%7B%20currentBlock%20%7B%20number%20%7D%20%7D`, "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"data":{"currentBlock":{"number":0}}}`, rec.Body.String())
+
+	rec = doRequest(t, http.MethodPost, "/v1/graphql", `{}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+
+	// Mutations are only accepted over POST
+	rec = doRequest(t, http.MethodGet, "/v1/graphql?query="+url.QueryEscape(`mutation { subscribe(address: "`+testAddress+`") }`), "")
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, http.MethodPost, rec.Header().Get("Allow"))
+	assert.Empty(t, service.ParserFor(context.Background()).Subscriptions())
+
+	// Nesting lists of transactions and blocks is turned away before it runs
+	code, response = graphQLRequest(t, `{ block(number: 1) { transactions { block { transactions { block { transactions { hash } } } } } } }`, nil)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Contains(t, response["errors"].([]interface{})[0].(map[string]interface{})["message"], "maximum complexity")
+}
+
+func TestGraphQLV1Subscription(t *testing.T) {
+	seedGraphQLStore(t)
+	server := httptest.NewServer(NewRouter())
+	t.Cleanup(server.Close)
+
+	body, _ := json.Marshal(map[string]string{"query": `subscription { transactions(addresses: ["` + testAddress + `"]) { hash status } }`})
+	req, _ := http.NewRequest(http.MethodPost, server.URL+"/v1/graphql", strings.NewReader(string(body)))
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	// The subscription is registered once the response headers have been sent
+	service.FilterTransactionsByAddress([]model.Transaction{{Hash: "0x03", From: testAddress, To: "0x0000000000000000000000000000000000000002"}})
+
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if strings.HasPrefix(scanner.Text(), "data: ") {
+				lines <- strings.TrimPrefix(scanner.Text(), "data: ")
+			}
+		}
+	}()
+
+	select {
+	case line := <-lines:
+		assert.JSONEq(t, `{"data":{"transactions":{"hash":"0x03","status":"mined"}}}`, line)
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for subscription event")
+	}
+}
@@ -1,28 +1,86 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"ethereum-tx-parser/internal/metrics"
 	"ethereum-tx-parser/internal/model"
 	"ethereum-tx-parser/internal/service"
-	"log"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 )
 
-// StartServer initializes and starts the HTTP server
-func StartServer() error {
-	http.HandleFunc("/currentBlock", CurrentBlockHandler)
-	http.HandleFunc("/subscribe", SaveSubscriptionHandler)
-	http.HandleFunc("/transactions", ListTransactionsHandler)
+// shutdownTimeout bounds how long a graceful shutdown waits for in-flight requests before
+// closing the remaining connections, such as event streams
+const shutdownTimeout = 10 * time.Second
+
+// StartServer serves the API on addr, over HTTPS with the certificates of certs when it is not nil,
+// until ctx is done. It then shuts down gracefully.
+func StartServer(ctx context.Context, addr string, certs *CertReloader) error {
+	server := &http.Server{Addr: addr, Handler: NewRouter()}
+	if certs == nil {
+		httpLog.Info("server started", "addr", addr)
+		return serveUntilDone(ctx, server, server.ListenAndServe)
+	}
+	server.TLSConfig = certs.TLSConfig()
+	httpLog.Info("server started", "addr", addr, "tls", true)
+	return serveUntilDone(ctx, server, func() error { return server.ListenAndServeTLS("", "") })
+}
 
-	log.Println("Server started on :8080")
-	if err := http.ListenAndServe(":8080", nil); err != nil {
+// serveUntilDone runs serve until it fails or ctx is done, then stops accepting connections and
+// waits up to shutdownTimeout for active requests before closing the server
+func serveUntilDone(ctx context.Context, server *http.Server, serve func() error) error {
+	errs := make(chan error, 1)
+	go func() { errs <- serve() }()
+	select {
+	case err := <-errs:
 		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return server.Close()
 	}
 	return nil
 }
 
+// NewRouter builds the HTTP routes: the versioned /v1 API and the original unversioned
+// endpoints, which are kept as deprecated aliases. Every route requires authentication as
+// configured by service.RequireAPIKey and service.AdminToken, and is rate limited per API key or
+// client address as configured by service.RateLimitPerSecond. Responses carry the CORS and
+// security headers configured by CORS and SecurityHeaders, are counted in the metrics served at
+// /metrics, and are logged and traced.
+func NewRouter() http.Handler {
+	mux := http.NewServeMux()
+	registerV1Routes(mux)
+	mux.HandleFunc("POST /rpc", JSONRPCHandler)
+	mux.Handle("GET /metrics", metrics.Handler())
+	mux.HandleFunc("GET /healthz", HealthzHandler)
+	mux.HandleFunc("GET /readyz", ReadyzHandler)
+	mux.HandleFunc("GET /status", StatusHandler)
+
+	mux.HandleFunc("/currentBlock", deprecated("/v1/blocks/current", CurrentBlockHandler))
+	mux.HandleFunc("/subscribe", deprecated("/v1/subscriptions", SaveSubscriptionHandler))
+	mux.HandleFunc("/transactions", deprecated("/v1/addresses/{address}/transactions", ListTransactionsHandler))
+	mux.HandleFunc("/contracts", deprecated("/v1/addresses/{address}/contracts", ListContractsHandler))
+	mux.HandleFunc("/alerts", deprecated("/v1/alerts", ListAlertsHandler))
+	return instrument(mux, withSecurityHeaders(authenticate(mux)))
+}
+
+// deprecated marks responses of an unversioned endpoint as deprecated in favour of its /v1 successor
+func deprecated(successor string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Link", "<"+successor+">; rel=\"successor-version\"")
+		handler(w, r)
+	}
+}
+
 // CurrentBlockHandler returns the current block number
 func CurrentBlockHandler(w http.ResponseWriter, r *http.Request) {
 	setJSONResponseHeaders(w)
@@ -31,12 +89,12 @@ func CurrentBlockHandler(w http.ResponseWriter, r *http.Request) {
 	blockHex := strings.TrimPrefix(response, "0x")
 	blockNumber, err := strconv.ParseInt(blockHex, 16, 64)
 	if err != nil {
-		log.Printf("Error parsing hex block number: %v", err)
+		httpLog.ErrorContext(r.Context(), "parsing block number failed", "block", response, "error", err)
 	}
 
 	if err := json.NewEncoder(w).Encode(blockNumber); err != nil {
 		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
-		log.Printf("error encoding current block response: %v", err)
+		httpLog.ErrorContext(r.Context(), "encoding response failed", "error", err)
 	}
 }
 
@@ -49,8 +107,17 @@ func SaveSubscriptionHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Missing address", http.StatusBadRequest)
 		return
 	}
+	fromBlock, fromTimestamp, err := legacyBackfillStart(r)
+	if err == nil && r.URL.Query().Get("fromBlock") != "" {
+		err = service.CheckBackfillStart(fromBlock)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	subscribed, err := service.Subscribe(address)
+	p := service.ParserFor(r.Context())
+	subscribed, err := p.Subscribe(address)
 
 	status := "Already Subscribed"
 	if subscribed {
@@ -62,12 +129,43 @@ func SaveSubscriptionHandler(w http.ResponseWriter, r *http.Request) {
 
 	} else {
 		response = map[string]string{"status": status, "address": address}
+		if r.URL.Query().Get("fromBlock") != "" || r.URL.Query().Get("fromTimestamp") != "" {
+			if job, scheduled, err := p.Backfill(address, fromBlock, fromTimestamp); err != nil {
+				if subscribed {
+					unsubscribeAfterBackfill(r, p, address)
+				}
+				response["status"] = err.Error()
+			} else if scheduled {
+				response["backfill"] = job.ID
+			}
+		}
 	}
 
 	if err := json.NewEncoder(w).Encode(response); err != nil {
 		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
-		log.Printf("error encoding subscription response for address %s: %v", address, err)
+		httpLog.ErrorContext(r.Context(), "encoding response failed", "address", address, "error", err)
+	}
+}
+
+// legacyBackfillStart reads the fromBlock (decimal or 0x hex) or fromTimestamp query parameter
+func legacyBackfillStart(r *http.Request) (uint64, int64, error) {
+	var fromBlock *uint64
+	var fromTimestamp *int64
+	if value := r.URL.Query().Get("fromBlock"); value != "" {
+		block, err := strconv.ParseUint(value, 0, 64)
+		if err != nil {
+			return 0, 0, errors.New("fromBlock must be a block number")
+		}
+		fromBlock = &block
+	}
+	if value := r.URL.Query().Get("fromTimestamp"); value != "" {
+		timestamp, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return 0, 0, errors.New("fromTimestamp must be a Unix time in seconds")
+		}
+		fromTimestamp = &timestamp
 	}
+	return backfillStart(fromBlock, fromTimestamp)
 }
 
 // ListTransactionsHandler returns a list of transactions for a given Ethereum address
@@ -80,6 +178,11 @@ func ListTransactionsHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !service.CanReadAddress(r.Context(), address) {
+		http.Error(w, "Address not subscribed", http.StatusNotFound)
+		return
+	}
+
 	transactions := model.SharedStore().GetTransactions(strings.ToLower(address))
 	// Check if transactions are empty
 	if len(transactions) == 0 {
@@ -92,19 +195,61 @@ func ListTransactionsHandler(w http.ResponseWriter, r *http.Request) {
 
 		if err := json.NewEncoder(w).Encode(response); err != nil {
 			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
-			log.Printf("error encoding empty transactions response for address %s: %v", address, err)
+			httpLog.ErrorContext(r.Context(), "encoding response failed", "address", address, "error", err)
 		}
 		return
 	}
 
 	if err := json.NewEncoder(w).Encode(transactions); err != nil {
 		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
-		log.Printf("error encoding transactions for address %s: %v", address, err)
+		httpLog.ErrorContext(r.Context(), "encoding response failed", "address", address, "error", err)
+	}
+}
+
+// ListContractsHandler returns the contracts deployed by a given Ethereum address
+func ListContractsHandler(w http.ResponseWriter, r *http.Request) {
+	setJSONResponseHeaders(w)
+
+	address := r.URL.Query().Get("address")
+	if address == "" {
+		http.Error(w, "Missing address", http.StatusBadRequest)
+		return
+	}
+
+	if !service.CanReadAddress(r.Context(), address) {
+		http.Error(w, "Address not subscribed", http.StatusNotFound)
+		return
+	}
+
+	contracts := model.SharedStore().GetContracts(address)
+	if contracts == nil {
+		contracts = []string{}
+	}
+
+	response := map[string]interface{}{"address": address, "contracts": contracts}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		httpLog.ErrorContext(r.Context(), "encoding response failed", "address", address, "error", err)
+	}
+}
+
+// ListAlertsHandler returns nonce gap and stuck-transaction findings, optionally for a single address
+func ListAlertsHandler(w http.ResponseWriter, r *http.Request) {
+	setJSONResponseHeaders(w)
+
+	address := r.URL.Query().Get("address")
+	alerts := service.ParserFor(r.Context()).GetAlerts(address)
+	if alerts == nil {
+		alerts = []model.Alert{}
+	}
+
+	if err := json.NewEncoder(w).Encode(alerts); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		httpLog.ErrorContext(r.Context(), "encoding response failed", "error", err)
 	}
 }
 
 // setJSONResponseHeaders sets common headers for JSON responses
 func setJSONResponseHeaders(w http.ResponseWriter) {
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*") // Allows cross-origin requests
 }
@@ -0,0 +1,255 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"ethereum-tx-parser/internal/model"
+	"ethereum-tx-parser/internal/service"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testAddress = "0x1234567890abcdef1234567890abcdef12345678"
+
+// doRequest sends a request through the router against a fresh in-memory store
+func doRequest(t *testing.T, method string, target string, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	NewRouter().ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCreateSubscriptionV1(t *testing.T) {
+	service.InitializeModelLayer()
+
+	rec := doRequest(t, http.MethodPost, "/v1/subscriptions", `{"address":"`+testAddress+`"}`)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.JSONEq(t, `{"data":{"address":"`+testAddress+`"}}`, rec.Body.String())
+
+	rec = doRequest(t, http.MethodPost, "/v1/subscriptions", `{"address":"`+testAddress+`"}`)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+
+	rec = doRequest(t, http.MethodPost, "/v1/subscriptions", `{"address":"invalid"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	var problem Problem
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+	assert.Equal(t, http.StatusBadRequest, problem.Status)
+	assert.Equal(t, "/v1/subscriptions", problem.Instance)
+
+	rec = doRequest(t, http.MethodPost, "/v1/subscriptions", `not json`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestDeleteSubscriptionV1(t *testing.T) {
+	service.InitializeModelLayer()
+	service.Subscribe(testAddress)
+
+	rec := doRequest(t, http.MethodDelete, "/v1/subscriptions/"+testAddress, "")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = doRequest(t, http.MethodDelete, "/v1/subscriptions/"+testAddress, "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = doRequest(t, http.MethodDelete, "/v1/subscriptions/invalid", "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestV1MethodRouting(t *testing.T) {
+	service.InitializeModelLayer()
+
+	rec := doRequest(t, http.MethodGet, "/v1/subscriptions", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, http.MethodPost, rec.Header().Get("Allow"))
+	assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+
+	rec = doRequest(t, http.MethodGet, "/v1/unknown", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+}
+
+func TestListTransactionsV1(t *testing.T) {
+	service.InitializeModelLayer()
+	service.Subscribe(testAddress)
+
+	rec := doRequest(t, http.MethodGet, "/v1/addresses/"+testAddress+"/transactions", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"data":[],"meta":{"limit":50}}`, rec.Body.String(), "Empty lists should use the same envelope")
+
+	model.SharedStore().SaveTransaction(testAddress, model.Transaction{Hash: "0xabc", From: testAddress})
+	rec = doRequest(t, http.MethodGet, "/v1/addresses/0x"+strings.ToUpper(testAddress[2:])+"/transactions", "")
+	var body struct {
+		Data []model.Transaction `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, 1, len(body.Data))
+}
+
+func TestGetCurrentBlockV1(t *testing.T) {
+	service.InitializeModelLayer()
+	model.SharedStore().SaveBlock("0x10")
+
+	rec := doRequest(t, http.MethodGet, "/v1/blocks/current", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"data":{"number":16,"hex":"0x10"}}`, rec.Body.String())
+
+	model.SharedStore().SaveBlock("not hex")
+	rec = doRequest(t, http.MethodGet, "/v1/blocks/current", "")
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestDeprecatedRoutes(t *testing.T) {
+	service.InitializeModelLayer()
+
+	rec := doRequest(t, http.MethodGet, "/subscribe?address="+testAddress, "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "true", rec.Header().Get("Deprecation"))
+	assert.Contains(t, rec.Header().Get("Link"), "/v1/subscriptions")
+	assert.JSONEq(t, `{"status":"Subscribed","address":"`+testAddress+`"}`, rec.Body.String())
+}
+
+func TestListTransactionsV1Pagination(t *testing.T) {
+	service.InitializeModelLayer()
+	service.Subscribe(testAddress)
+	for i := 0; i < 3; i++ {
+		model.SharedStore().SaveTransaction(testAddress, model.Transaction{
+			Hash: "0x" + strings.Repeat("a", i+1), From: testAddress, Value: "0x1", BlockNumber: "0x" + strings.Repeat("1", i+1),
+		})
+	}
+
+	type page struct {
+		Data []model.Transaction `json:"data"`
+		Meta struct {
+			Limit      int    `json:"limit"`
+			NextCursor string `json:"nextCursor"`
+		} `json:"meta"`
+	}
+
+	rec := doRequest(t, http.MethodGet, "/v1/addresses/"+testAddress+"/transactions?limit=2&direction=out&minValue=1", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var first page
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &first))
+	assert.Equal(t, 2, len(first.Data))
+	assert.Equal(t, 2, first.Meta.Limit)
+	assert.NotEmpty(t, first.Meta.NextCursor)
+
+	rec = doRequest(t, http.MethodGet, "/v1/addresses/"+testAddress+"/transactions?limit=2&direction=out&minValue=1&cursor="+first.Meta.NextCursor, "")
+	var second page
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &second))
+	assert.Equal(t, 1, len(second.Data))
+	assert.Equal(t, "0xaaa", second.Data[0].Hash)
+	assert.Empty(t, second.Meta.NextCursor)
+
+	for _, params := range []string{"limit=0", "direction=sideways", "fromBlock=abc", "minValue=-1", "fromTime=yesterday", "cursor=%25%25"} {
+		rec = doRequest(t, http.MethodGet, "/v1/addresses/"+testAddress+"/transactions?"+params, "")
+		assert.Equal(t, http.StatusBadRequest, rec.Code, params)
+	}
+}
+
+// serveEmptyBlocks points the service at a node whose blocks have no transactions
+func serveEmptyBlocks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"number":"0x1","timestamp":"0x1","transactions":[]}}`))
+	}))
+	t.Cleanup(server.Close)
+	previous := service.EthereumRPCURL
+	service.EthereumRPCURL = server.URL
+	t.Cleanup(func() { service.EthereumRPCURL = previous })
+	t.Cleanup(func() { waitForBackfills(t) })
+}
+
+// waitForBackfills waits until no backfill job is queued or running
+func waitForBackfills(t *testing.T) {
+	assert.Eventually(t, func() bool {
+		for _, job := range service.GetBackfillJobs("") {
+			if job.State == model.TaskQueued || job.State == model.TaskRunning {
+				return false
+			}
+		}
+		return true
+	}, 5*time.Second, 5*time.Millisecond)
+}
+
+func TestCreateSubscriptionV1WithBackfill(t *testing.T) {
+	service.InitializeModelLayer()
+	serveEmptyBlocks(t)
+	service.SaveLatestBlock("0x20")
+
+	rec := doRequest(t, http.MethodPost, "/v1/subscriptions", `{"address":"`+testAddress+`","fromBlock":16,"fromTimestamp":1}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = doRequest(t, http.MethodPost, "/v1/subscriptions", `{"address":"`+testAddress+`","fromTimestamp":0}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	// A range over the limit is refused without subscribing
+	previous := service.MaxBackfillBlocks
+	service.MaxBackfillBlocks = 8
+	rec = doRequest(t, http.MethodPost, "/v1/subscriptions", `{"address":"`+testAddress+`","fromBlock":0}`)
+	service.MaxBackfillBlocks = previous
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "at most 8 blocks")
+	assert.Empty(t, service.ParserFor(context.Background()).Subscriptions())
+
+	// A backfill that cannot be queued leaves no subscription behind
+	queued := service.MaxQueuedBackfills
+	service.MaxQueuedBackfills = 0
+	rec = doRequest(t, http.MethodPost, "/v1/subscriptions", `{"address":"`+testAddress+`","fromBlock":16}`)
+	service.MaxQueuedBackfills = queued
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Empty(t, service.ParserFor(context.Background()).Subscriptions())
+
+	rec = doRequest(t, http.MethodPost, "/v1/subscriptions", `{"address":"`+testAddress+`","fromBlock":16}`)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	var created struct{ Data subscription }
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	if assert.NotNil(t, created.Data.Backfill) {
+		assert.EqualValues(t, 16, created.Data.Backfill.FromBlock)
+		assert.EqualValues(t, 0x1f, created.Data.Backfill.ToBlock)
+	}
+
+	// Subscribing again with a start block backfills instead of conflicting
+	rec = doRequest(t, http.MethodPost, "/v1/subscriptions", `{"address":"`+testAddress+`","fromBlock":30}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = doRequest(t, http.MethodPost, "/v1/subscriptions", `{"address":"`+testAddress+`","fromBlock":32}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"data":{"address":"`+testAddress+`"}}`, rec.Body.String())
+
+	rec = doRequest(t, http.MethodGet, "/v1/backfills/"+created.Data.Backfill.ID, "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"address":"`+testAddress+`"`)
+	rec = doRequest(t, http.MethodGet, "/v1/backfills/missing", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	rec = doRequest(t, http.MethodGet, "/v1/backfills", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), created.Data.Backfill.ID)
+}
+
+func TestSaveSubscriptionHandlerWithBackfill(t *testing.T) {
+	service.InitializeModelLayer()
+	serveEmptyBlocks(t)
+	service.SaveLatestBlock("0x20")
+
+	rec := doRequest(t, http.MethodGet, "/subscribe?address="+testAddress+"&fromBlock=nope", "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	queued := service.MaxQueuedBackfills
+	service.MaxQueuedBackfills = 0
+	rec = doRequest(t, http.MethodGet, "/subscribe?address="+testAddress+"&fromBlock=0x10", "")
+	service.MaxQueuedBackfills = queued
+	assert.Contains(t, rec.Body.String(), service.ErrBackfillQueueFull.Error())
+	assert.Empty(t, service.ParserFor(context.Background()).Subscriptions())
+
+	rec = doRequest(t, http.MethodGet, "/subscribe?address="+testAddress+"&fromBlock=0x10", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var response map[string]string
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, "Subscribed", response["status"])
+	assert.NotEmpty(t, response["backfill"])
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"encoding/json"
+	"ethereum-tx-parser/internal/model"
+	"ethereum-tx-parser/internal/service"
+	"net/http"
+)
+
+// probePaths are answered without authentication or rate limiting, since orchestrators poll them
+var probePaths = map[string]bool{"/healthz": true, "/readyz": true}
+
+// HealthzHandler reports that the process is up and serving requests
+func HealthzHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// ReadyzHandler reports whether the instance is usable: storage answers, the Ethereum node
+// answered recently and the listener is not too far behind. Not ready is a 503.
+func ReadyzHandler(w http.ResponseWriter, r *http.Request) {
+	ready, checks := service.CheckReadiness()
+	status, code := "ready", http.StatusOK
+	if !ready {
+		status, code = "not ready", http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, struct {
+		Status string                       `json:"status"`
+		Checks map[string]model.HealthCheck `json:"checks"`
+	}{status, checks})
+}
+
+// StatusHandler returns the sync progress of the block listener
+func StatusHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, service.GetSyncStatus())
+}
+
+// writeJSON writes v as a bare JSON response, for endpoints outside the /v1 envelope
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	setJSONResponseHeaders(w)
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		httpLog.Error("encoding response failed", "error", err)
+	}
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"ethereum-tx-parser/internal/model"
+	"ethereum-tx-parser/internal/service"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProbesSkipAuthentication(t *testing.T) {
+	service.InitializeModelLayer()
+	service.RequireAPIKey = true
+	t.Cleanup(func() { service.RequireAPIKey = false })
+
+	rec := doRequest(t, http.MethodGet, "/healthz", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, rec.Body.String())
+
+	rec = doRequest(t, http.MethodGet, "/readyz", "")
+	assert.NotEqual(t, http.StatusUnauthorized, rec.Code)
+
+	rec = doRequest(t, http.MethodGet, "/status", "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestReadyz(t *testing.T) {
+	service.InitializeModelLayer()
+
+	rec := doRequest(t, http.MethodGet, "/readyz", "")
+	var body struct {
+		Status string
+		Checks map[string]model.HealthCheck
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.True(t, body.Checks["storage"].OK)
+	assert.Contains(t, body.Checks, "rpc")
+	assert.Contains(t, body.Checks, "lag")
+	if body.Status == "ready" {
+		assert.Equal(t, http.StatusOK, rec.Code)
+	} else {
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	}
+	assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+}
+
+func TestStatus(t *testing.T) {
+	service.InitializeModelLayer()
+	service.SaveLatestBlock("0x2a")
+
+	rec := doRequest(t, http.MethodGet, "/status", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var status model.SyncStatus
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &status))
+	assert.Equal(t, service.Version, status.Version)
+	assert.Equal(t, int64(42), status.ProcessedBlock)
+	assert.False(t, status.StartedAt.IsZero())
+}
@@ -0,0 +1,233 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"ethereum-tx-parser/internal/model"
+	"ethereum-tx-parser/internal/service"
+	"io"
+	"net/http"
+)
+
+// Standard JSON-RPC 2.0 error codes
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcInternalError  = -32603
+	rpcLimitExceeded  = -32005 // Server-defined, as in EIP-1474
+)
+
+// maxRPCBodySize bounds a JSON-RPC request, including batches
+const maxRPCBodySize = 1 << 20
+
+// rpcRequest is a JSON-RPC 2.0 request received by the server. An absent ID marks a notification.
+type rpcRequest struct {
+	JsonRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+	ID      json.RawMessage `json:"id"`
+}
+
+// rpcResponse is a JSON-RPC 2.0 response; exactly one of Result and Error is set
+type rpcResponse struct {
+	JsonRPC string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
+// rpcError is a JSON-RPC 2.0 error object
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// rpcMethod handles the positional params of one method
+type rpcMethod func(p service.LocalParser, params []json.RawMessage) (interface{}, *rpcError)
+
+// rpcMethods are the methods served by JSONRPCHandler
+var rpcMethods = map[string]rpcMethod{
+	"parser_getCurrentBlock": func(p service.LocalParser, params []json.RawMessage) (interface{}, *rpcError) {
+		if len(params) != 0 {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: "expected no params"}
+		}
+		return internalOnError(p.GetCurrentBlock())
+	},
+	"parser_subscribe": func(p service.LocalParser, params []json.RawMessage) (interface{}, *rpcError) {
+		address, rpcErr := addressParam(params)
+		if rpcErr != nil {
+			return nil, rpcErr
+		}
+		return internalOnError(p.Subscribe(address))
+	},
+	"parser_getTransactions": func(p service.LocalParser, params []json.RawMessage) (interface{}, *rpcError) {
+		address, rpcErr := addressParam(params)
+		if rpcErr != nil {
+			return nil, rpcErr
+		}
+		transactions, err := p.GetTransactions(address)
+		if transactions == nil && err == nil {
+			transactions = []model.Transaction{}
+		}
+		return internalOnError(transactions, err)
+	},
+	"parser_getContracts": func(p service.LocalParser, params []json.RawMessage) (interface{}, *rpcError) {
+		address, rpcErr := addressParam(params)
+		if rpcErr != nil {
+			return nil, rpcErr
+		}
+		contracts, err := p.GetContracts(address)
+		if contracts == nil && err == nil {
+			contracts = []string{}
+		}
+		return internalOnError(contracts, err)
+	},
+	"parser_getAlerts": func(p service.LocalParser, params []json.RawMessage) (interface{}, *rpcError) {
+		var address string
+		if len(params) > 1 || (len(params) == 1 && json.Unmarshal(params[0], &address) != nil) {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: "expected an optional address string"}
+		}
+		alerts := p.GetAlerts(address)
+		if alerts == nil {
+			alerts = []model.Alert{}
+		}
+		return alerts, nil
+	},
+}
+
+// JSONRPCHandler serves the parser as a JSON-RPC 2.0 service, including batch requests
+func JSONRPCHandler(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRPCBodySize))
+	if err != nil {
+		writeRPC(w, rpcResponse{JsonRPC: "2.0", Error: &rpcError{Code: rpcParseError, Message: "failed to read request"}, ID: json.RawMessage("null")})
+		return
+	}
+
+	p := service.ParserFor(r.Context())
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		var batch []json.RawMessage
+		if err := json.Unmarshal(body, &batch); err != nil {
+			writeRPC(w, parseErrorResponse())
+			return
+		}
+		if len(batch) == 0 {
+			writeRPC(w, rpcResponse{JsonRPC: "2.0", Error: &rpcError{Code: rpcInvalidRequest, Message: "empty batch"}, ID: json.RawMessage("null")})
+			return
+		}
+
+		responses := []rpcResponse{}
+		for _, raw := range batch {
+			if resp, ok := handleRPC(p, raw); ok {
+				responses = append(responses, resp)
+			}
+		}
+		if len(responses) == 0 {
+			// A batch of notifications gets no response body
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		writeRPC(w, responses)
+		return
+	}
+
+	if !json.Valid(body) {
+		writeRPC(w, parseErrorResponse())
+		return
+	}
+	resp, ok := handleRPC(p, body)
+	if !ok {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeRPC(w, resp)
+}
+
+// handleRPC runs a single request. It reports false for notifications, which get no response.
+func handleRPC(p service.LocalParser, raw json.RawMessage) (rpcResponse, bool) {
+	var req rpcRequest
+	err := json.Unmarshal(raw, &req)
+	if err != nil || req.JsonRPC != "2.0" || req.Method == "" || !validRPCID(req.ID) {
+		return rpcResponse{JsonRPC: "2.0", Error: &rpcError{Code: rpcInvalidRequest, Message: "invalid request"}, ID: json.RawMessage("null")}, true
+	}
+
+	resp := rpcResponse{JsonRPC: "2.0", ID: req.ID}
+	method, ok := rpcMethods[req.Method]
+	if !ok {
+		resp.Error = &rpcError{Code: rpcMethodNotFound, Message: "method " + req.Method + " not found"}
+	} else {
+		var params []json.RawMessage
+		if len(req.Params) > 0 && string(req.Params) != "null" {
+			if err := json.Unmarshal(req.Params, &params); err != nil {
+				resp.Error = &rpcError{Code: rpcInvalidParams, Message: "params must be an array"}
+			}
+		}
+		if resp.Error == nil {
+			var result interface{}
+			if result, resp.Error = method(p, params); resp.Error == nil {
+				if resp.Result, err = json.Marshal(result); err != nil {
+					httpLog.Error("encoding JSON-RPC result failed", "method", req.Method, "error", err)
+					resp.Error = &rpcError{Code: rpcInternalError, Message: "internal error"}
+				}
+			}
+		}
+	}
+	return resp, req.ID != nil
+}
+
+// validRPCID reports whether an ID is absent, null, a string or a number
+func validRPCID(id json.RawMessage) bool {
+	if id == nil {
+		return true
+	}
+	var value interface{}
+	if err := json.Unmarshal(id, &value); err != nil {
+		return false
+	}
+	switch value.(type) {
+	case nil, string, float64:
+		return true
+	}
+	return false
+}
+
+// addressParam reads a single address string param
+func addressParam(params []json.RawMessage) (string, *rpcError) {
+	var address string
+	if len(params) != 1 || json.Unmarshal(params[0], &address) != nil {
+		return "", &rpcError{Code: rpcInvalidParams, Message: "expected a single address string"}
+	}
+	return address, nil
+}
+
+// internalOnError maps a Parser result to a JSON-RPC result or error. Invalid and unsubscribed
+// addresses are reported as invalid params and a full subscription quota as limit exceeded;
+// anything else is an internal error.
+func internalOnError(result interface{}, err error) (interface{}, *rpcError) {
+	if errors.Is(err, service.ErrInvalidAddress) || errors.Is(err, service.ErrNotSubscribed) {
+		return nil, &rpcError{Code: rpcInvalidParams, Message: err.Error()}
+	}
+	if errors.Is(err, service.ErrQuotaExceeded) {
+		return nil, &rpcError{Code: rpcLimitExceeded, Message: err.Error()}
+	}
+	if err != nil {
+		httpLog.Error("JSON-RPC call failed", "error", err)
+		return nil, &rpcError{Code: rpcInternalError, Message: "internal error"}
+	}
+	return result, nil
+}
+
+func parseErrorResponse() rpcResponse {
+	return rpcResponse{JsonRPC: "2.0", Error: &rpcError{Code: rpcParseError, Message: "parse error"}, ID: json.RawMessage("null")}
+}
+
+// writeRPC writes a JSON-RPC response or batch of responses. JSON-RPC errors use HTTP 200.
+func writeRPC(w http.ResponseWriter, v interface{}) {
+	setJSONResponseHeaders(w)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		httpLog.Error("encoding JSON-RPC response failed", "error", err)
+	}
+}
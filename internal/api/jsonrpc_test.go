@@ -0,0 +1,60 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"ethereum-tx-parser/internal/service"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONRPCHandler(t *testing.T) {
+	service.InitializeModelLayer()
+
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{"current block", `{"jsonrpc":"2.0","method":"parser_getCurrentBlock","id":1}`,
+			`{"jsonrpc":"2.0","result":0,"id":1}`},
+		{"subscribe", `{"jsonrpc":"2.0","method":"parser_subscribe","params":["` + testAddress + `"],"id":"a"}`,
+			`{"jsonrpc":"2.0","result":true,"id":"a"}`},
+		{"subscribe again", `{"jsonrpc":"2.0","method":"parser_subscribe","params":["` + testAddress + `"],"id":2}`,
+			`{"jsonrpc":"2.0","result":false,"id":2}`},
+		{"invalid address", `{"jsonrpc":"2.0","method":"parser_subscribe","params":["0x12"],"id":3}`,
+			`{"jsonrpc":"2.0","error":{"code":-32602,"message":"invalid Ethereum address"},"id":3}`},
+		{"missing params", `{"jsonrpc":"2.0","method":"parser_getTransactions","id":4}`,
+			`{"jsonrpc":"2.0","error":{"code":-32602,"message":"expected a single address string"},"id":4}`},
+		{"transactions", `{"jsonrpc":"2.0","method":"parser_getTransactions","params":["` + testAddress + `"],"id":5}`,
+			`{"jsonrpc":"2.0","result":[],"id":5}`},
+		{"unknown method", `{"jsonrpc":"2.0","method":"eth_call","id":6}`,
+			`{"jsonrpc":"2.0","error":{"code":-32601,"message":"method eth_call not found"},"id":6}`},
+		{"wrong version", `{"jsonrpc":"1.0","method":"parser_getCurrentBlock","id":7}`,
+			`{"jsonrpc":"2.0","error":{"code":-32600,"message":"invalid request"},"id":null}`},
+		{"parse error", `{"jsonrpc":`,
+			`{"jsonrpc":"2.0","error":{"code":-32700,"message":"parse error"},"id":null}`},
+		{"empty batch", `[]`,
+			`{"jsonrpc":"2.0","error":{"code":-32600,"message":"empty batch"},"id":null}`},
+		{"batch", `[{"jsonrpc":"2.0","method":"parser_getCurrentBlock","id":1},{"jsonrpc":"2.0","method":"parser_getCurrentBlock"},1]`,
+			`[{"jsonrpc":"2.0","result":0,"id":1},{"jsonrpc":"2.0","error":{"code":-32600,"message":"invalid request"},"id":null}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(t, http.MethodPost, "/rpc", tt.body)
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.JSONEq(t, tt.expected, rec.Body.String())
+		})
+	}
+}
+
+func TestJSONRPCNotifications(t *testing.T) {
+	rec := doRequest(t, http.MethodPost, "/rpc", `{"jsonrpc":"2.0","method":"parser_getCurrentBlock"}`)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Empty(t, rec.Body.String())
+
+	rec = doRequest(t, http.MethodPost, "/rpc", `[{"jsonrpc":"2.0","method":"parser_getCurrentBlock"}]`)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"ethereum-tx-parser/internal/logging"
+	"net/http"
+)
+
+// RequestIDHeader carries the ID of a request, taken from the client when it sends a usable one
+const RequestIDHeader = "X-Request-ID"
+
+var httpLog = logging.For("http")
+
+// requestID returns the request's own ID if it is short and printable, or a new random one
+func requestID(r *http.Request) string {
+	if id := r.Header.Get(RequestIDHeader); validRequestID(id) {
+		return id
+	}
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_', c == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// logLevels is the response of the log level endpoints
+type logLevels struct {
+	Level      string            `json:"level"`
+	Components map[string]string `json:"components"`
+}
+
+func currentLogLevels() logLevels {
+	levels := logLevels{Level: logging.LevelName(logging.Level()), Components: make(map[string]string)}
+	for component, l := range logging.ComponentLevels() {
+		levels.Components[component] = logging.LevelName(l)
+	}
+	return levels
+}
+
+// GetLogLevelV1 returns the global log level and the component overrides
+func GetLogLevelV1(w http.ResponseWriter, r *http.Request) {
+	writeData(w, http.StatusOK, currentLogLevels(), nil)
+}
+
+// SetLogLevelV1 changes the log level from a JSON body of level and an optional component.
+// With a component, an empty level makes the component follow the global level again.
+func SetLogLevelV1(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Level     string `json:"level"`
+		Component string `json:"component"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, "request body must be a JSON object with a level")
+		return
+	}
+
+	if req.Component != "" && req.Level == "" {
+		logging.ResetComponentLevel(req.Component)
+		httpLog.InfoContext(r.Context(), "log level reset", "target", req.Component)
+		writeData(w, http.StatusOK, currentLogLevels(), nil)
+		return
+	}
+	level, err := logging.ParseLevel(req.Level)
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.Component == "" {
+		logging.SetLevel(level)
+	} else {
+		logging.SetComponentLevel(req.Component, level)
+	}
+	httpLog.InfoContext(r.Context(), "log level changed", "target", req.Component, "level", logging.LevelName(level))
+	writeData(w, http.StatusOK, currentLogLevels(), nil)
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ethereum-tx-parser/internal/logging"
+	"ethereum-tx-parser/internal/service"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestIDHeader(t *testing.T) {
+	service.InitializeModelLayer()
+	rec := doRequest(t, http.MethodGet, "/healthz", "")
+	assert.Len(t, rec.Header().Get(RequestIDHeader), 16)
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	req.Header.Set(RequestIDHeader, "client-id.1")
+	rec = httptest.NewRecorder()
+	NewRouter().ServeHTTP(rec, req)
+	assert.Equal(t, "client-id.1", rec.Header().Get(RequestIDHeader))
+
+	req.Header.Set(RequestIDHeader, "not a usable\nid")
+	rec = httptest.NewRecorder()
+	NewRouter().ServeHTTP(rec, req)
+	assert.NotEqual(t, "not a usable\nid", rec.Header().Get(RequestIDHeader))
+}
+
+func TestLogLevelV1(t *testing.T) {
+	withAdminToken(t)
+	t.Cleanup(func() {
+		logging.SetLevel(0)
+		logging.ResetComponentLevel("rpc")
+	})
+
+	rec := doAuthRequest(t, http.MethodPut, "/v1/admin/log-level", `{"level":"debug"}`, "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = doAuthRequest(t, http.MethodPut, "/v1/admin/log-level", `{"level":"loud"}`, service.AdminToken)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = doAuthRequest(t, http.MethodPut, "/v1/admin/log-level", `{"level":"warn"}`, service.AdminToken)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = doAuthRequest(t, http.MethodPut, "/v1/admin/log-level", `{"level":"debug","component":"rpc"}`, service.AdminToken)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = doAuthRequest(t, http.MethodGet, "/v1/admin/log-level", "", service.AdminToken)
+	var levels struct{ Data logLevels }
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &levels))
+	assert.Equal(t, "warn", levels.Data.Level)
+	assert.Equal(t, map[string]string{"rpc": "debug"}, levels.Data.Components)
+
+	rec = doAuthRequest(t, http.MethodPut, "/v1/admin/log-level", `{"component":"rpc"}`, service.AdminToken)
+	var reset struct{ Data logLevels }
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &reset))
+	assert.Empty(t, reset.Data.Components)
+}
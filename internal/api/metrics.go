@@ -0,0 +1,133 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"ethereum-tx-parser/internal/logging"
+	"ethereum-tx-parser/internal/metrics"
+	"ethereum-tx-parser/internal/tracing"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "txparser_http_request_duration_seconds",
+	Help:    "Duration of HTTP requests by route, method and status. Streams and WebSockets are observed when they close.",
+	Buckets: prometheus.DefBuckets,
+}, []string{"route", "method", "status"})
+
+func init() {
+	if err := metrics.Register(httpDuration); err != nil {
+		httpLog.Error("registering metrics failed", "error", err)
+	}
+}
+
+var httpTracer = tracing.Tracer("http")
+
+// instrument records the duration and status of every request, labelled with the route pattern
+// it matches in mux rather than its path, so addresses don't create a series each. It also gives
+// each request an ID, returned in the X-Request-ID header and attached to its log records, traces
+// it in a server span that continues the caller's W3C trace context, and logs the request when it
+// completes; health probes and metric scrapes are logged at debug level.
+func instrument(mux *http.ServeMux, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		_, pattern := mux.Handler(r)
+		route := "unmatched"
+		if pattern != "" {
+			// Patterns registered with a method start with it, e.g. "GET /v1/alerts"
+			route = pattern[strings.Index(pattern, "/"):]
+		}
+
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := httpTracer.Start(ctx, r.Method+" "+route, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(
+			attribute.String("http.request.method", r.Method),
+			attribute.String("http.route", route),
+			attribute.String("url.path", r.URL.Path),
+			attribute.String("client.address", clientIP(r)),
+		))
+		id := requestID(r)
+		w.Header().Set(RequestIDHeader, id)
+		span.SetAttributes(attribute.String("http.request.id", id))
+		r = r.WithContext(logging.WithRequestID(ctx, id))
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		elapsed := time.Since(start)
+		metrics.Observe(httpDuration, elapsed.Seconds(), route, r.Method, strconv.Itoa(recorder.status))
+		span.SetAttributes(attribute.Int("http.response.status_code", recorder.status))
+		if recorder.status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(recorder.status))
+		}
+		span.End()
+
+		level := slog.LevelInfo
+		if probePaths[r.URL.Path] || r.URL.Path == "/metrics" {
+			level = slog.LevelDebug
+		}
+		httpLog.Log(r.Context(), level, "request", "method", r.Method, "route", route, "path", r.URL.Path,
+			"status", recorder.status, "duration_ms", elapsed.Milliseconds(), "client", clientIP(r))
+	})
+}
+
+// statusRecorder remembers the status code written through it. It passes on flushing and
+// hijacking, which event streams and WebSockets need.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	if !s.wroteHeader {
+		s.status, s.wroteHeader = status, true
+	}
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	s.wroteHeader = true
+	return s.ResponseWriter.Write(b)
+}
+
+func (s *statusRecorder) Flush() {
+	if flusher, ok := s.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (s *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := s.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response does not support hijacking")
+	}
+	s.status, s.wroteHeader = http.StatusSwitchingProtocols, true
+	return hijacker.Hijack()
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+// StartMetricsServer serves /metrics alone on addr, without authentication, for scrapers on a
+// private network, until ctx is done
+func StartMetricsServer(ctx context.Context, addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", metrics.Handler())
+	server := &http.Server{Addr: addr, Handler: mux}
+	return serveUntilDone(ctx, server, server.ListenAndServe)
+}
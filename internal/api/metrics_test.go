@@ -0,0 +1,79 @@
+package api
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"ethereum-tx-parser/internal/service"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+// histogramCount returns how many values a histogram series has observed
+func histogramCount(vec *prometheus.HistogramVec, labelValues ...string) uint64 {
+	var m dto.Metric
+	vec.WithLabelValues(labelValues...).(prometheus.Histogram).Write(&m)
+	return m.GetHistogram().GetSampleCount()
+}
+
+func TestMetrics(t *testing.T) {
+	service.InitializeModelLayer()
+	service.Subscribe(testAddress)
+	before := histogramCount(httpDuration, "/v1/addresses/{address}/transactions", http.MethodGet, "200")
+
+	doRequest(t, http.MethodGet, "/v1/addresses/"+testAddress+"/transactions", "")
+	doRequest(t, http.MethodGet, "/no/such/path", "")
+	assert.Equal(t, before+1, histogramCount(httpDuration, "/v1/addresses/{address}/transactions", http.MethodGet, "200"))
+	assert.NotZero(t, histogramCount(httpDuration, "unmatched", http.MethodGet, "404"))
+
+	rec := doRequest(t, http.MethodGet, "/metrics", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	for _, name := range []string{
+		"txparser_http_request_duration_seconds_bucket",
+		"txparser_processed_block",
+		"txparser_chain_head_block",
+		"txparser_block_lag",
+		"txparser_blocks_processed_total",
+		"txparser_matched_transactions_total",
+		"txparser_subscriptions",
+		"# TYPE txparser_rpc_request_duration_seconds histogram",
+		"txparser_storage_operation_duration_seconds_bucket",
+	} {
+		assert.Contains(t, body, name)
+	}
+}
+
+func TestStartMetricsServerStops(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	addr := listener.Addr().String()
+	listener.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error, 1)
+	go func() { stopped <- StartMetricsServer(ctx, addr) }()
+	assert.Eventually(t, func() bool {
+		resp, err := http.Get("http://" + addr + "/metrics")
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return resp.StatusCode == http.StatusOK
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	select {
+	case err := <-stopped:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not stop")
+	}
+	_, err = http.Get("http://" + addr + "/metrics")
+	assert.Error(t, err, "The server should no longer accept connections")
+}
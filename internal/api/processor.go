@@ -0,0 +1,79 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"ethereum-tx-parser/internal/service"
+	"net/http"
+)
+
+// rewindMeta reports what a rewind deleted
+type rewindMeta struct {
+	RemovedTransactions int `json:"removedTransactions"`
+}
+
+// GetProcessorV1 returns the state of the block processor
+func GetProcessorV1(w http.ResponseWriter, r *http.Request) {
+	writeData(w, http.StatusOK, service.GetProcessorStatus(), nil)
+}
+
+// PauseProcessorV1 pauses the block processor once its current cycle is done
+func PauseProcessorV1(w http.ResponseWriter, r *http.Request) {
+	writeData(w, http.StatusOK, service.PauseProcessor(), nil)
+}
+
+// ResumeProcessorV1 resumes a paused block processor
+func ResumeProcessorV1(w http.ResponseWriter, r *http.Request) {
+	writeData(w, http.StatusOK, service.ResumeProcessor(), nil)
+}
+
+// RewindProcessorV1 makes the block in a JSON body of block the last processed one, deleting the
+// transactions of later blocks
+func RewindProcessorV1(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Block *uint64 `json:"block"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Block == nil {
+		writeProblem(w, r, http.StatusBadRequest, "request body must be a JSON object with a block number")
+		return
+	}
+
+	removed, err := service.RewindProcessor(*req.Block)
+	if errors.Is(err, service.ErrInvalidBlockRange) {
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		writeProblem(w, r, http.StatusInternalServerError, "failed to rewind the processor")
+		return
+	}
+	writeData(w, http.StatusOK, service.GetProcessorStatus(), rewindMeta{RemovedTransactions: removed})
+}
+
+// ReprocessBlocksV1 starts reprocessing the blocks fromBlock through toBlock for the addresses in a
+// JSON body. It responds 202 with the task, whose progress GetProcessorV1 reports.
+func ReprocessBlocksV1(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		FromBlock *uint64  `json:"fromBlock"`
+		ToBlock   *uint64  `json:"toBlock"`
+		Addresses []string `json:"addresses"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.FromBlock == nil || req.ToBlock == nil {
+		writeProblem(w, r, http.StatusBadRequest, "request body must be a JSON object with fromBlock, toBlock and addresses")
+		return
+	}
+
+	task, err := service.ReprocessBlocks(*req.FromBlock, *req.ToBlock, req.Addresses)
+	switch {
+	case errors.Is(err, service.ErrInvalidBlockRange), errors.Is(err, service.ErrInvalidAddress):
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrNotSubscribed):
+		writeProblem(w, r, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrReprocessRunning):
+		writeProblem(w, r, http.StatusConflict, err.Error())
+	case err != nil:
+		writeProblem(w, r, http.StatusInternalServerError, "failed to start reprocessing")
+	default:
+		writeData(w, http.StatusAccepted, task, nil)
+	}
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"ethereum-tx-parser/internal/model"
+	"ethereum-tx-parser/internal/service"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcessorAdminV1(t *testing.T) {
+	service.InitializeModelLayer()
+	withAdminToken(t)
+	t.Cleanup(func() { service.ResumeProcessor() })
+	service.SaveLatestBlock("0x20")
+	assert.NoError(t, model.SharedStore().SaveTransaction(testAddress, model.Transaction{Hash: "0x1", BlockNumber: "0x1f"}))
+
+	rec := doAuthRequest(t, http.MethodPost, "/v1/admin/processor/pause", "", "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = doAuthRequest(t, http.MethodPost, "/v1/admin/processor/pause", "", service.AdminToken)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var status struct{ Data model.ProcessorStatus }
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &status))
+	assert.True(t, status.Data.Paused)
+	assert.EqualValues(t, 0x20, status.Data.ProcessedBlock)
+
+	rec = doAuthRequest(t, http.MethodPost, "/v1/admin/processor/rewind", `{"block":32}`, service.AdminToken)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = doAuthRequest(t, http.MethodPost, "/v1/admin/processor/rewind", `{}`, service.AdminToken)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = doAuthRequest(t, http.MethodPost, "/v1/admin/processor/rewind", `{"block":16}`, service.AdminToken)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"removedTransactions":1`)
+	assert.Empty(t, model.SharedStore().GetTransactions(testAddress))
+
+	rec = doAuthRequest(t, http.MethodPost, "/v1/admin/processor/reprocess", `{"fromBlock":1,"toBlock":2,"addresses":["`+testAddress+`"]}`, service.AdminToken)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	rec = doAuthRequest(t, http.MethodPost, "/v1/admin/processor/reprocess", `{"fromBlock":1,"toBlock":2,"addresses":["nope"]}`, service.AdminToken)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = doAuthRequest(t, http.MethodPost, "/v1/admin/processor/reprocess", `{"fromBlock":1,"addresses":["`+testAddress+`"]}`, service.AdminToken)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = doAuthRequest(t, http.MethodPost, "/v1/admin/processor/resume", "", service.AdminToken)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = doAuthRequest(t, http.MethodGet, "/v1/admin/processor", "", service.AdminToken)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"paused":false`)
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"context"
+	"ethereum-tx-parser/internal/model"
+	"ethereum-tx-parser/internal/service"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+)
+
+// clientKey is the context key of the client a request is rate limited as
+type clientKey struct{}
+
+// withClient returns a context for a request rate limited as client
+func withClient(ctx context.Context, client string) context.Context {
+	return context.WithValue(ctx, clientKey{}, client)
+}
+
+// clientIP returns the address of the peer that sent a request
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// allowRequest takes a request from the rate limit of a client and reports the remaining budget
+// in X-RateLimit headers. Over the limit it writes a 429 with Retry-After and returns false.
+func allowRequest(w http.ResponseWriter, r *http.Request, client string) bool {
+	if service.RateLimitPerSecond <= 0 {
+		return true
+	}
+	allowed, usage, wait := service.AllowRequest(client)
+
+	w.Header().Set("X-RateLimit-Limit", strconv.Itoa(usage.Burst))
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(usage.Remaining))
+	if !allowed {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		writeProblem(w, r, http.StatusTooManyRequests, "rate limit exceeded")
+	}
+	return allowed
+}
+
+// GetQuotaV1 returns the caller's subscription count and remaining request budget
+func GetQuotaV1(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := service.TenantFromContext(r.Context())
+	if !ok {
+		tenant = model.DefaultTenant
+	}
+	quota := service.GetQuota(tenant)
+	if client, ok := r.Context().Value(clientKey{}).(string); ok {
+		quota.Requests = service.RequestUsage(client)
+	}
+	writeData(w, http.StatusOK, quota, nil)
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ethereum-tx-parser/internal/model"
+	"ethereum-tx-parser/internal/service"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// doRequestFrom sends a request through the router from a client address, with an API key if set
+func doRequestFrom(t *testing.T, remoteAddr string, method string, target string, body string, key string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.RemoteAddr = remoteAddr
+	if key != "" {
+		req.Header.Set(APIKeyHeader, key)
+	}
+	rec := httptest.NewRecorder()
+	NewRouter().ServeHTTP(rec, req)
+	return rec
+}
+
+// withRateLimit enables rate limiting for a test
+func withRateLimit(t *testing.T, perSecond float64, burst int) {
+	service.RateLimitPerSecond, service.RateLimitBurst = perSecond, burst
+	t.Cleanup(func() { service.RateLimitPerSecond, service.RateLimitBurst = 0, 20 })
+}
+
+func TestRateLimit(t *testing.T) {
+	service.InitializeModelLayer()
+	withRateLimit(t, 0.5, 2)
+
+	rec := doRequestFrom(t, "198.51.100.1:1000", http.MethodGet, "/v1/blocks/current", "", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "2", rec.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "1", rec.Header().Get("X-RateLimit-Remaining"))
+
+	doRequestFrom(t, "198.51.100.1:1001", http.MethodGet, "/v1/blocks/current", "", "")
+	rec = doRequestFrom(t, "198.51.100.1:1002", http.MethodGet, "/v1/blocks/current", "", "")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "2", rec.Header().Get("Retry-After"))
+	assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+
+	// Invalid keys count against the client address
+	rec = doRequestFrom(t, "198.51.100.1:1003", http.MethodGet, "/v1/blocks/current", "", "txp_unknown")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+
+	// A valid key has its own bucket
+	_, key, err := service.IssueAPIKey("ratelimit-test", "")
+	assert.NoError(t, err)
+	rec = doRequestFrom(t, "198.51.100.1:1004", http.MethodGet, "/v1/blocks/current", "", key)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = doRequestFrom(t, "198.51.100.2:1000", http.MethodGet, "/v1/blocks/current", "", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestQuotaV1(t *testing.T) {
+	service.InitializeModelLayer()
+	withRateLimit(t, 0.01, 5)
+	service.MaxSubscriptionsPerTenant = 1
+	t.Cleanup(func() { service.MaxSubscriptionsPerTenant = 0 })
+	_, key, err := service.IssueAPIKey("quota-test", "")
+	assert.NoError(t, err)
+
+	rec := doRequestFrom(t, "198.51.100.3:1000", http.MethodPost, "/v1/subscriptions", `{"address":"`+testAddress+`"}`, key)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	rec = doRequestFrom(t, "198.51.100.3:1000", http.MethodPost, "/v1/subscriptions", `{"address":"0x0000000000000000000000000000000000000002"}`, key)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = doRequestFrom(t, "198.51.100.3:1000", http.MethodGet, "/v1/quota", "", key)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var quota struct{ Data model.Quota }
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &quota))
+	assert.Equal(t, "quota-test", quota.Data.Tenant)
+	assert.Equal(t, model.SubscriptionQuota{Used: 1, Limit: 1}, quota.Data.Subscriptions)
+	if assert.NotNil(t, quota.Data.Requests) {
+		assert.Equal(t, 5, quota.Data.Requests.Burst)
+		assert.Equal(t, 2, quota.Data.Requests.Remaining)
+	}
+
+	rec = doRequestFrom(t, "198.51.100.3:1000", http.MethodPost, "/rpc", `{"jsonrpc":"2.0","id":1,"method":"parser_subscribe","params":["0x0000000000000000000000000000000000000002"]}`, key)
+	assert.Contains(t, rec.Body.String(), "-32005")
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// envelope is the body of every successful /v1 response
+type envelope struct {
+	Data interface{} `json:"data"`
+	Meta interface{} `json:"meta,omitempty"`
+}
+
+// Problem is an RFC 7807 problem details object returned for every /v1 error
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+}
+
+// writeData writes a successful response wrapped in the standard envelope
+func writeData(w http.ResponseWriter, status int, data interface{}, meta interface{}) {
+	setJSONResponseHeaders(w)
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(envelope{Data: data, Meta: meta}); err != nil {
+		httpLog.Error("encoding response failed", "error", err)
+	}
+}
+
+// writeProblem writes an application/problem+json error response
+func writeProblem(w http.ResponseWriter, r *http.Request, status int, detail string) {
+	setJSONResponseHeaders(w)
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+
+	problem := Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: r.URL.Path,
+	}
+	if err := json.NewEncoder(w).Encode(problem); err != nil {
+		httpLog.ErrorContext(r.Context(), "encoding problem response failed", "error", err)
+	}
+}
+
+// writeNotSubscribed responds 404 for an address the caller's tenant does not subscribe to
+func writeNotSubscribed(w http.ResponseWriter, r *http.Request, address string) {
+	writeProblem(w, r, http.StatusNotFound, "address "+address+" is not subscribed")
+}
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSConfig selects the cross-origin requests browsers may make to the API
+type CORSConfig struct {
+	AllowedOrigins []string      // Exact origins such as "https://app.example.com", or "*" for any; empty disables CORS
+	AllowedMethods []string      // Methods a preflight may ask for
+	AllowedHeaders []string      // Request headers a preflight may ask for, matched case-insensitively
+	MaxAge         time.Duration // How long browsers may cache a preflight response; 0 leaves it to the browser
+}
+
+var (
+	// CORS is the cross-origin policy. The default allows any origin, as the API always has.
+	CORS = CORSConfig{
+		AllowedOrigins: []string{"*"},
+		AllowedMethods: []string{http.MethodGet, http.MethodPost, http.MethodDelete},
+		AllowedHeaders: []string{"Authorization", "Content-Type", APIKeyHeader, "Last-Event-ID"},
+		MaxAge:         10 * time.Minute,
+	}
+
+	// SecurityHeaders adds headers that stop browsers from sniffing, framing or leaking responses
+	SecurityHeaders = true
+
+	// HSTSMaxAge is the Strict-Transport-Security max-age sent over TLS; 0 omits the header
+	HSTSMaxAge = 365 * 24 * time.Hour
+)
+
+// corsExposedHeaders are the response headers cross-origin scripts may read
+var corsExposedHeaders = strings.Join([]string{"Location", "Link", "Deprecation", "Retry-After", "X-RateLimit-Limit", "X-RateLimit-Remaining"}, ", ")
+
+// withSecurityHeaders applies the CORS policy and security headers to every response. Preflight
+// requests are answered here, before authentication, since browsers send them without credentials.
+func withSecurityHeaders(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if SecurityHeaders {
+			setSecurityHeaders(w, r)
+		}
+
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Origin")
+		allowed := originAllowed(origin)
+
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			if !allowed {
+				writeProblem(w, r, http.StatusForbidden, "origin "+origin+" is not allowed")
+				return
+			}
+			preflight(w, r, origin)
+			return
+		}
+
+		if allowed {
+			setAllowOrigin(w, origin)
+			w.Header().Set("Access-Control-Expose-Headers", corsExposedHeaders)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// setSecurityHeaders sets the headers that apply to every response
+func setSecurityHeaders(w http.ResponseWriter, r *http.Request) {
+	header := w.Header()
+	header.Set("X-Content-Type-Options", "nosniff")
+	header.Set("X-Frame-Options", "DENY")
+	header.Set("Referrer-Policy", "no-referrer")
+	header.Set("Content-Security-Policy", "default-src 'none'; frame-ancestors 'none'")
+	if r.TLS != nil && HSTSMaxAge > 0 {
+		header.Set("Strict-Transport-Security", "max-age="+strconv.Itoa(int(HSTSMaxAge.Seconds())))
+	}
+}
+
+// preflight answers a CORS preflight request from an allowed origin
+func preflight(w http.ResponseWriter, r *http.Request, origin string) {
+	method := r.Header.Get("Access-Control-Request-Method")
+	if !containsFold(CORS.AllowedMethods, method) {
+		writeProblem(w, r, http.StatusForbidden, "method "+method+" is not allowed")
+		return
+	}
+	for _, header := range strings.Split(r.Header.Get("Access-Control-Request-Headers"), ",") {
+		if header = strings.TrimSpace(header); header != "" && !containsFold(CORS.AllowedHeaders, header) {
+			writeProblem(w, r, http.StatusForbidden, "header "+header+" is not allowed")
+			return
+		}
+	}
+
+	w.Header().Add("Vary", "Access-Control-Request-Method")
+	w.Header().Add("Vary", "Access-Control-Request-Headers")
+	setAllowOrigin(w, origin)
+	w.Header().Set("Access-Control-Allow-Methods", strings.Join(CORS.AllowedMethods, ", "))
+	if len(CORS.AllowedHeaders) > 0 {
+		w.Header().Set("Access-Control-Allow-Headers", strings.Join(CORS.AllowedHeaders, ", "))
+	}
+	if CORS.MaxAge > 0 {
+		w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(CORS.MaxAge.Seconds())))
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// setAllowOrigin allows an origin, answering "*" when any origin is allowed
+func setAllowOrigin(w http.ResponseWriter, origin string) {
+	if containsFold(CORS.AllowedOrigins, "*") {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		return
+	}
+	w.Header().Set("Access-Control-Allow-Origin", origin)
+}
+
+// originAllowed reports whether the CORS policy allows an origin. It also guards WebSocket
+// upgrades, which browsers make from any page without a preflight.
+func originAllowed(origin string) bool {
+	return containsFold(CORS.AllowedOrigins, "*") || containsFold(CORS.AllowedOrigins, origin)
+}
+
+// containsFold reports whether list contains s, ignoring case
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
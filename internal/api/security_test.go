@@ -0,0 +1,105 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ethereum-tx-parser/internal/service"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// doOriginRequest sends a request through the router from a browser origin
+func doOriginRequest(t *testing.T, method string, target string, origin string, headers map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, nil)
+	req.Header.Set("Origin", origin)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	rec := httptest.NewRecorder()
+	NewRouter().ServeHTTP(rec, req)
+	return rec
+}
+
+// withCORSOrigins restricts the CORS policy to origins for a test
+func withCORSOrigins(t *testing.T, origins ...string) {
+	previous := CORS
+	CORS.AllowedOrigins = origins
+	t.Cleanup(func() { CORS = previous })
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	service.InitializeModelLayer()
+
+	rec := doRequest(t, http.MethodGet, "/v1/blocks/current", "")
+	assert.Equal(t, "nosniff", rec.Header().Get("X-Content-Type-Options"))
+	assert.Equal(t, "DENY", rec.Header().Get("X-Frame-Options"))
+	assert.NotEmpty(t, rec.Header().Get("Content-Security-Policy"))
+	assert.Empty(t, rec.Header().Get("Strict-Transport-Security"))
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+
+	SecurityHeaders = false
+	t.Cleanup(func() { SecurityHeaders = true })
+	rec = doRequest(t, http.MethodGet, "/v1/blocks/current", "")
+	assert.Empty(t, rec.Header().Get("X-Frame-Options"))
+}
+
+func TestCORSDefaultAllowsAnyOrigin(t *testing.T) {
+	service.InitializeModelLayer()
+
+	rec := doOriginRequest(t, http.MethodGet, "/v1/blocks/current", "https://app.example.com", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "*", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, rec.Header().Get("Access-Control-Expose-Headers"), "Retry-After")
+}
+
+func TestCORSPreflight(t *testing.T) {
+	withCORSOrigins(t, "https://app.example.com")
+	service.RequireAPIKey = true
+	t.Cleanup(func() { service.RequireAPIKey = false })
+
+	// Preflights are answered without credentials
+	rec := doOriginRequest(t, http.MethodOptions, "/v1/subscriptions", "https://app.example.com", map[string]string{
+		"Access-Control-Request-Method":  http.MethodPost,
+		"Access-Control-Request-Headers": "content-type, x-api-key",
+	})
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "https://app.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, rec.Header().Get("Access-Control-Allow-Methods"), http.MethodPost)
+	assert.Equal(t, "600", rec.Header().Get("Access-Control-Max-Age"))
+	assert.Contains(t, rec.Header().Values("Vary"), "Origin")
+
+	rec = doOriginRequest(t, http.MethodOptions, "/v1/subscriptions", "https://app.example.com", map[string]string{
+		"Access-Control-Request-Method": http.MethodPut,
+	})
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = doOriginRequest(t, http.MethodOptions, "/v1/subscriptions", "https://app.example.com", map[string]string{
+		"Access-Control-Request-Method":  http.MethodPost,
+		"Access-Control-Request-Headers": "x-custom",
+	})
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = doOriginRequest(t, http.MethodOptions, "/v1/subscriptions", "https://evil.example.com", map[string]string{
+		"Access-Control-Request-Method": http.MethodPost,
+	})
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestCORSDisallowedOrigin(t *testing.T) {
+	service.InitializeModelLayer()
+	withCORSOrigins(t, "https://app.example.com")
+
+	rec := doOriginRequest(t, http.MethodGet, "/v1/blocks/current", "https://evil.example.com", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+
+	rec = doOriginRequest(t, http.MethodGet, "/v1/ws", "https://evil.example.com", map[string]string{
+		"Connection": "Upgrade",
+		"Upgrade":    "websocket",
+	})
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"encoding/json"
+	"ethereum-tx-parser/internal/model"
+	"ethereum-tx-parser/internal/service"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// streamHeartbeat is how often an idle event stream sends a comment to keep proxies from closing it
+var streamHeartbeat = 15 * time.Second
+
+// StreamTransactionsV1 streams transaction events for one or more addresses as Server-Sent Events.
+// Addresses are given as repeated or comma-separated address parameters. Each event's id is its
+// sequence number, so a reconnecting client sending Last-Event-ID receives only what it missed.
+func StreamTransactionsV1(w http.ResponseWriter, r *http.Request) {
+	addresses := make(map[string]bool)
+	for _, param := range r.URL.Query()["address"] {
+		for _, address := range strings.Split(param, ",") {
+			if address = strings.TrimSpace(address); address != "" {
+				addresses[strings.ToLower(address)] = true
+			}
+		}
+	}
+	if len(addresses) == 0 {
+		writeProblem(w, r, http.StatusBadRequest, "missing address")
+		return
+	}
+	for address := range addresses {
+		if !service.CanReadAddress(r.Context(), address) {
+			writeNotSubscribed(w, r, address)
+			return
+		}
+	}
+
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = r.URL.Query().Get("lastEventId")
+	}
+	var lastSeq uint64
+	if lastID != "" {
+		var err error
+		if lastSeq, err = strconv.ParseUint(lastID, 10, 64); err != nil {
+			writeProblem(w, r, http.StatusBadRequest, "Last-Event-ID must be an event sequence number")
+			return
+		}
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeProblem(w, r, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+
+	// New clients start from the current position. Subscribing before replaying ensures nothing
+	// published in between is lost; the sequence number check below drops anything seen twice.
+	if lastID == "" {
+		lastSeq = service.LastEventSeq()
+	}
+	events, unsubscribe := service.SubscribeEvents(256)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	send := func(event model.Event) bool {
+		if event.Seq <= lastSeq {
+			return true
+		}
+		lastSeq = event.Seq
+		if event.Type != model.EventTransaction || !addresses[event.Address] || !service.CanReadAddress(r.Context(), event.Address) {
+			return true
+		}
+		if err := writeSSE(w, event); err != nil {
+			httpLog.DebugContext(r.Context(), "writing stream event failed", "error", err)
+			return false
+		}
+		flusher.Flush()
+		return true
+	}
+
+	for _, event := range service.EventsSince(lastSeq) {
+		if !send(event) {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				// Dropped for falling behind; the client reconnects and resumes from its last id
+				return
+			}
+			if !send(event) {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// writeSSE writes one event in text/event-stream format
+func writeSSE(w http.ResponseWriter, event model.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Seq, event.Type, data)
+	return err
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"ethereum-tx-parser/internal/model"
+	"ethereum-tx-parser/internal/service"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// sseEvent is one parsed text/event-stream message
+type sseEvent struct {
+	id    string
+	event model.Event
+}
+
+// openStream connects to the transaction stream and returns a channel of parsed events
+func openStream(t *testing.T, url string, lastEventID string) <-chan sseEvent {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	events := make(chan sseEvent, 10)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		var current sseEvent
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case strings.HasPrefix(line, "id: "):
+				current.id = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "data: "):
+				json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &current.event)
+			case line == "" && current.id != "":
+				events <- current
+				current = sseEvent{}
+			}
+		}
+	}()
+	return events
+}
+
+func nextEvent(t *testing.T, events <-chan sseEvent) sseEvent {
+	t.Helper()
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for event")
+		return sseEvent{}
+	}
+}
+
+func TestStreamTransactionsV1(t *testing.T) {
+	service.InitializeModelLayer()
+	other := "0xabcdefabcdefabcdefabcdefabcdefabcdefabcd"
+	service.Subscribe(testAddress)
+	service.Subscribe(other)
+
+	server := httptest.NewServer(NewRouter())
+	t.Cleanup(server.Close) // Runs after the stream bodies are closed
+	url := server.URL + "/v1/stream/transactions?address=" + testAddress
+
+	events := openStream(t, url, "")
+	service.FilterTransactionsByAddress([]model.Transaction{
+		{Hash: "0x01", From: other, To: "0x0000000000000000000000000000000000000001"},
+		{Hash: "0x02", From: testAddress, To: "0x0000000000000000000000000000000000000001"},
+		{Hash: "0x03", From: testAddress, To: "0x0000000000000000000000000000000000000001"},
+	})
+
+	first := nextEvent(t, events)
+	assert.Equal(t, testAddress, first.event.Address)
+	assert.Equal(t, "0x02", first.event.Data.(map[string]interface{})["hash"], "Events for other addresses should be filtered out")
+	second := nextEvent(t, events)
+	assert.Equal(t, "0x03", second.event.Data.(map[string]interface{})["hash"])
+
+	// Reconnecting with the first id replays only what came after it
+	resumed := openStream(t, url, first.id)
+	replayed := nextEvent(t, resumed)
+	assert.Equal(t, second.id, replayed.id)
+}
+
+func TestStreamTransactionsV1Validation(t *testing.T) {
+	rec := doRequest(t, http.MethodGet, "/v1/stream/transactions", "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/stream/transactions?address="+testAddress, nil)
+	req.Header.Set("Last-Event-ID", "abc")
+	rec = httptest.NewRecorder()
+	NewRouter().ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeCert writes a certificate and key for commonName to dir, signed by parent or self-signed
+// when parent is nil, and returns the certificate, key and file paths
+func writeCert(t *testing.T, dir string, commonName string, isCA bool, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey, string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		DNSNames:              []string{"localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+	certFile := filepath.Join(dir, commonName+".crt")
+	keyFile := filepath.Join(dir, commonName+".key")
+	assert.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	assert.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	return cert, key, certFile, keyFile
+}
+
+// startTLSServer serves a handler with the certificates of a reloader
+func startTLSServer(t *testing.T, certs *CertReloader) string {
+	t.Helper()
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	server.TLS = certs.TLSConfig()
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+// tlsGet connects with the given client certificates, trusting any server certificate, and
+// returns the common name of the certificate the server presented
+func tlsGet(t *testing.T, url string, clientCerts []tls.Certificate) (string, error) {
+	t.Helper()
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+		InsecureSkipVerify: true,
+		Certificates:       clientCerts,
+	}}}
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	return resp.TLS.PeerCertificates[0].Subject.CommonName, nil
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	_, _, certFile, keyFile := writeCert(t, dir, "first", false, nil, nil)
+
+	certs, err := NewCertReloader(TLSConfig{CertFile: certFile, KeyFile: keyFile})
+	assert.NoError(t, err)
+	url := startTLSServer(t, certs)
+
+	name, err := tlsGet(t, url, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "first", name)
+
+	// Replace the files in place, as a certificate renewal would
+	_, _, secondCert, secondKey := writeCert(t, dir, "second", false, nil, nil)
+	assert.NoError(t, os.Rename(secondCert, certFile))
+	assert.NoError(t, os.Rename(secondKey, keyFile))
+	assert.NoError(t, certs.Reload())
+
+	name, err = tlsGet(t, url, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "second", name)
+
+	// A broken file keeps the current certificate
+	assert.NoError(t, os.WriteFile(certFile, []byte("not a certificate"), 0600))
+	assert.Error(t, certs.Reload())
+	name, err = tlsGet(t, url, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "second", name)
+}
+
+func TestCertReloaderClientAuth(t *testing.T) {
+	dir := t.TempDir()
+	ca, caKey, caFile, _ := writeCert(t, dir, "ca", true, nil, nil)
+	_, _, certFile, keyFile := writeCert(t, dir, "server", false, ca, caKey)
+	_, _, clientCertFile, clientKeyFile := writeCert(t, dir, "client", false, ca, caKey)
+
+	_, err := NewCertReloader(TLSConfig{CertFile: certFile, KeyFile: keyFile, RequireClientCert: true})
+	assert.Error(t, err)
+
+	certs, err := NewCertReloader(TLSConfig{CertFile: certFile, KeyFile: keyFile, ClientCAFile: caFile, RequireClientCert: true})
+	assert.NoError(t, err)
+	url := startTLSServer(t, certs)
+
+	_, err = tlsGet(t, url, nil)
+	assert.Error(t, err)
+
+	clientCert, err := tls.LoadX509KeyPair(clientCertFile, clientKeyFile)
+	assert.NoError(t, err)
+	_, err = tlsGet(t, url, []tls.Certificate{clientCert})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,419 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"ethereum-tx-parser/internal/model"
+	"ethereum-tx-parser/internal/service"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Page sizes for list endpoints
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 500
+)
+
+// currentBlock is the /v1 representation of the last processed block
+type currentBlock struct {
+	Number int64  `json:"number"`
+	Hex    string `json:"hex"`
+}
+
+// subscription is the /v1 representation of a subscribed address
+type subscription struct {
+	Address  string             `json:"address"`
+	Backfill *model.BackfillJob `json:"backfill,omitempty"` // Scheduled by subscribing with fromBlock or fromTimestamp
+}
+
+// registerV1Routes adds the versioned API to the router
+func registerV1Routes(mux *http.ServeMux) {
+	route(mux, http.MethodGet, "/v1/blocks/current", GetCurrentBlockV1)
+	route(mux, http.MethodPost, "/v1/subscriptions", CreateSubscriptionV1)
+	route(mux, http.MethodDelete, "/v1/subscriptions/{address}", DeleteSubscriptionV1)
+	route(mux, http.MethodGet, "/v1/backfills", ListBackfillsV1)
+	route(mux, http.MethodGet, "/v1/backfills/{id}", GetBackfillV1)
+	route(mux, http.MethodGet, "/v1/addresses/{address}/transactions", ListTransactionsV1)
+	route(mux, http.MethodGet, "/v1/addresses/{address}/contracts", ListContractsV1)
+	route(mux, http.MethodGet, "/v1/alerts", ListAlertsV1)
+	route(mux, http.MethodGet, "/v1/quota", GetQuotaV1)
+	route(mux, http.MethodGet, "/v1/stream/transactions", StreamTransactionsV1)
+	route(mux, http.MethodGet, "/v1/ws", WebSocketV1)
+	routes(mux, "/v1/graphql", map[string]http.HandlerFunc{
+		http.MethodGet:  GraphQLV1,
+		http.MethodPost: GraphQLV1,
+	})
+	routes(mux, "/v1/webhooks", map[string]http.HandlerFunc{
+		http.MethodGet:  ListWebhooksV1,
+		http.MethodPost: CreateWebhookV1,
+	})
+	route(mux, http.MethodDelete, "/v1/webhooks/{id}", DeleteWebhookV1)
+	route(mux, http.MethodGet, "/v1/webhooks/{id}/deliveries", ListWebhookDeliveriesV1)
+	route(mux, http.MethodPost, "/v1/webhooks/{id}/deliveries/{delivery}/replay", ReplayWebhookDeliveryV1)
+	routes(mux, "/v1/admin/keys", map[string]http.HandlerFunc{
+		http.MethodGet:  ListAPIKeysV1,
+		http.MethodPost: CreateAPIKeyV1,
+	})
+	route(mux, http.MethodDelete, "/v1/admin/keys/{id}", RevokeAPIKeyV1)
+	routes(mux, "/v1/admin/log-level", map[string]http.HandlerFunc{
+		http.MethodGet: GetLogLevelV1,
+		http.MethodPut: SetLogLevelV1,
+	})
+	route(mux, http.MethodGet, "/v1/admin/processor", GetProcessorV1)
+	route(mux, http.MethodPost, "/v1/admin/processor/pause", PauseProcessorV1)
+	route(mux, http.MethodPost, "/v1/admin/processor/resume", ResumeProcessorV1)
+	route(mux, http.MethodPost, "/v1/admin/processor/rewind", RewindProcessorV1)
+	route(mux, http.MethodPost, "/v1/admin/processor/reprocess", ReprocessBlocksV1)
+
+	mux.HandleFunc("/v1/", func(w http.ResponseWriter, r *http.Request) {
+		writeProblem(w, r, http.StatusNotFound, "no such endpoint")
+	})
+}
+
+// route registers a handler for one method and answers other methods with a 405 problem
+func route(mux *http.ServeMux, method string, path string, handler http.HandlerFunc) {
+	routes(mux, path, map[string]http.HandlerFunc{method: handler})
+}
+
+// routes registers a handler per method on one path and answers other methods with a 405 problem
+func routes(mux *http.ServeMux, path string, handlers map[string]http.HandlerFunc) {
+	methods := make([]string, 0, len(handlers))
+	for method, handler := range handlers {
+		mux.HandleFunc(method+" "+path, handler)
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	allow := strings.Join(methods, ", ")
+
+	mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Allow", allow)
+		writeProblem(w, r, http.StatusMethodNotAllowed, "use "+allow+" for this endpoint")
+	})
+}
+
+// GetCurrentBlockV1 returns the last processed block number
+func GetCurrentBlockV1(w http.ResponseWriter, r *http.Request) {
+	blockHex, err := model.SharedStore().GetCurrentBlock()
+	if err != nil {
+		writeProblem(w, r, http.StatusInternalServerError, "failed to read current block")
+		return
+	}
+
+	number, err := strconv.ParseInt(strings.TrimPrefix(blockHex, "0x"), 16, 64)
+	if err != nil {
+		writeProblem(w, r, http.StatusInternalServerError, "stored block number is not valid hex")
+		return
+	}
+
+	writeData(w, http.StatusOK, currentBlock{Number: number, Hex: blockHex}, nil)
+}
+
+// CreateSubscriptionV1 subscribes the address in the JSON request body.
+// It responds 201 for a new subscription and 409 if the address is already subscribed. With a
+// fromBlock or fromTimestamp it also schedules a backfill of the blocks processed before, returned
+// in the response, and responds 200 instead of 409 for an address already subscribed.
+func CreateSubscriptionV1(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Address       string  `json:"address"`
+		FromBlock     *uint64 `json:"fromBlock"`
+		FromTimestamp *int64  `json:"fromTimestamp"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, "request body must be a JSON object with an address")
+		return
+	}
+	if req.Address == "" {
+		writeProblem(w, r, http.StatusBadRequest, "missing address")
+		return
+	}
+	fromBlock, fromTimestamp, err := backfillStart(req.FromBlock, req.FromTimestamp)
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	backfill := req.FromBlock != nil || req.FromTimestamp != nil
+	if req.FromBlock != nil {
+		if err := service.CheckBackfillStart(fromBlock); err != nil {
+			writeBackfillProblem(w, r, err)
+			return
+		}
+	}
+
+	p := service.ParserFor(r.Context())
+	subscribed, err := p.Subscribe(req.Address)
+	if err == nil && backfill {
+		created := subscription{Address: strings.ToLower(req.Address)}
+		job, scheduled, err := p.Backfill(req.Address, fromBlock, fromTimestamp)
+		if err != nil {
+			// Undo the new subscription, so a retry creates it again
+			if subscribed {
+				unsubscribeAfterBackfill(r, p, req.Address)
+			}
+			writeBackfillProblem(w, r, err)
+			return
+		}
+		if scheduled {
+			created.Backfill = &job
+		}
+		status := http.StatusOK
+		if subscribed {
+			status = http.StatusCreated
+			w.Header().Set("Location", "/v1/addresses/"+created.Address+"/transactions")
+		}
+		writeData(w, status, created, nil)
+		return
+	}
+	switch {
+	case errors.Is(err, service.ErrInvalidAddress):
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrQuotaExceeded):
+		writeProblem(w, r, http.StatusForbidden, "subscription limit of "+strconv.Itoa(service.MaxSubscriptionsPerTenant)+" addresses reached")
+	case err != nil:
+		writeProblem(w, r, http.StatusInternalServerError, "failed to save subscription")
+	case !subscribed:
+		writeProblem(w, r, http.StatusConflict, "address "+req.Address+" is already subscribed")
+	default:
+		w.Header().Set("Location", "/v1/addresses/"+strings.ToLower(req.Address)+"/transactions")
+		writeData(w, http.StatusCreated, subscription{Address: strings.ToLower(req.Address)}, nil)
+	}
+}
+
+// unsubscribeAfterBackfill removes a subscription made for a backfill that could not be scheduled
+func unsubscribeAfterBackfill(r *http.Request, p service.LocalParser, address string) {
+	if _, err := p.Unsubscribe(address); err != nil {
+		httpLog.ErrorContext(r.Context(), "removing subscription after failed backfill failed", "address", address, "error", err)
+	}
+}
+
+// DeleteSubscriptionV1 stops observing an address. Transactions already stored are kept.
+func DeleteSubscriptionV1(w http.ResponseWriter, r *http.Request) {
+	address := r.PathValue("address")
+	unsubscribed, err := service.ParserFor(r.Context()).Unsubscribe(address)
+	switch {
+	case errors.Is(err, service.ErrInvalidAddress):
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
+	case err != nil:
+		writeProblem(w, r, http.StatusInternalServerError, "failed to remove subscription")
+	case !unsubscribed:
+		writeNotSubscribed(w, r, address)
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// transactionsMeta describes the page returned by ListTransactionsV1
+type transactionsMeta struct {
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// ListTransactionsV1 returns a page of the stored transactions of an address.
+// See parseTransactionQuery for the supported query parameters.
+func ListTransactionsV1(w http.ResponseWriter, r *http.Request) {
+	query, err := parseTransactionQuery(r.URL.Query())
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !service.CanReadAddress(r.Context(), r.PathValue("address")) {
+		writeNotSubscribed(w, r, r.PathValue("address"))
+		return
+	}
+
+	page, err := model.SharedStore().QueryTransactions(r.PathValue("address"), query)
+	if errors.Is(err, model.ErrInvalidCursor) {
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		writeProblem(w, r, http.StatusInternalServerError, "failed to query transactions")
+		return
+	}
+
+	transactions := page.Transactions
+	if transactions == nil {
+		transactions = []model.Transaction{}
+	}
+	writeData(w, http.StatusOK, transactions, transactionsMeta{Limit: query.Limit, NextCursor: page.NextCursor})
+}
+
+// parseTransactionQuery reads the transaction filters from query parameters:
+// direction (in|out), fromBlock, toBlock, fromTime, toTime (unix seconds or RFC 3339),
+// minValue, maxValue (wei, decimal or 0x hex), counterparty, status, sort (block|time),
+// order (asc|desc), limit and cursor
+func parseTransactionQuery(values url.Values) (model.TransactionQuery, error) {
+	query := model.TransactionQuery{
+		Direction:    values.Get("direction"),
+		Counterparty: values.Get("counterparty"),
+		Status:       values.Get("status"),
+		SortBy:       values.Get("sort"),
+		Order:        values.Get("order"),
+		Cursor:       values.Get("cursor"),
+		Limit:        defaultPageLimit,
+	}
+
+	switch query.Direction {
+	case "", model.DirectionIn, model.DirectionOut:
+	default:
+		return query, fmt.Errorf("direction must be %q or %q", model.DirectionIn, model.DirectionOut)
+	}
+	switch query.Status {
+	case "", model.TxStatusPending, model.TxStatusMined, model.TxStatusDropped, model.TxStatusReplaced:
+	default:
+		return query, fmt.Errorf("unknown status %q", query.Status)
+	}
+	switch query.SortBy {
+	case "", model.SortByBlock, model.SortByTime:
+	default:
+		return query, fmt.Errorf("sort must be %q or %q", model.SortByBlock, model.SortByTime)
+	}
+	switch query.Order {
+	case "", model.OrderAsc, model.OrderDesc:
+	default:
+		return query, fmt.Errorf("order must be %q or %q", model.OrderAsc, model.OrderDesc)
+	}
+
+	if limit := values.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > maxPageLimit {
+			return query, fmt.Errorf("limit must be between 1 and %d", maxPageLimit)
+		}
+		query.Limit = n
+	}
+
+	var err error
+	if query.FromBlock, err = parseBlockParam(values, "fromBlock"); err != nil {
+		return query, err
+	}
+	if query.ToBlock, err = parseBlockParam(values, "toBlock"); err != nil {
+		return query, err
+	}
+	if query.FromTime, err = parseTimeParam(values, "fromTime"); err != nil {
+		return query, err
+	}
+	if query.ToTime, err = parseTimeParam(values, "toTime"); err != nil {
+		return query, err
+	}
+	if query.MinValue, err = parseValueParam(values, "minValue"); err != nil {
+		return query, err
+	}
+	if query.MaxValue, err = parseValueParam(values, "maxValue"); err != nil {
+		return query, err
+	}
+	return query, nil
+}
+
+// parseBlockParam parses an optional decimal or 0x hex block number
+func parseBlockParam(values url.Values, name string) (*uint64, error) {
+	value := values.Get(name)
+	if value == "" {
+		return nil, nil
+	}
+	n, err := strconv.ParseUint(value, 0, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be a block number", name)
+	}
+	return &n, nil
+}
+
+// parseTimeParam parses an optional unix timestamp or RFC 3339 time
+func parseTimeParam(values url.Values, name string) (int64, error) {
+	value := values.Get(name)
+	if value == "" {
+		return 0, nil
+	}
+	if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return n, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a unix timestamp or RFC 3339 time", name)
+	}
+	return t.Unix(), nil
+}
+
+// parseValueParam parses an optional decimal or 0x hex wei amount
+func parseValueParam(values url.Values, name string) (*big.Int, error) {
+	value := values.Get(name)
+	if value == "" {
+		return nil, nil
+	}
+	n, ok := new(big.Int).SetString(value, 0)
+	if !ok || n.Sign() < 0 {
+		return nil, fmt.Errorf("%s must be a non-negative wei amount", name)
+	}
+	return n, nil
+}
+
+// ListContractsV1 returns the contracts deployed by an address
+func ListContractsV1(w http.ResponseWriter, r *http.Request) {
+	if !service.CanReadAddress(r.Context(), r.PathValue("address")) {
+		writeNotSubscribed(w, r, r.PathValue("address"))
+		return
+	}
+
+	contracts := model.SharedStore().GetContracts(r.PathValue("address"))
+	if contracts == nil {
+		contracts = []string{}
+	}
+	writeData(w, http.StatusOK, contracts, nil)
+}
+
+// ListAlertsV1 returns pending transaction findings, optionally filtered by address
+func ListAlertsV1(w http.ResponseWriter, r *http.Request) {
+	alerts := service.ParserFor(r.Context()).GetAlerts(r.URL.Query().Get("address"))
+	if alerts == nil {
+		alerts = []model.Alert{}
+	}
+	writeData(w, http.StatusOK, alerts, nil)
+}
+
+// backfillStart validates the start of a backfill given as a block number or a Unix timestamp
+func backfillStart(fromBlock *uint64, fromTimestamp *int64) (uint64, int64, error) {
+	switch {
+	case fromBlock != nil && fromTimestamp != nil:
+		return 0, 0, errors.New("give fromBlock or fromTimestamp, not both")
+	case fromBlock != nil:
+		return *fromBlock, 0, nil
+	case fromTimestamp != nil && *fromTimestamp <= 0:
+		return 0, 0, errors.New("fromTimestamp must be a positive Unix time in seconds")
+	case fromTimestamp != nil:
+		return 0, *fromTimestamp, nil
+	}
+	return 0, 0, nil
+}
+
+// writeBackfillProblem responds with the problem of a backfill that could not be scheduled
+func writeBackfillProblem(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidBlockRange):
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrBackfillQueueFull):
+		writeProblem(w, r, http.StatusTooManyRequests, err.Error())
+	default:
+		writeProblem(w, r, http.StatusInternalServerError, "failed to schedule backfill")
+	}
+}
+
+// ListBackfillsV1 returns the caller's backfill jobs, oldest first
+func ListBackfillsV1(w http.ResponseWriter, r *http.Request) {
+	writeData(w, http.StatusOK, service.ParserFor(r.Context()).BackfillJobs(), nil)
+}
+
+// GetBackfillV1 returns the progress of a backfill job
+func GetBackfillV1(w http.ResponseWriter, r *http.Request) {
+	job, err := service.ParserFor(r.Context()).BackfillJob(r.PathValue("id"))
+	if err != nil {
+		writeProblem(w, r, http.StatusNotFound, err.Error())
+		return
+	}
+	writeData(w, http.StatusOK, job, nil)
+}
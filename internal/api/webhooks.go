@@ -0,0 +1,128 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"ethereum-tx-parser/internal/model"
+	"ethereum-tx-parser/internal/service"
+	"net/http"
+)
+
+// CreateWebhookV1 registers a webhook from a JSON body of url, secret, addresses and events.
+// The response includes the signing secret, which is not returned by any other endpoint.
+func CreateWebhookV1(w http.ResponseWriter, r *http.Request) {
+	var req model.Webhook
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, "request body must be a JSON object with a url")
+		return
+	}
+
+	tenant, _ := service.TenantFromContext(r.Context())
+	webhook, err := service.CreateWebhook(model.Webhook{
+		URL:       req.URL,
+		Secret:    req.Secret,
+		Addresses: req.Addresses,
+		Events:    req.Events,
+		Tenant:    tenant,
+	})
+	if errors.Is(err, service.ErrInvalidWebhook) {
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		writeProblem(w, r, http.StatusInternalServerError, "failed to save webhook")
+		return
+	}
+
+	w.Header().Set("Location", "/v1/webhooks/"+webhook.ID+"/deliveries")
+	writeData(w, http.StatusCreated, webhook, nil)
+}
+
+// ListWebhooksV1 returns the registered webhooks the caller may manage
+func ListWebhooksV1(w http.ResponseWriter, r *http.Request) {
+	webhooks := []model.Webhook{}
+	for _, webhook := range service.GetWebhooks() {
+		if canManageWebhook(r, webhook) {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+	writeData(w, http.StatusOK, webhooks, nil)
+}
+
+// DeleteWebhookV1 removes a webhook and its queued deliveries
+func DeleteWebhookV1(w http.ResponseWriter, r *http.Request) {
+	if !webhookVisible(r, r.PathValue("id")) {
+		writeProblem(w, r, http.StatusNotFound, service.ErrWebhookNotFound.Error())
+		return
+	}
+	if err := service.DeleteWebhook(r.PathValue("id")); err != nil {
+		writeProblem(w, r, http.StatusNotFound, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListWebhookDeliveriesV1 returns the deliveries of a webhook, optionally filtered by
+// status (pending, delivered or dead)
+func ListWebhookDeliveriesV1(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", model.DeliveryPending, model.DeliveryDelivered, model.DeliveryDead:
+	default:
+		writeProblem(w, r, http.StatusBadRequest, "unknown status "+status)
+		return
+	}
+
+	if !webhookVisible(r, r.PathValue("id")) {
+		writeProblem(w, r, http.StatusNotFound, service.ErrWebhookNotFound.Error())
+		return
+	}
+	deliveries, err := service.GetWebhookDeliveries(r.PathValue("id"), status)
+	if err != nil {
+		writeProblem(w, r, http.StatusNotFound, err.Error())
+		return
+	}
+	if deliveries == nil {
+		deliveries = []model.WebhookDelivery{}
+	}
+	writeData(w, http.StatusOK, deliveries, nil)
+}
+
+// ReplayWebhookDeliveryV1 queues a delivery to be sent again
+func ReplayWebhookDeliveryV1(w http.ResponseWriter, r *http.Request) {
+	if !webhookVisible(r, r.PathValue("id")) {
+		writeProblem(w, r, http.StatusNotFound, service.ErrWebhookNotFound.Error())
+		return
+	}
+	delivery, err := service.ReplayWebhookDelivery(r.PathValue("id"), r.PathValue("delivery"))
+	if err != nil {
+		writeProblem(w, r, http.StatusNotFound, err.Error())
+		return
+	}
+	writeData(w, http.StatusAccepted, delivery, nil)
+}
+
+// canManageWebhook reports whether the caller may see and change a webhook. Callers only manage
+// the webhooks of their tenant; those without an API key act for the default tenant.
+func canManageWebhook(r *http.Request, webhook model.Webhook) bool {
+	tenant, _ := service.TenantFromContext(r.Context())
+	return webhookTenant(webhook.Tenant) == webhookTenant(tenant)
+}
+
+// webhookTenant returns the tenant a webhook acts for, the default tenant when it has none
+func webhookTenant(tenant string) string {
+	if tenant == "" {
+		return model.DefaultTenant
+	}
+	return tenant
+}
+
+// webhookVisible reports whether a webhook exists and the caller may manage it
+func webhookVisible(r *http.Request, id string) bool {
+	for _, webhook := range service.GetWebhooks() {
+		if webhook.ID == id {
+			return canManageWebhook(r, webhook)
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"ethereum-tx-parser/internal/model"
+	"ethereum-tx-parser/internal/service"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhooksV1(t *testing.T) {
+	rec := doRequest(t, http.MethodPost, "/v1/webhooks", `{"url":"ftp://example.com"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = doRequest(t, http.MethodPost, "/v1/webhooks", `{"url":"http://example.com/hook","events":["transaction"],"addresses":["`+testAddress+`"]}`)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	var created struct{ Data model.Webhook }
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	assert.NotEmpty(t, created.Data.ID)
+	assert.NotEmpty(t, created.Data.Secret)
+
+	rec = doRequest(t, http.MethodGet, "/v1/webhooks", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), created.Data.ID)
+	assert.NotContains(t, rec.Body.String(), created.Data.Secret)
+
+	rec = doRequest(t, http.MethodGet, "/v1/webhooks/"+created.Data.ID+"/deliveries?status=dead", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"data":[]}`, rec.Body.String())
+
+	rec = doRequest(t, http.MethodPost, "/v1/webhooks/"+created.Data.ID+"/deliveries/missing/replay", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = doRequest(t, http.MethodPut, "/v1/webhooks", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, "GET, POST", rec.Header().Get("Allow"))
+
+	rec = doRequest(t, http.MethodDelete, "/v1/webhooks/"+created.Data.ID, "")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Empty(t, service.GetWebhooks())
+
+	rec = doRequest(t, http.MethodGet, "/v1/webhooks/"+created.Data.ID+"/deliveries", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,216 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"ethereum-tx-parser/internal/model"
+	"ethereum-tx-parser/internal/service"
+	"ethereum-tx-parser/internal/websocket"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limits for WebSocket clients
+var (
+	wsSendBuffer   = 256              // Events queued per connection before it counts as slow
+	wsWriteTimeout = 10 * time.Second // A write blocking longer than this drops the connection
+)
+
+// wsRequest is a message sent by a WebSocket client
+type wsRequest struct {
+	Action    string   `json:"action"` // "subscribe" or "unsubscribe"
+	Addresses []string `json:"addresses"`
+	Events    []string `json:"events"`
+}
+
+// wsReply acknowledges a client request or reports an error
+type wsReply struct {
+	Type      string   `json:"type"` // "subscribed", "unsubscribed" or "error"
+	Addresses []string `json:"addresses,omitempty"`
+	Events    []string `json:"events,omitempty"`
+	Message   string   `json:"message,omitempty"`
+}
+
+// wsEventTypes are the event types a WebSocket client may subscribe to
+var wsEventTypes = map[string]bool{
+	model.EventTransaction:   true,
+	model.EventTokenTransfer: true,
+	model.EventReorg:         true,
+	model.EventBlock:         true,
+	model.EventAlert:         true,
+}
+
+// wsSubscription is the set of addresses and event types a connection is interested in
+type wsSubscription struct {
+	mu        sync.Mutex
+	addresses map[string]bool
+	events    map[string]bool
+}
+
+// apply adds or removes the addresses and event types of a request
+func (s *wsSubscription) apply(req wsRequest) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subscribe := req.Action == "subscribe"
+	for _, address := range req.Addresses {
+		setMember(s.addresses, strings.ToLower(address), subscribe)
+	}
+	for _, event := range req.Events {
+		setMember(s.events, event, subscribe)
+	}
+}
+
+// wants reports whether an event should be pushed. Block and reorg events concern the whole
+// chain; every other type is only pushed for subscribed addresses.
+func (s *wsSubscription) wants(event model.Event) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.events[event.Type] {
+		return false
+	}
+	if event.Type == model.EventBlock || event.Type == model.EventReorg {
+		return true
+	}
+	return s.addresses[event.Address]
+}
+
+// snapshot returns the current subscription for acknowledgements
+func (s *wsSubscription) snapshot() ([]string, []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return setKeys(s.addresses), setKeys(s.events)
+}
+
+// WebSocketV1 upgrades to a WebSocket on which the client manages its own subscriptions with
+// {"action": "subscribe"|"unsubscribe", "addresses": [...], "events": [...]} messages and
+// receives matching events as they are published. Connections that cannot keep up are closed
+// with status 1008 and the reason "slow consumer". Upgrades from browser origins the CORS policy
+// does not allow are refused.
+func WebSocketV1(w http.ResponseWriter, r *http.Request) {
+	if origin := r.Header.Get("Origin"); origin != "" && !originAllowed(origin) {
+		writeProblem(w, r, http.StatusForbidden, "origin "+origin+" is not allowed")
+		return
+	}
+	conn, err := websocket.Upgrade(w, r)
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	defer conn.Close()
+
+	events, unsubscribe := service.SubscribeEvents(wsSendBuffer)
+	defer unsubscribe()
+
+	subscription := &wsSubscription{addresses: make(map[string]bool), events: make(map[string]bool)}
+	done := make(chan struct{})
+	go readWebSocketRequests(r.Context(), conn, subscription, done)
+
+	for {
+		select {
+		case <-done:
+			return
+		case event, ok := <-events:
+			if !ok {
+				conn.WriteClose(websocket.ClosePolicyViolation, "slow consumer")
+				return
+			}
+			// Rechecked per event, since the tenant may have unsubscribed since
+			if !subscription.wants(event) || (event.Address != "" && !service.CanReadAddress(r.Context(), event.Address)) {
+				continue
+			}
+			if err := writeWebSocketJSON(conn, event); err != nil {
+				conn.WriteClose(websocket.ClosePolicyViolation, "slow consumer")
+				return
+			}
+		}
+	}
+}
+
+// readWebSocketRequests applies client requests until the connection closes
+func readWebSocketRequests(ctx context.Context, conn *websocket.Conn, subscription *wsSubscription, done chan<- struct{}) {
+	defer close(done)
+	for {
+		message, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var req wsRequest
+		if err := json.Unmarshal(message, &req); err != nil {
+			writeWebSocketJSON(conn, wsReply{Type: "error", Message: "messages must be JSON objects"})
+			continue
+		}
+		if req.Action != "subscribe" && req.Action != "unsubscribe" {
+			writeWebSocketJSON(conn, wsReply{Type: "error", Message: `action must be "subscribe" or "unsubscribe"`})
+			continue
+		}
+		if invalid := invalidEventType(req.Events); invalid != "" {
+			writeWebSocketJSON(conn, wsReply{Type: "error", Message: "unknown event type " + invalid})
+			continue
+		}
+
+		if unreadable := unreadableAddress(ctx, req); unreadable != "" {
+			writeWebSocketJSON(conn, wsReply{Type: "error", Message: "address " + unreadable + " is not subscribed"})
+			continue
+		}
+
+		subscription.apply(req)
+		addresses, eventTypes := subscription.snapshot()
+		writeWebSocketJSON(conn, wsReply{Type: req.Action + "d", Addresses: addresses, Events: eventTypes})
+	}
+}
+
+// writeWebSocketJSON sends a JSON text message within the write timeout
+func writeWebSocketJSON(conn *websocket.Conn, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		httpLog.Error("encoding WebSocket message failed", "error", err)
+		return err
+	}
+	conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	return conn.WriteText(data)
+}
+
+// unreadableAddress returns the first address a subscribe request asks for that the caller's
+// tenant does not subscribe to
+func unreadableAddress(ctx context.Context, req wsRequest) string {
+	if req.Action != "subscribe" {
+		return ""
+	}
+	for _, address := range req.Addresses {
+		if !service.CanReadAddress(ctx, address) {
+			return address
+		}
+	}
+	return ""
+}
+
+// invalidEventType returns the first unknown event type in the list
+func invalidEventType(events []string) string {
+	for _, event := range events {
+		if !wsEventTypes[event] {
+			return event
+		}
+	}
+	return ""
+}
+
+func setMember(set map[string]bool, key string, member bool) {
+	if member {
+		set[key] = true
+	} else {
+		delete(set, key)
+	}
+}
+
+func setKeys(set map[string]bool) []string {
+	keys := []string{}
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
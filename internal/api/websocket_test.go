@@ -0,0 +1,91 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"ethereum-tx-parser/internal/model"
+	"ethereum-tx-parser/internal/service"
+	"ethereum-tx-parser/internal/websocket"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// readJSON reads the next WebSocket message into v
+func readJSON(t *testing.T, conn *websocket.Conn, v interface{}) {
+	t.Helper()
+	done := make(chan error, 1)
+	go func() {
+		message, err := conn.ReadMessage()
+		if err == nil {
+			err = json.Unmarshal(message, v)
+		}
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for message")
+	}
+}
+
+func sendJSON(t *testing.T, conn *websocket.Conn, v interface{}) {
+	t.Helper()
+	data, _ := json.Marshal(v)
+	assert.NoError(t, conn.WriteText(data))
+}
+
+func TestWebSocketV1(t *testing.T) {
+	service.InitializeModelLayer()
+	service.Subscribe(testAddress)
+
+	server := httptest.NewServer(NewRouter())
+	t.Cleanup(server.Close)
+	conn, err := websocket.Dial("ws" + strings.TrimPrefix(server.URL, "http") + "/v1/ws")
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	sendJSON(t, conn, wsRequest{Action: "subscribe", Addresses: []string{strings.ToUpper(testAddress[2:])}, Events: []string{"bogus"}})
+	var reply wsReply
+	readJSON(t, conn, &reply)
+	assert.Equal(t, "error", reply.Type)
+
+	sendJSON(t, conn, wsRequest{Action: "subscribe", Addresses: []string{testAddress}, Events: []string{model.EventTransaction, model.EventBlock}})
+	reply = wsReply{}
+	readJSON(t, conn, &reply)
+	assert.Equal(t, wsReply{Type: "subscribed", Addresses: []string{testAddress}, Events: []string{model.EventBlock, model.EventTransaction}}, reply)
+
+	service.FilterTransactionsByAddress([]model.Transaction{
+		{Hash: "0x01", From: "0x0000000000000000000000000000000000000001", To: "0x0000000000000000000000000000000000000002"},
+		{Hash: "0x02", From: testAddress, To: "0x0000000000000000000000000000000000000002"},
+	})
+	service.RecordProcessedBlock(model.Block{Number: "0x1", Hash: "0xb1"})
+
+	var event model.Event
+	readJSON(t, conn, &event)
+	assert.Equal(t, model.EventTransaction, event.Type)
+	assert.Equal(t, "0x02", event.Data.(map[string]interface{})["hash"])
+	readJSON(t, conn, &event)
+	assert.Equal(t, model.EventBlock, event.Type)
+
+	// After unsubscribing from transactions only block events arrive
+	sendJSON(t, conn, wsRequest{Action: "unsubscribe", Events: []string{model.EventTransaction}})
+	reply = wsReply{}
+	readJSON(t, conn, &reply)
+	assert.Equal(t, "unsubscribed", reply.Type)
+	assert.Equal(t, []string{model.EventBlock}, reply.Events)
+
+	service.FilterTransactionsByAddress([]model.Transaction{{Hash: "0x03", From: testAddress}})
+	service.RecordProcessedBlock(model.Block{Number: "0x2", Hash: "0xb2", ParentHash: "0xb1"})
+	readJSON(t, conn, &event)
+	assert.Equal(t, model.EventBlock, event.Type)
+}
+
+func TestWebSocketV1RequiresUpgrade(t *testing.T) {
+	rec := doRequest(t, "GET", "/v1/ws", "")
+	assert.Equal(t, 400, rec.Code)
+}
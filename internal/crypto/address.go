@@ -0,0 +1,21 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"ethereum-tx-parser/internal/rlp"
+)
+
+// CreateAddress computes the address of a contract deployed by sender with the given nonce,
+// i.e. the last 20 bytes of keccak256(rlp([sender, nonce]))
+func CreateAddress(sender string, nonce uint64) (string, error) {
+	senderBytes, err := hex.DecodeString(strings.TrimPrefix(strings.ToLower(sender), "0x"))
+	if err != nil || len(senderBytes) != 20 {
+		return "", errors.New("invalid sender address")
+	}
+
+	hash := Keccak256(rlp.EncodeList(rlp.EncodeBytes(senderBytes), rlp.EncodeUint(nonce)))
+	return "0x" + hex.EncodeToString(hash[12:]), nil
+}
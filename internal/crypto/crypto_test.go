@@ -0,0 +1,95 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeccak256(t *testing.T) {
+	tests := []struct {
+		input    []byte
+		expected string
+	}{
+		{nil, "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470"},
+		{[]byte("abc"), "4e03657aea45a94fc7d47ba826c8d667c0d1e6e33a64a036ec44f58fa12d6c45"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, hex.EncodeToString(Keccak256(tt.input)))
+	}
+
+	// Input spanning more than one rate-sized block
+	long := make([]byte, 200)
+	assert.Equal(t, hex.EncodeToString(Keccak256(long)), hex.EncodeToString(Keccak256(long[:100], long[100:])))
+}
+
+func TestCreateAddress(t *testing.T) {
+	sender := "0x970e8128ab834e8eac17ab8e3812f010678cf791"
+
+	address, err := CreateAddress(sender, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "0x333c3310824b7c685133f2bedb2ca4b8b4df633d", address)
+
+	address, err = CreateAddress(sender, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "0x8bda78331c916a08481428e4b07c96d3e916d165", address)
+
+	_, err = CreateAddress("0x1234", 0)
+	assert.Error(t, err)
+}
+
+func TestPrivateKeyToAddress(t *testing.T) {
+	assert.Equal(t, "0x7e5f4552091a69125d5dfcb7b8c2659029395bdf", PrivateKeyToAddress(big.NewInt(1)))
+}
+
+func TestRecoverAddress(t *testing.T) {
+	// Signing hash and signature of the EIP-155 example transaction, signed with key 0x4646...46
+	hash, _ := hex.DecodeString("daf5a779ae972f972197303d7b574746c7ef83eadac0f2791ad23db92e4c8e53")
+	r, _ := new(big.Int).SetString("28ef61340bd939bc2195fe537567866003e1a15d3c71ff63e1590620aa636276", 16)
+	s, _ := new(big.Int).SetString("67cbe9d8997f761aecb703304b3800ccf555c9f3dc64214b297fb1966a3b6d83", 16)
+
+	address, err := RecoverAddress(hash, r, s, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "0x9d8a62f656a8d1615c1294fd71e9cfb3e4855a4f", address)
+
+	address, err = RecoverAddress(hash, r, s, 1)
+	assert.NoError(t, err)
+	assert.NotEqual(t, "0x9d8a62f656a8d1615c1294fd71e9cfb3e4855a4f", address, "Wrong parity should recover a different key")
+
+	// The high-s form of the same signature recovers the same key with the opposite parity
+	highS := new(big.Int).Sub(curveN, s)
+	address, err = RecoverAddress(hash, r, highS, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "0x9d8a62f656a8d1615c1294fd71e9cfb3e4855a4f", address)
+
+	_, err = RecoverAddress(hash, r, curveN, 0)
+	assert.Error(t, err, "s outside the curve order should be rejected")
+}
+
+func TestValidSignatureValues(t *testing.T) {
+	one := big.NewInt(1)
+	highS := new(big.Int).Add(halfN, one)
+
+	assert.True(t, ValidSignatureValues(one, halfN, true))
+	assert.True(t, ValidSignatureValues(one, highS, false), "High-s is allowed before Homestead")
+	assert.False(t, ValidSignatureValues(one, highS, true), "High-s is rejected since Homestead")
+	assert.False(t, ValidSignatureValues(big.NewInt(0), one, false))
+	assert.False(t, ValidSignatureValues(one, curveN, false))
+}
+
+func TestSignAndRecover(t *testing.T) {
+	key, _ := new(big.Int).SetString("4646464646464646464646464646464646464646464646464646464646464646", 16)
+	hash := Keccak256([]byte("message"))
+
+	for _, k := range []int64{12345, 67890, 424242} {
+		r, s, recID, err := sign(hash, key, big.NewInt(k))
+		assert.NoError(t, err)
+
+		address, err := RecoverAddress(hash, r, s, recID)
+		assert.NoError(t, err)
+		assert.Equal(t, PrivateKeyToAddress(key), address)
+	}
+}
@@ -0,0 +1,142 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"errors"
+	"math/big"
+)
+
+// secp256k1 domain parameters
+var (
+	curveP, _  = new(big.Int).SetString("fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc2f", 16)
+	curveN, _  = new(big.Int).SetString("fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141", 16)
+	curveGx, _ = new(big.Int).SetString("79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798", 16)
+	curveGy, _ = new(big.Int).SetString("483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8", 16)
+	curveB     = big.NewInt(7)
+	halfN      = new(big.Int).Rsh(curveN, 1)
+)
+
+// point is an affine curve point; nil coordinates represent the point at infinity
+type point struct {
+	x, y *big.Int
+}
+
+func (p point) infinity() bool {
+	return p.x == nil
+}
+
+// add returns p+q using affine coordinates
+func add(p, q point) point {
+	if p.infinity() {
+		return q
+	}
+	if q.infinity() {
+		return p
+	}
+
+	var slope *big.Int
+	if p.x.Cmp(q.x) == 0 {
+		sum := new(big.Int).Add(p.y, q.y)
+		if sum.Mod(sum, curveP).Sign() == 0 {
+			return point{}
+		}
+		// Tangent slope: 3x² / 2y
+		num := new(big.Int).Mul(p.x, p.x)
+		num.Mul(num, big.NewInt(3))
+		den := new(big.Int).Lsh(p.y, 1)
+		slope = num.Mul(num, den.ModInverse(den, curveP))
+	} else {
+		num := new(big.Int).Sub(q.y, p.y)
+		den := new(big.Int).Sub(q.x, p.x)
+		den.Mod(den, curveP)
+		slope = num.Mul(num, den.ModInverse(den, curveP))
+	}
+	slope.Mod(slope, curveP)
+
+	x := new(big.Int).Mul(slope, slope)
+	x.Sub(x, p.x).Sub(x, q.x).Mod(x, curveP)
+	y := new(big.Int).Sub(p.x, x)
+	y.Mul(y, slope).Sub(y, p.y).Mod(y, curveP)
+	return point{x, y}
+}
+
+// scalarMult returns k*p using double-and-add
+func scalarMult(p point, k *big.Int) point {
+	result := point{}
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		result = add(result, result)
+		if k.Bit(i) == 1 {
+			result = add(result, p)
+		}
+	}
+	return result
+}
+
+// PublicKeyToAddress derives the Ethereum address of an uncompressed public key (x, y)
+func PublicKeyToAddress(x, y *big.Int) string {
+	buf := make([]byte, 64)
+	x.FillBytes(buf[:32])
+	y.FillBytes(buf[32:])
+	return "0x" + hex.EncodeToString(Keccak256(buf)[12:])
+}
+
+// PrivateKeyToAddress derives the Ethereum address controlled by a private key
+func PrivateKeyToAddress(key *big.Int) string {
+	pub := scalarMult(point{curveGx, curveGy}, key)
+	return PublicKeyToAddress(pub.x, pub.y)
+}
+
+// ValidSignatureValues reports whether r and s are valid signature values. Since Homestead
+// (EIP-2), s must also be in the lower half of the curve order.
+func ValidSignatureValues(r, s *big.Int, homestead bool) bool {
+	if r.Sign() <= 0 || r.Cmp(curveN) >= 0 || s.Sign() <= 0 || s.Cmp(curveN) >= 0 {
+		return false
+	}
+	return !homestead || s.Cmp(halfN) <= 0
+}
+
+// RecoverAddress recovers the address of the key that produced signature (r, s, recID) over hash.
+// It accepts high-s signatures; use ValidSignatureValues to enforce the Homestead rule.
+func RecoverAddress(hash []byte, r, s *big.Int, recID byte) (string, error) {
+	if len(hash) != 32 {
+		return "", errors.New("hash must be 32 bytes")
+	}
+	if recID > 3 {
+		return "", errors.New("invalid recovery id")
+	}
+	if !ValidSignatureValues(r, s, false) {
+		return "", errors.New("invalid signature values")
+	}
+
+	// Reconstruct the nonce point R from its x coordinate and y parity
+	x := new(big.Int).Set(r)
+	if recID&2 != 0 {
+		x.Add(x, curveN)
+		if x.Cmp(curveP) >= 0 {
+			return "", errors.New("invalid signature r")
+		}
+	}
+	ySquared := new(big.Int).Exp(x, big.NewInt(3), curveP)
+	ySquared.Add(ySquared, curveB).Mod(ySquared, curveP)
+	y := new(big.Int).ModSqrt(ySquared, curveP)
+	if y == nil {
+		return "", errors.New("invalid signature: point not on curve")
+	}
+	if y.Bit(0) != uint(recID&1) {
+		y.Sub(curveP, y)
+	}
+
+	// Q = r⁻¹(sR - eG)
+	e := new(big.Int).SetBytes(hash)
+	rInv := new(big.Int).ModInverse(r, curveN)
+	u1 := new(big.Int).Neg(e)
+	u1.Mul(u1, rInv).Mod(u1, curveN)
+	u2 := new(big.Int).Mul(s, rInv)
+	u2.Mod(u2, curveN)
+
+	q := add(scalarMult(point{curveGx, curveGy}, u1), scalarMult(point{x, y}, u2))
+	if q.infinity() {
+		return "", errors.New("invalid signature: recovered point at infinity")
+	}
+	return PublicKeyToAddress(q.x, q.y), nil
+}
@@ -0,0 +1,30 @@
+package crypto
+
+import (
+	"errors"
+	"math/big"
+)
+
+// sign produces an ECDSA signature (r, s, recovery id) over a 32-byte hash using the given nonce k.
+func sign(hash []byte, key *big.Int, k *big.Int) (*big.Int, *big.Int, byte, error) {
+	rPoint := scalarMult(point{curveGx, curveGy}, k)
+	r := new(big.Int).Mod(rPoint.x, curveN)
+	if r.Sign() == 0 {
+		return nil, nil, 0, errors.New("invalid nonce")
+	}
+
+	e := new(big.Int).SetBytes(hash)
+	s := new(big.Int).Mul(r, key)
+	s.Add(s, e).Mul(s, new(big.Int).ModInverse(k, curveN)).Mod(s, curveN)
+	recID := byte(rPoint.y.Bit(0))
+	if rPoint.x.Cmp(curveN) >= 0 {
+		recID |= 2
+	}
+
+	// Normalise to low-s form as required by Ethereum, which flips the recovered point's parity
+	if s.Cmp(halfN) > 0 {
+		s.Sub(curveN, s)
+		recID ^= 1
+	}
+	return r, s, recID, nil
+}
@@ -0,0 +1,534 @@
+// Package graphql is a small GraphQL executor for schemas defined in Go. It supports queries,
+// mutations and subscriptions with variables, aliases, fragments and the @skip and @include
+// directives, and limits on how deep and costly an operation may be. Introspection is not
+// supported.
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Schema is the root of a GraphQL schema. Mutation and Subscription are optional.
+type Schema struct {
+	Query        *Object
+	Mutation     *Object
+	Subscription *Object
+
+	// MaxDepth is how deeply an operation may nest fields, and MaxComplexity the highest cost it
+	// may have (see FieldDef.Cost); 0 means no limit
+	MaxDepth      int
+	MaxComplexity int
+}
+
+// Object is a GraphQL object type
+type Object struct {
+	Name   string
+	Fields map[string]*FieldDef
+}
+
+// FieldDef defines a field of an object type
+type FieldDef struct {
+	// Type is the object type of the field's value, or of each element if it resolves to a
+	// slice. It is nil for scalar fields, whose values are returned as they are.
+	Type *Object
+
+	// Args maps each argument name to its type, e.g. "String!", "Int" or "[String!]"
+	Args map[string]string
+
+	// Cost is the number of values a list field is expected to resolve to, by which the cost of
+	// its selection set is multiplied; 0 counts as 1. Every selected field costs 1 itself.
+	Cost int
+
+	// Resolve returns the value of the field for its parent value, p.Source
+	Resolve func(p ResolveParams) (interface{}, error)
+
+	// Subscribe starts a subscription root field. Each value received on the channel is resolved
+	// as the field's value; the channel must be closed once p.Context is done.
+	Subscribe func(p ResolveParams) (<-chan interface{}, error)
+}
+
+// ResolveParams are passed to resolvers
+type ResolveParams struct {
+	Context context.Context
+	Source  interface{}            // Value of the parent object
+	Args    map[string]interface{} // Coerced arguments; absent optional arguments are nil
+}
+
+// Request is a GraphQL request as sent over HTTP
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// Result is a GraphQL response. Data is absent when the request failed before execution.
+type Result struct {
+	Data   interface{} `json:"data,omitempty"`
+	Errors []*Error    `json:"errors,omitempty"`
+}
+
+// Error is a GraphQL error with the location in the document and the response path it concerns
+type Error struct {
+	Message   string        `json:"message"`
+	Locations []Location    `json:"locations,omitempty"`
+	Path      []interface{} `json:"path,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Location is a line and column in a GraphQL document, both starting at 1
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// orderedMap is a JSON object that keeps fields in selection order
+type orderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func newOrderedMap() *orderedMap {
+	return &orderedMap{values: make(map[string]interface{})}
+}
+
+func (m *orderedMap) set(key string, value interface{}) {
+	if _, exists := m.values[key]; !exists {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+// MarshalJSON encodes the map with its keys in insertion order
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		buf.Write(k)
+		buf.WriteByte(':')
+		v, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// prepared is a validated operation ready to execute
+type prepared struct {
+	schema    *Schema
+	doc       *Document
+	op        *Operation
+	root      *Object
+	variables map[string]interface{}
+}
+
+// prepare parses and validates a request and selects the operation to run
+func prepare(schema *Schema, req Request) (*prepared, []*Error) {
+	doc, err := Parse(req.Query)
+	if err != nil {
+		return nil, []*Error{toError(err)}
+	}
+	op, opErr := selectOperation(doc, req.OperationName)
+	if opErr != nil {
+		return nil, []*Error{opErr}
+	}
+
+	p := &prepared{schema: schema, doc: doc, op: op}
+	switch op.Type {
+	case "query":
+		p.root = schema.Query
+	case "mutation":
+		p.root = schema.Mutation
+	case "subscription":
+		p.root = schema.Subscription
+	}
+	if p.root == nil {
+		return nil, []*Error{{Message: fmt.Sprintf("schema does not support %s operations", op.Type), Locations: []Location{op.Loc}}}
+	}
+
+	if errs := p.validate(); len(errs) > 0 {
+		return nil, errs
+	}
+
+	variables, errs := coerceVariables(op, req.Variables)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	p.variables = variables
+	return p, nil
+}
+
+// selectOperation returns the operation of a document a request names, or its only operation
+func selectOperation(doc *Document, name string) (*Operation, *Error) {
+	var op *Operation
+	for _, candidate := range doc.Operations {
+		if name == "" || candidate.Name == name {
+			if op != nil {
+				return nil, &Error{Message: "operationName is required when the document has several operations"}
+			}
+			op = candidate
+		}
+	}
+	if op == nil {
+		return nil, &Error{Message: fmt.Sprintf("unknown operation %q", name)}
+	}
+	return op, nil
+}
+
+// OperationType returns whether a request selects a query, mutation or subscription, or "" when
+// it selects no operation, which Execute reports as an error
+func OperationType(req Request) string {
+	doc, err := Parse(req.Query)
+	if err != nil {
+		return ""
+	}
+	op, opErr := selectOperation(doc, req.OperationName)
+	if opErr != nil {
+		return ""
+	}
+	return op.Type
+}
+
+// Execute runs a query or mutation. Subscriptions must be started with Subscribe.
+func Execute(ctx context.Context, schema *Schema, req Request) *Result {
+	p, errs := prepare(schema, req)
+	if len(errs) > 0 {
+		return &Result{Errors: errs}
+	}
+	if p.op.Type == "subscription" {
+		return &Result{Errors: []*Error{{Message: "subscriptions must be streamed", Locations: []Location{p.op.Loc}}}}
+	}
+
+	e := &executor{ctx: ctx, prepared: p}
+	data := e.selectionSet(p.root, nil, p.op.SelectionSet, nil)
+	return &Result{Data: data, Errors: e.errors}
+}
+
+// Subscribe starts a subscription and returns a channel receiving one result per event. The
+// channel is closed when ctx is done or the source ends. Request errors are returned as a
+// result instead, with a nil channel.
+func Subscribe(ctx context.Context, schema *Schema, req Request) (<-chan *Result, *Result) {
+	p, errs := prepare(schema, req)
+	if len(errs) > 0 {
+		return nil, &Result{Errors: errs}
+	}
+	if p.op.Type != "subscription" {
+		return nil, &Result{Errors: []*Error{{Message: "operation is not a subscription", Locations: []Location{p.op.Loc}}}}
+	}
+
+	e := &executor{ctx: ctx, prepared: p}
+	fields := e.collectFields(p.root, p.op.SelectionSet, nil)
+	key := fields.keys[0]
+	field := fields.fields[key][0]
+	def := p.root.Fields[field.Name]
+
+	args, err := e.arguments(def, field)
+	if err == nil && def.Subscribe == nil {
+		err = fmt.Errorf("field %s cannot be subscribed to", field.Name)
+	}
+	var source <-chan interface{}
+	if err == nil {
+		source, err = def.Subscribe(ResolveParams{Context: ctx, Args: args})
+	}
+	if err != nil {
+		return nil, &Result{Errors: []*Error{{Message: err.Error(), Locations: []Location{field.Loc}, Path: []interface{}{key}}}}
+	}
+
+	results := make(chan *Result)
+	go func() {
+		defer close(results)
+		for value := range source {
+			event := &executor{ctx: ctx, prepared: p}
+			data := newOrderedMap()
+			data.set(key, event.complete(def.Type, fields.fields[key], value, []interface{}{key}))
+
+			select {
+			case results <- &Result{Data: data, Errors: event.errors}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return results, nil
+}
+
+// executor resolves an operation and accumulates field errors
+type executor struct {
+	*prepared
+	ctx    context.Context
+	errors []*Error
+}
+
+// groupedFields are the fields of a selection set grouped by response key, in document order
+type groupedFields struct {
+	keys   []string
+	fields map[string][]*Field
+}
+
+// collectFields flattens fragments and applies @skip and @include
+func (e *executor) collectFields(object *Object, selections []Selection, grouped *groupedFields) *groupedFields {
+	if grouped == nil {
+		grouped = &groupedFields{fields: make(map[string][]*Field)}
+	}
+
+	for _, selection := range selections {
+		switch s := selection.(type) {
+		case *Field:
+			if !e.included(s.Directives) {
+				continue
+			}
+			key := s.ResponseKey()
+			if _, exists := grouped.fields[key]; !exists {
+				grouped.keys = append(grouped.keys, key)
+			}
+			grouped.fields[key] = append(grouped.fields[key], s)
+		case *FragmentSpread:
+			if e.included(s.Directives) {
+				e.collectFields(object, e.doc.Fragments[s.Name].SelectionSet, grouped)
+			}
+		case *InlineFragment:
+			if e.included(s.Directives) {
+				e.collectFields(object, s.SelectionSet, grouped)
+			}
+		}
+	}
+	return grouped
+}
+
+// included evaluates @skip(if:) and @include(if:)
+func (e *executor) included(directives []Directive) bool {
+	for _, directive := range directives {
+		condition, _ := e.substitute(directive.Arguments["if"]).(bool)
+		if (directive.Name == "skip" && condition) || (directive.Name == "include" && !condition) {
+			return false
+		}
+	}
+	return true
+}
+
+// selectionSet resolves the fields selected on an object value
+func (e *executor) selectionSet(object *Object, source interface{}, selections []Selection, path []interface{}) *orderedMap {
+	grouped := e.collectFields(object, selections, nil)
+	result := newOrderedMap()
+	for _, key := range grouped.keys {
+		fields := grouped.fields[key]
+		fieldPath := append(append([]interface{}(nil), path...), key)
+
+		if fields[0].Name == "__typename" {
+			result.set(key, object.Name)
+			continue
+		}
+
+		def := object.Fields[fields[0].Name]
+		value, err := e.resolve(def, fields[0], source)
+		if err != nil {
+			e.errors = append(e.errors, &Error{Message: err.Error(), Locations: []Location{fields[0].Loc}, Path: fieldPath})
+			result.set(key, nil)
+			continue
+		}
+		result.set(key, e.complete(def.Type, fields, value, fieldPath))
+	}
+	return result
+}
+
+// resolve coerces a field's arguments and calls its resolver
+func (e *executor) resolve(def *FieldDef, field *Field, source interface{}) (interface{}, error) {
+	args, err := e.arguments(def, field)
+	if err != nil {
+		return nil, err
+	}
+	if def.Resolve == nil {
+		if m, ok := source.(map[string]interface{}); ok {
+			return m[field.Name], nil
+		}
+		return nil, nil
+	}
+	return def.Resolve(ResolveParams{Context: e.ctx, Source: source, Args: args})
+}
+
+// complete turns a resolved value into its response value, recursing into object types
+func (e *executor) complete(object *Object, fields []*Field, value interface{}, path []interface{}) interface{} {
+	if isNil(value) {
+		return nil
+	}
+	if object == nil {
+		return value
+	}
+
+	if items, ok := asSlice(value); ok {
+		list := make([]interface{}, len(items))
+		for i, item := range items {
+			list[i] = e.complete(object, fields, item, append(append([]interface{}(nil), path...), i))
+		}
+		return list
+	}
+
+	var selections []Selection
+	for _, field := range fields {
+		selections = append(selections, field.SelectionSet...)
+	}
+	return e.selectionSet(object, value, selections, path)
+}
+
+// arguments substitutes variables into a field's arguments and coerces them to their types
+func (e *executor) arguments(def *FieldDef, field *Field) (map[string]interface{}, error) {
+	args := make(map[string]interface{}, len(def.Args))
+	for name, typeRef := range def.Args {
+		literal, given := field.Arguments[name]
+		value := e.substitute(literal)
+		if !given || value == nil {
+			if strings.HasSuffix(typeRef, "!") {
+				return nil, fmt.Errorf("argument %s of type %s is required", name, typeRef)
+			}
+			args[name] = nil
+			continue
+		}
+
+		coerced, err := coerce(typeRef, value)
+		if err != nil {
+			return nil, fmt.Errorf("argument %s: %v", name, err)
+		}
+		args[name] = coerced
+	}
+	return args, nil
+}
+
+// substitute replaces variable references in a literal with their values
+func (e *executor) substitute(literal interface{}) interface{} {
+	switch v := literal.(type) {
+	case Variable:
+		return e.variables[string(v)]
+	case EnumValue:
+		return string(v)
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			list[i] = e.substitute(item)
+		}
+		return list
+	case map[string]interface{}:
+		object := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			object[key] = e.substitute(item)
+		}
+		return object
+	}
+	return literal
+}
+
+// coerceVariables checks the request variables against the operation's definitions
+func coerceVariables(op *Operation, values map[string]interface{}) (map[string]interface{}, []*Error) {
+	variables := make(map[string]interface{})
+	var errs []*Error
+	for _, def := range op.Variables {
+		value, given := values[def.Name]
+		if !given {
+			value = def.Default
+		}
+		if value == nil {
+			if strings.HasSuffix(def.Type, "!") {
+				errs = append(errs, &Error{Message: fmt.Sprintf("variable $%s of type %s is required", def.Name, def.Type)})
+			}
+			continue
+		}
+
+		coerced, err := coerce(def.Type, value)
+		if err != nil {
+			errs = append(errs, &Error{Message: fmt.Sprintf("variable $%s: %v", def.Name, err)})
+			continue
+		}
+		variables[def.Name] = coerced
+	}
+	return variables, errs
+}
+
+// coerce converts an input value to the given type. Numbers from JSON variables arrive as
+// float64 and are accepted for Int when integral. Unknown named types pass through unchanged.
+func coerce(typeRef string, value interface{}) (interface{}, error) {
+	typeRef = strings.TrimSuffix(typeRef, "!")
+	if value == nil {
+		return nil, nil
+	}
+
+	if strings.HasPrefix(typeRef, "[") {
+		inner := typeRef[1 : len(typeRef)-1]
+		items, ok := value.([]interface{})
+		if !ok {
+			items = []interface{}{value}
+		}
+		list := make([]interface{}, len(items))
+		for i, item := range items {
+			if item == nil && strings.HasSuffix(inner, "!") {
+				return nil, fmt.Errorf("list item %d cannot be null", i)
+			}
+			coerced, err := coerce(inner, item)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = coerced
+		}
+		return list, nil
+	}
+
+	switch typeRef {
+	case "Int":
+		switch n := value.(type) {
+		case int64:
+			return n, nil
+		case float64:
+			if n == float64(int64(n)) {
+				return int64(n), nil
+			}
+		}
+		return nil, fmt.Errorf("expected an Int, got %v", value)
+	case "Float":
+		switch n := value.(type) {
+		case int64:
+			return float64(n), nil
+		case float64:
+			return n, nil
+		}
+		return nil, fmt.Errorf("expected a Float, got %v", value)
+	case "String":
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+		return nil, fmt.Errorf("expected a String, got %v", value)
+	case "ID":
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case int64:
+			return strconv.FormatInt(v, 10), nil
+		}
+		return nil, fmt.Errorf("expected an ID, got %v", value)
+	case "Boolean":
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+		return nil, fmt.Errorf("expected a Boolean, got %v", value)
+	}
+	return value, nil
+}
+
+// toError converts a parse error into a GraphQL error
+func toError(err error) *Error {
+	if gqlErr, ok := err.(*Error); ok {
+		return gqlErr
+	}
+	return &Error{Message: err.Error()}
+}
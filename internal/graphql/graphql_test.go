@@ -0,0 +1,197 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+type book struct {
+	Title  string
+	Author string
+}
+
+func testSchema() *Schema {
+	bookType := &Object{Name: "Book", Fields: map[string]*FieldDef{
+		"title":  {Resolve: func(p ResolveParams) (interface{}, error) { return p.Source.(book).Title, nil }},
+		"author": {Resolve: func(p ResolveParams) (interface{}, error) { return p.Source.(book).Author, nil }},
+	}}
+	books := []book{{"Dune", "Herbert"}, {"Emma", "Austen"}}
+
+	return &Schema{
+		Query: &Object{Name: "Query", Fields: map[string]*FieldDef{
+			"books": {
+				Type: bookType,
+				Args: map[string]string{"first": "Int"},
+				Resolve: func(p ResolveParams) (interface{}, error) {
+					if first, ok := p.Args["first"].(int64); ok && int(first) < len(books) {
+						return books[:first], nil
+					}
+					return books, nil
+				},
+			},
+			"book": {
+				Type: bookType,
+				Args: map[string]string{"title": "String!"},
+				Resolve: func(p ResolveParams) (interface{}, error) {
+					for _, b := range books {
+						if b.Title == p.Args["title"] {
+							return b, nil
+						}
+					}
+					return nil, nil
+				},
+			},
+			"fail": {Resolve: func(p ResolveParams) (interface{}, error) { return nil, &Error{Message: "boom"} }},
+		}},
+		Subscription: &Object{Name: "Subscription", Fields: map[string]*FieldDef{
+			"published": {
+				Type: bookType,
+				Subscribe: func(p ResolveParams) (<-chan interface{}, error) {
+					ch := make(chan interface{}, len(books))
+					for _, b := range books {
+						ch <- b
+					}
+					close(ch)
+					return ch, nil
+				},
+			},
+		}},
+	}
+}
+
+func encode(t *testing.T, result *Result) string {
+	t.Helper()
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("failed to encode result: %v", err)
+	}
+	return string(data)
+}
+
+func TestExecute(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		variables map[string]interface{}
+		expected  string
+	}{
+		{"shorthand", `{ books { title } }`,
+			nil, `{"data":{"books":[{"title":"Dune"},{"title":"Emma"}]}}`},
+		{"aliases and arguments", `query { first: books(first: 1) { author, title } emma: book(title: "Emma") { author } }`,
+			nil, `{"data":{"first":[{"author":"Herbert","title":"Dune"}],"emma":{"author":"Austen"}}}`},
+		{"variables", `query Find($title: String!) { book(title: $title) { title } }`,
+			map[string]interface{}{"title": "Dune"}, `{"data":{"book":{"title":"Dune"}}}`},
+		{"variable defaults and directives", `query ($first: Int = 1, $withAuthor: Boolean!) { books(first: $first) { title author @include(if: $withAuthor) } }`,
+			map[string]interface{}{"withAuthor": false}, `{"data":{"books":[{"title":"Dune"}]}}`},
+		{"fragments", `{ books(first: 1) { ...parts ... on Book { __typename } } } fragment parts on Book { title }`,
+			nil, `{"data":{"books":[{"title":"Dune","__typename":"Book"}]}}`},
+		{"null object", `{ book(title: "Ulysses") { title } }`,
+			nil, `{"data":{"book":null}}`},
+		{"resolver error", `{ fail books(first: 1) { title } }`,
+			nil, `{"data":{"fail":null,"books":[{"title":"Dune"}]},"errors":[{"message":"boom","locations":[{"line":1,"column":3}],"path":["fail"]}]}`},
+		{"unknown field", `{ books { isbn } }`,
+			nil, `{"errors":[{"message":"cannot query field \"isbn\" on type Book","locations":[{"line":1,"column":11}]}]}`},
+		{"missing selection", `{ books }`,
+			nil, `{"errors":[{"message":"field Query.books of type Book must have a selection set","locations":[{"line":1,"column":3}]}]}`},
+		{"missing variable", `query ($title: String!) { book(title: $title) { title } }`,
+			nil, `{"errors":[{"message":"variable $title of type String! is required"}]}`},
+		{"wrong variable type", `query ($first: Int) { books(first: $first) { title } }`,
+			map[string]interface{}{"first": "one"}, `{"errors":[{"message":"variable $first: expected an Int, got one"}]}`},
+		{"syntax error", `{ books { title }`,
+			nil, `{"errors":[{"message":"unexpected end of document","locations":[{"line":1,"column":18}]}]}`},
+		{"unsupported mutation", `mutation { books { title } }`,
+			nil, `{"errors":[{"message":"schema does not support mutation operations","locations":[{"line":1,"column":1}]}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Execute(context.Background(), testSchema(), Request{Query: tt.query, Variables: tt.variables})
+			if got := encode(t, result); got != tt.expected {
+				t.Errorf("expected %s, got: %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	results, failure := Subscribe(context.Background(), testSchema(), Request{Query: `subscription { published { title } }`})
+	if failure != nil {
+		t.Fatalf("expected subscription to start, got: %s", encode(t, failure))
+	}
+
+	var got []string
+	timeout := time.After(2 * time.Second)
+	for len(got) < 2 {
+		select {
+		case result, ok := <-results:
+			if !ok {
+				t.Fatalf("results closed after %v", got)
+			}
+			got = append(got, encode(t, result))
+		case <-timeout:
+			t.Fatal("timed out waiting for subscription results")
+		}
+	}
+	if got[0] != `{"data":{"published":{"title":"Dune"}}}` || got[1] != `{"data":{"published":{"title":"Emma"}}}` {
+		t.Errorf("unexpected results: %v", got)
+	}
+
+	_, failure = Subscribe(context.Background(), testSchema(), Request{Query: `{ books { title } }`})
+	if failure == nil {
+		t.Errorf("expected a query to be rejected as a subscription")
+	}
+}
+
+func TestLimits(t *testing.T) {
+	node := &Object{Name: "Node"}
+	node.Fields = map[string]*FieldDef{
+		"id":       {Resolve: func(p ResolveParams) (interface{}, error) { return 1, nil }},
+		"next":     {Type: node, Resolve: func(p ResolveParams) (interface{}, error) { return struct{}{}, nil }},
+		"children": {Type: node, Cost: 10, Resolve: func(p ResolveParams) (interface{}, error) { return []struct{}{{}}, nil }},
+	}
+	schema := &Schema{Query: &Object{Name: "Query", Fields: map[string]*FieldDef{
+		"node": {Type: node, Resolve: func(p ResolveParams) (interface{}, error) { return struct{}{}, nil }},
+	}}, MaxDepth: 4, MaxComplexity: 100}
+
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{"within limits", `{ node { next { children { id } } } }`,
+			`{"data":{"node":{"next":{"children":[{"id":1}]}}}}`},
+		{"too deep", `{ node { next { next { next { id } } } } }`,
+			`{"errors":[{"message":"operation exceeds the maximum depth of 4","locations":[{"line":1,"column":31}]}]}`},
+		{"too complex", `{ node { children { children { id id } } } }`,
+			`{"errors":[{"message":"operation exceeds the maximum complexity of 100","locations":[{"line":1,"column":1}]}]}`},
+		{"fragments spread many times", `{ node { ...a ...a } } fragment a on Node { ...b ...b ...b } fragment b on Node { ...c ...c ...c } fragment c on Node { id id id id id id }`,
+			`{"errors":[{"message":"operation exceeds the maximum complexity of 100","locations":[{"line":1,"column":1}]}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := encode(t, Execute(context.Background(), schema, Request{Query: tt.query})); got != tt.expected {
+				t.Errorf("expected %s, got: %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestOperationType(t *testing.T) {
+	tests := map[string]string{
+		`{ books { title } }`:                          "query",
+		`mutation Add { add }`:                         "mutation",
+		`subscription { published { title } }`:         "subscription",
+		`query A { books { title } } mutation B { b }`: "",
+		`{ books`: "",
+	}
+	for query, expected := range tests {
+		if got := OperationType(Request{Query: query}); got != expected {
+			t.Errorf("%s: expected %q, got: %q", query, expected, got)
+		}
+	}
+	if got := OperationType(Request{Query: `query A { a } mutation B { b }`, OperationName: "B"}); got != "mutation" {
+		t.Errorf("expected the named operation's type, got: %q", got)
+	}
+}
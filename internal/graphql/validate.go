@@ -0,0 +1,224 @@
+package graphql
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// validate checks the selected operation against the schema before it runs
+func (p *prepared) validate() []*Error {
+	if err := p.checkLimits(); err != nil {
+		return []*Error{err}
+	}
+
+	v := &validator{prepared: p, defined: make(map[string]bool), visiting: make(map[string]bool)}
+	for _, def := range p.op.Variables {
+		v.defined[def.Name] = true
+	}
+
+	v.selectionSet(p.root, p.op.SelectionSet)
+
+	if p.op.Type == "subscription" && len(v.errors) == 0 {
+		e := &executor{prepared: p}
+		if fields := e.collectFields(p.root, p.op.SelectionSet, nil); len(fields.keys) != 1 {
+			v.fail(p.op.Loc, "subscriptions must select exactly one field")
+		}
+	}
+	return v.errors
+}
+
+// checkLimits checks the operation against the schema's MaxDepth and MaxComplexity. It runs
+// before the other checks and stops as soon as a limit is exceeded, so that oversized documents,
+// such as fragments spread many times over, are turned away without being walked in full.
+func (p *prepared) checkLimits() *Error {
+	if p.schema.MaxDepth <= 0 && p.schema.MaxComplexity <= 0 {
+		return nil
+	}
+	c := &coster{prepared: p, visiting: make(map[string]bool)}
+	c.selectionSet(p.root, p.op.SelectionSet, 1)
+	return c.err
+}
+
+// coster adds up the cost of an operation. Unknown fields and fragments are skipped; the
+// validator reports them.
+type coster struct {
+	*prepared
+	visiting map[string]bool // Fragments being expanded, to skip cycles
+	err      *Error
+}
+
+// selectionSet returns the cost of selections on object whose fields are at the given depth
+func (c *coster) selectionSet(object *Object, selections []Selection, depth int) int {
+	total := 0
+	for _, selection := range selections {
+		if c.err != nil {
+			return total
+		}
+		switch s := selection.(type) {
+		case *Field:
+			total += c.field(object, s, depth)
+		case *FragmentSpread:
+			fragment, ok := c.doc.Fragments[s.Name]
+			if !ok || c.visiting[s.Name] {
+				continue
+			}
+			c.visiting[s.Name] = true
+			total += c.selectionSet(object, fragment.SelectionSet, depth)
+			delete(c.visiting, s.Name)
+		case *InlineFragment:
+			total += c.selectionSet(object, s.SelectionSet, depth)
+		}
+		if limit := c.schema.MaxComplexity; limit > 0 && total > limit && c.err == nil {
+			c.err = &Error{Message: fmt.Sprintf("operation exceeds the maximum complexity of %d", limit), Locations: []Location{c.op.Loc}}
+		}
+	}
+	return total
+}
+
+func (c *coster) field(object *Object, field *Field, depth int) int {
+	if limit := c.schema.MaxDepth; limit > 0 && depth > limit {
+		c.err = &Error{Message: fmt.Sprintf("operation exceeds the maximum depth of %d", limit), Locations: []Location{field.Loc}}
+		return 1
+	}
+	def, ok := object.Fields[field.Name]
+	if !ok || def.Type == nil {
+		return 1
+	}
+	return 1 + max(def.Cost, 1)*c.selectionSet(def.Type, field.SelectionSet, depth+1)
+}
+
+// validator walks an operation and records every problem it finds
+type validator struct {
+	*prepared
+	defined  map[string]bool // Variables declared by the operation
+	visiting map[string]bool // Fragments being expanded, to detect cycles
+	errors   []*Error
+}
+
+func (v *validator) fail(loc Location, format string, args ...interface{}) {
+	v.errors = append(v.errors, &Error{Message: fmt.Sprintf(format, args...), Locations: []Location{loc}})
+}
+
+func (v *validator) selectionSet(object *Object, selections []Selection) {
+	for _, selection := range selections {
+		switch s := selection.(type) {
+		case *Field:
+			v.field(object, s)
+		case *FragmentSpread:
+			v.directives(s.Directives, s.Loc)
+			fragment, ok := v.doc.Fragments[s.Name]
+			if !ok {
+				v.fail(s.Loc, "unknown fragment %q", s.Name)
+				continue
+			}
+			if fragment.TypeCondition != object.Name {
+				v.fail(s.Loc, "fragment %q on %s cannot be spread on %s", s.Name, fragment.TypeCondition, object.Name)
+				continue
+			}
+			if v.visiting[s.Name] {
+				v.fail(s.Loc, "fragment %q spreads itself", s.Name)
+				continue
+			}
+			v.visiting[s.Name] = true
+			v.selectionSet(object, fragment.SelectionSet)
+			delete(v.visiting, s.Name)
+		case *InlineFragment:
+			v.directives(s.Directives, Location{})
+			if s.TypeCondition != "" && s.TypeCondition != object.Name {
+				v.fail(Location{}, "inline fragment on %s cannot be used on %s", s.TypeCondition, object.Name)
+				continue
+			}
+			v.selectionSet(object, s.SelectionSet)
+		}
+	}
+}
+
+func (v *validator) field(object *Object, field *Field) {
+	v.directives(field.Directives, field.Loc)
+	if field.Name == "__typename" {
+		if len(field.SelectionSet) > 0 {
+			v.fail(field.Loc, "field __typename cannot have a selection set")
+		}
+		return
+	}
+
+	def, ok := object.Fields[field.Name]
+	if !ok {
+		v.fail(field.Loc, "cannot query field %q on type %s", field.Name, object.Name)
+		return
+	}
+
+	for name, value := range field.Arguments {
+		if _, known := def.Args[name]; !known {
+			v.fail(field.Loc, "unknown argument %q on field %s.%s", name, object.Name, field.Name)
+		}
+		v.value(value, field.Loc)
+	}
+
+	switch {
+	case def.Type == nil && len(field.SelectionSet) > 0:
+		v.fail(field.Loc, "field %s.%s is a scalar and cannot have a selection set", object.Name, field.Name)
+	case def.Type != nil && len(field.SelectionSet) == 0:
+		v.fail(field.Loc, "field %s.%s of type %s must have a selection set", object.Name, field.Name, def.Type.Name)
+	case def.Type != nil:
+		v.selectionSet(def.Type, field.SelectionSet)
+	}
+}
+
+func (v *validator) directives(directives []Directive, loc Location) {
+	for _, directive := range directives {
+		if directive.Name != "skip" && directive.Name != "include" {
+			v.fail(loc, "unknown directive @%s", directive.Name)
+			continue
+		}
+		if _, ok := directive.Arguments["if"]; !ok {
+			v.fail(loc, "directive @%s requires an if argument", directive.Name)
+		}
+		for _, value := range directive.Arguments {
+			v.value(value, loc)
+		}
+	}
+}
+
+// value checks that every variable a literal references is declared
+func (v *validator) value(literal interface{}, loc Location) {
+	switch value := literal.(type) {
+	case Variable:
+		if !v.defined[string(value)] {
+			v.fail(loc, "variable $%s is not defined", string(value))
+		}
+	case []interface{}:
+		for _, item := range value {
+			v.value(item, loc)
+		}
+	case map[string]interface{}:
+		for _, item := range value {
+			v.value(item, loc)
+		}
+	}
+}
+
+// isNil reports whether a resolved value is nil, including typed nil pointers, maps and slices
+func isNil(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	switch rv := reflect.ValueOf(value); rv.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// asSlice returns the elements of a slice or array value
+func asSlice(value interface{}) ([]interface{}, bool) {
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, false
+	}
+	items := make([]interface{}, rv.Len())
+	for i := range items {
+		items[i] = rv.Index(i).Interface()
+	}
+	return items, true
+}
@@ -0,0 +1,257 @@
+// Package grpcapi serves the parser over gRPC, as defined in proto/parser/v1/parser.proto.
+package grpcapi
+
+//go:generate protoc -I ../../proto --go_out=. --go_opt=module=ethereum-tx-parser/internal/grpcapi --go-grpc_out=. --go-grpc_opt=module=ethereum-tx-parser/internal/grpcapi parser/v1/parser.proto
+
+import (
+	"context"
+	"errors"
+	"ethereum-tx-parser/internal/grpcapi/parserv1"
+	"ethereum-tx-parser/internal/logging"
+	"ethereum-tx-parser/internal/model"
+	"ethereum-tx-parser/internal/service"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var grpcLog = logging.For("grpc")
+
+// Server implements parserv1.ParserServiceServer over the service layer
+type Server struct {
+	parserv1.UnimplementedParserServiceServer
+}
+
+// NewServer returns a gRPC server with the parser service registered. Calls are authenticated
+// with an API key in the authorization ("Bearer <key>") or x-api-key metadata, like HTTP requests.
+func NewServer(opts ...grpc.ServerOption) *grpc.Server {
+	opts = append(opts, grpc.ChainUnaryInterceptor(authenticateUnary), grpc.ChainStreamInterceptor(authenticateStream))
+	server := grpc.NewServer(opts...)
+	parserv1.RegisterParserServiceServer(server, &Server{})
+	return server
+}
+
+// shutdownTimeout bounds how long a graceful stop waits for running calls, such as watch streams,
+// before cancelling them
+const shutdownTimeout = 10 * time.Second
+
+// StartServer serves the parser service on addr until the listener fails or ctx is done, then
+// stops gracefully. Options such as transport credentials are passed on to NewServer.
+func StartServer(ctx context.Context, addr string, opts ...grpc.ServerOption) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	server := NewServer(opts...)
+	grpcLog.Info("server started", "addr", addr)
+	errs := make(chan error, 1)
+	go func() { errs <- server.Serve(listener) }()
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(shutdownTimeout):
+		server.Stop()
+	}
+	return nil
+}
+
+// GetCurrentBlock returns the last parsed block number
+func (s *Server) GetCurrentBlock(ctx context.Context, req *parserv1.GetCurrentBlockRequest) (*parserv1.GetCurrentBlockResponse, error) {
+	number, err := service.ParserFor(ctx).GetCurrentBlock()
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to read current block")
+	}
+	return &parserv1.GetCurrentBlockResponse{Number: int64(number), Hex: fmt.Sprintf("0x%x", number)}, nil
+}
+
+// Subscribe adds an address to be observed for transactions
+func (s *Server) Subscribe(ctx context.Context, req *parserv1.SubscribeRequest) (*parserv1.SubscribeResponse, error) {
+	subscribed, err := service.ParserFor(ctx).Subscribe(req.GetAddress())
+	if err != nil {
+		return nil, statusFor(err)
+	}
+	return &parserv1.SubscribeResponse{Subscribed: subscribed}, nil
+}
+
+// GetTransactions returns the stored transactions of an address
+func (s *Server) GetTransactions(ctx context.Context, req *parserv1.GetTransactionsRequest) (*parserv1.GetTransactionsResponse, error) {
+	transactions, err := service.ParserFor(ctx).GetTransactions(req.GetAddress())
+	if err != nil {
+		return nil, statusFor(err)
+	}
+
+	resp := &parserv1.GetTransactionsResponse{}
+	for _, tx := range transactions {
+		resp.Transactions = append(resp.Transactions, toProto(tx))
+	}
+	return resp, nil
+}
+
+// WatchTransactions streams transaction events for the requested addresses. Events retained
+// since after_seq are replayed first, so a client can resume where its last stream broke off.
+func (s *Server) WatchTransactions(req *parserv1.WatchTransactionsRequest, stream parserv1.ParserService_WatchTransactionsServer) error {
+	p := service.ParserFor(stream.Context())
+	addresses := make(map[string]bool)
+	for _, address := range req.GetAddresses() {
+		if err := service.ValidateAddress(address); err != nil {
+			return statusFor(err)
+		}
+		if !p.CanRead(address) {
+			return statusFor(service.ErrNotSubscribed)
+		}
+		addresses[strings.ToLower(address)] = true
+	}
+	if len(addresses) == 0 {
+		return status.Error(codes.InvalidArgument, "at least one address is required")
+	}
+
+	// Subscribe before replaying so nothing published in between is lost
+	lastSeq := req.GetAfterSeq()
+	if lastSeq == 0 {
+		lastSeq = service.LastEventSeq()
+	}
+	events, unsubscribe := service.SubscribeEvents(256)
+	defer unsubscribe()
+
+	send := func(event model.Event) error {
+		if event.Seq <= lastSeq {
+			return nil
+		}
+		lastSeq = event.Seq
+		if event.Type != model.EventTransaction || !addresses[event.Address] || !p.CanRead(event.Address) {
+			return nil
+		}
+		return stream.Send(&parserv1.TransactionEvent{
+			Seq:         event.Seq,
+			Address:     event.Address,
+			Transaction: toProto(event.Data.(model.Transaction)),
+		})
+	}
+
+	for _, event := range service.EventsSince(lastSeq) {
+		if err := send(event); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				return status.Errorf(codes.ResourceExhausted, "stream fell behind; resume with after_seq %d", lastSeq)
+			}
+			if err := send(event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// statusFor maps service errors to gRPC status codes
+func statusFor(err error) error {
+	if errors.Is(err, service.ErrInvalidAddress) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if errors.Is(err, service.ErrNotSubscribed) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	if errors.Is(err, service.ErrQuotaExceeded) {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// authenticate resolves the API key in the metadata of a call to its tenant, under the same
+// rules as HTTP requests
+func authenticate(ctx context.Context) (context.Context, error) {
+	key := ""
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get("authorization"); len(values) > 0 && len(values[0]) > 7 && strings.EqualFold(values[0][:7], "Bearer ") {
+		key = strings.TrimSpace(values[0][7:])
+	} else if values := md.Get("x-api-key"); len(values) > 0 {
+		key = values[0]
+	}
+
+	if key == "" {
+		if service.RequireAPIKey {
+			return nil, status.Error(codes.Unauthenticated, "an API key is required")
+		}
+		return ctx, nil
+	}
+	record, ok := service.AuthenticateAPIKey(key)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "invalid or revoked API key")
+	}
+	return service.WithTenant(ctx, record.Tenant), nil
+}
+
+func authenticateUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func authenticateStream(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := authenticate(stream.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, &tenantStream{ServerStream: stream, ctx: ctx})
+}
+
+// tenantStream overrides the context of a server stream with the authenticated one
+type tenantStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *tenantStream) Context() context.Context {
+	return s.ctx
+}
+
+// toProto converts a stored transaction to its protobuf form
+func toProto(tx model.Transaction) *parserv1.Transaction {
+	return &parserv1.Transaction{
+		Hash:                 tx.Hash,
+		From:                 tx.From,
+		To:                   tx.To,
+		Value:                tx.Value,
+		Nonce:                tx.Nonce,
+		Input:                tx.Input,
+		BlockNumber:          tx.BlockNumber,
+		TransactionIndex:     tx.TransactionIndex,
+		Timestamp:            tx.Timestamp,
+		Type:                 tx.Type,
+		Gas:                  tx.Gas,
+		GasPrice:             tx.GasPrice,
+		MaxFeePerGas:         tx.MaxFeePerGas,
+		MaxPriorityFeePerGas: tx.MaxPriorityFeePerGas,
+		Status:               tx.Status,
+		ContractAddress:      tx.ContractAddress,
+		Replaces:             tx.Replaces,
+		ReplacedBy:           tx.ReplacedBy,
+		ReplacementType:      tx.ReplacementType,
+		SeenAt:               tx.SeenAt,
+		VerificationError:    tx.VerificationError,
+	}
+}
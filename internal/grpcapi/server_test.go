@@ -0,0 +1,156 @@
+package grpcapi
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"ethereum-tx-parser/internal/grpcapi/parserv1"
+	"ethereum-tx-parser/internal/model"
+	"ethereum-tx-parser/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const testAddress = "0x1234567890abcdef1234567890abcdef12345678"
+
+// newTestClient serves the parser service on an in-process listener and returns a client for it
+func newTestClient(t *testing.T) parserv1.ParserServiceClient {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	server := NewServer()
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return parserv1.NewParserServiceClient(conn)
+}
+
+func TestParserService(t *testing.T) {
+	service.InitializeModelLayer()
+	service.SaveLatestBlock("0x10")
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	block, err := client.GetCurrentBlock(ctx, &parserv1.GetCurrentBlockRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(16), block.GetNumber())
+	assert.Equal(t, "0x10", block.GetHex())
+
+	subscribed, err := client.Subscribe(ctx, &parserv1.SubscribeRequest{Address: testAddress})
+	assert.NoError(t, err)
+	assert.True(t, subscribed.GetSubscribed())
+
+	_, err = client.Subscribe(ctx, &parserv1.SubscribeRequest{Address: "0x12"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	service.FilterTransactionsByAddress([]model.Transaction{{Hash: "0xabc", From: testAddress, BlockNumber: "0x10", To: "0x0000000000000000000000000000000000000002"}})
+	transactions, err := client.GetTransactions(ctx, &parserv1.GetTransactionsRequest{Address: testAddress})
+	assert.NoError(t, err)
+	assert.Len(t, transactions.GetTransactions(), 1)
+	assert.Equal(t, "0xabc", transactions.GetTransactions()[0].GetHash())
+	assert.Equal(t, model.TxStatusMined, transactions.GetTransactions()[0].GetStatus())
+}
+
+func TestWatchTransactions(t *testing.T) {
+	service.InitializeModelLayer()
+	service.Subscribe(testAddress)
+	client := newTestClient(t)
+
+	// Events published before the call are replayed from after_seq
+	start := service.LastEventSeq()
+	service.FilterTransactionsByAddress([]model.Transaction{{Hash: "0x01", From: testAddress}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := client.WatchTransactions(ctx, &parserv1.WatchTransactionsRequest{Addresses: []string{testAddress}, AfterSeq: start})
+	assert.NoError(t, err)
+
+	event, err := stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, "0x01", event.GetTransaction().GetHash())
+	assert.Equal(t, testAddress, event.GetAddress())
+
+	service.FilterTransactionsByAddress([]model.Transaction{
+		{Hash: "0x02", From: "0x0000000000000000000000000000000000000001"},
+		{Hash: "0x03", To: testAddress, From: "0x0000000000000000000000000000000000000001"},
+	})
+	event, err = stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, "0x03", event.GetTransaction().GetHash())
+}
+
+func TestWatchTransactionsRequiresAddress(t *testing.T) {
+	client := newTestClient(t)
+	stream, err := client.WatchTransactions(context.Background(), &parserv1.WatchTransactionsRequest{})
+	assert.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestAuthentication(t *testing.T) {
+	service.InitializeModelLayer()
+	client := newTestClient(t)
+	_, key, err := service.IssueAPIKey("grpc-test", "")
+	assert.NoError(t, err)
+
+	invalid := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "txp_unknown")
+	_, err = client.GetCurrentBlock(invalid, &parserv1.GetCurrentBlockRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	service.RequireAPIKey = true
+	t.Cleanup(func() { service.RequireAPIKey = false })
+	_, err = client.GetCurrentBlock(context.Background(), &parserv1.GetCurrentBlockRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+key)
+	_, err = client.Subscribe(ctx, &parserv1.SubscribeRequest{Address: testAddress})
+	assert.NoError(t, err)
+	_, err = client.GetTransactions(ctx, &parserv1.GetTransactionsRequest{Address: testAddress})
+	assert.NoError(t, err)
+
+	// Another tenant does not see the subscription
+	_, other, _ := service.IssueAPIKey("grpc-other", "")
+	otherCtx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", other)
+	_, err = client.GetTransactions(otherCtx, &parserv1.GetTransactionsRequest{Address: testAddress})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestStartServerStops(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	addr := listener.Addr().String()
+	listener.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error, 1)
+	go func() { stopped <- StartServer(ctx, addr) }()
+	assert.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			return false
+		}
+		conn.Close()
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	select {
+	case err := <-stopped:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not stop")
+	}
+}
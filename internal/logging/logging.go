@@ -0,0 +1,196 @@
+// Package logging configures the process-wide log/slog output: the format, a global level that
+// can be changed at runtime, per-component level overrides and request IDs carried in contexts
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Output formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+var (
+	level = new(slog.LevelVar) // Global level, info by default
+
+	componentMu     sync.RWMutex
+	componentLevels = make(map[string]slog.Level) // Overrides of the global level by component
+
+	output atomic.Pointer[slog.Handler] // Formats records; filtering happens in handler
+)
+
+func init() {
+	setOutput(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	slog.SetDefault(slog.New(&handler{}))
+}
+
+// Setup selects the format ("text" or "json") and level of all logs, written to w
+func Setup(w io.Writer, format string, levelName string) error {
+	parsed, err := ParseLevel(levelName)
+	if err != nil {
+		return err
+	}
+	options := &slog.HandlerOptions{Level: slog.LevelDebug}
+	switch format {
+	case FormatText:
+		setOutput(slog.NewTextHandler(w, options))
+	case FormatJSON:
+		setOutput(slog.NewJSONHandler(w, options))
+	default:
+		return fmt.Errorf("unknown log format %q, expected text or json", format)
+	}
+	level.Set(parsed)
+	return nil
+}
+
+func setOutput(h slog.Handler) {
+	output.Store(&h)
+}
+
+// For returns the logger of a component. Its records carry a component attribute, and are
+// filtered by the component's level if one is set, or by the global level otherwise.
+func For(component string) *slog.Logger {
+	return slog.New(&handler{component: component}).With("component", component)
+}
+
+// ParseLevel parses debug, info, warn or error
+func ParseLevel(name string) (slog.Level, error) {
+	var parsed slog.Level
+	if err := parsed.UnmarshalText([]byte(name)); err != nil {
+		return 0, fmt.Errorf("unknown log level %q, expected debug, info, warn or error", name)
+	}
+	return parsed, nil
+}
+
+// Level returns the global level
+func Level() slog.Level {
+	return level.Level()
+}
+
+// SetLevel changes the global level
+func SetLevel(l slog.Level) {
+	level.Set(l)
+}
+
+// SetComponentLevel overrides the global level for one component
+func SetComponentLevel(component string, l slog.Level) error {
+	if component == "" {
+		return errors.New("component must not be empty")
+	}
+	componentMu.Lock()
+	defer componentMu.Unlock()
+	componentLevels[component] = l
+	return nil
+}
+
+// ResetComponentLevel makes a component follow the global level again
+func ResetComponentLevel(component string) {
+	componentMu.Lock()
+	defer componentMu.Unlock()
+	delete(componentLevels, component)
+}
+
+// ComponentLevels returns the component level overrides by component name
+func ComponentLevels() map[string]slog.Level {
+	componentMu.RLock()
+	defer componentMu.RUnlock()
+	levels := make(map[string]slog.Level, len(componentLevels))
+	for component, l := range componentLevels {
+		levels[component] = l
+	}
+	return levels
+}
+
+// Components returns the names of components with a level override, sorted
+func Components() []string {
+	levels := ComponentLevels()
+	names := make([]string, 0, len(levels))
+	for name := range levels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// minLevel returns the level records of a component must reach to be logged
+func minLevel(component string) slog.Level {
+	if component != "" {
+		componentMu.RLock()
+		l, ok := componentLevels[component]
+		componentMu.RUnlock()
+		if ok {
+			return l
+		}
+	}
+	return level.Level()
+}
+
+type requestIDKey struct{}
+
+// WithRequestID returns a context whose log records carry a request ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID of a context, if it has one
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// handler filters records by level and passes them to the current output, adding the request ID
+// and trace context of the record's context. It looks the output up for each record, so loggers
+// created before Setup still use the configured format.
+type handler struct {
+	component string
+	ops       []func(slog.Handler) slog.Handler // WithAttrs and WithGroup calls, in order
+}
+
+func (h *handler) Enabled(_ context.Context, l slog.Level) bool {
+	return l >= minLevel(h.component)
+}
+
+func (h *handler) Handle(ctx context.Context, record slog.Record) error {
+	out := *output.Load()
+	for _, op := range h.ops {
+		out = op(out)
+	}
+	if id := RequestID(ctx); id != "" {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		record.AddAttrs(slog.String("trace_id", span.TraceID().String()), slog.String("span_id", span.SpanID().String()))
+	}
+	return out.Handle(ctx, record)
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.with(func(out slog.Handler) slog.Handler { return out.WithAttrs(attrs) })
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	return h.with(func(out slog.Handler) slog.Handler { return out.WithGroup(name) })
+}
+
+func (h *handler) with(op func(slog.Handler) slog.Handler) *handler {
+	ops := append(append([]func(slog.Handler) slog.Handler(nil), h.ops...), op)
+	return &handler{component: h.component, ops: ops}
+}
+
+// LevelName returns the lower-case name of a level, as accepted by ParseLevel
+func LevelName(l slog.Level) string {
+	return strings.ToLower(l.String())
+}
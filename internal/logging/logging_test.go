@@ -0,0 +1,106 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// captureLogs sends logs to a buffer in the given format for the duration of a test
+func captureLogs(t *testing.T, format string, levelName string) *bytes.Buffer {
+	var buf bytes.Buffer
+	assert.NoError(t, Setup(&buf, format, levelName))
+	t.Cleanup(func() {
+		Setup(os.Stderr, FormatText, "info")
+		for _, component := range Components() {
+			ResetComponentLevel(component)
+		}
+	})
+	return &buf
+}
+
+func TestSetupRejectsUnknownValues(t *testing.T) {
+	captureLogs(t, FormatText, "info")
+	assert.Error(t, Setup(os.Stderr, "xml", "info"))
+	assert.Error(t, Setup(os.Stderr, FormatText, "loud"))
+}
+
+func TestJSONFormat(t *testing.T) {
+	buf := captureLogs(t, FormatJSON, "info")
+	For("processor").Info("processed block", "block", "0x10")
+
+	var record map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "processed block", record["msg"])
+	assert.Equal(t, "INFO", record["level"])
+	assert.Equal(t, "processor", record["component"])
+	assert.Equal(t, "0x10", record["block"])
+}
+
+func TestLevels(t *testing.T) {
+	buf := captureLogs(t, FormatText, "warn")
+	logger := For("rpc")
+	logger.Info("hidden")
+	logger.Warn("shown")
+	assert.NotContains(t, buf.String(), "hidden")
+	assert.Contains(t, buf.String(), "shown")
+
+	// Loggers made before a level change follow it
+	SetLevel(slog.LevelDebug)
+	logger.Debug("now shown")
+	assert.Contains(t, buf.String(), "now shown")
+	assert.Equal(t, "debug", LevelName(Level()))
+}
+
+func TestComponentLevel(t *testing.T) {
+	buf := captureLogs(t, FormatText, "info")
+	assert.Error(t, SetComponentLevel("", slog.LevelDebug))
+	assert.NoError(t, SetComponentLevel("rpc", slog.LevelDebug))
+	assert.NoError(t, SetComponentLevel("http", slog.LevelError))
+	assert.Equal(t, []string{"http", "rpc"}, Components())
+
+	For("rpc").Debug("rpc detail")
+	For("http").Info("http request")
+	For("processor").Debug("processor detail")
+	assert.Contains(t, buf.String(), "rpc detail")
+	assert.NotContains(t, buf.String(), "http request")
+	assert.NotContains(t, buf.String(), "processor detail")
+
+	ResetComponentLevel("http")
+	For("http").Info("http again")
+	assert.Contains(t, buf.String(), "http again")
+	assert.Equal(t, map[string]slog.Level{"rpc": slog.LevelDebug}, ComponentLevels())
+}
+
+func TestRequestID(t *testing.T) {
+	buf := captureLogs(t, FormatText, "info")
+	ctx := WithRequestID(context.Background(), "abc123")
+	assert.Equal(t, "abc123", RequestID(ctx))
+	assert.Empty(t, RequestID(context.Background()))
+
+	For("http").With("route", "/v1/alerts").InfoContext(ctx, "request")
+	line := buf.String()
+	assert.Contains(t, line, "request_id=abc123")
+	assert.Contains(t, line, "route=/v1/alerts")
+	assert.Equal(t, 1, strings.Count(line, "\n"))
+}
+
+func TestTraceContext(t *testing.T) {
+	buf := captureLogs(t, FormatJSON, "info")
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID}))
+	For("rpc").InfoContext(ctx, "call")
+
+	var record map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", record["trace_id"])
+	assert.Equal(t, "00f067aa0ba902b7", record["span_id"])
+}
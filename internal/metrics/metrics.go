@@ -0,0 +1,64 @@
+// Package metrics holds the Prometheus registry the server exposes at /metrics. Packages define
+// their metrics with github.com/prometheus/client_golang and register them here.
+package metrics
+
+import (
+	"ethereum-tx-parser/internal/logging"
+	"fmt"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var metricsLog = logging.For("metrics")
+
+// Registry is the registry Register adds to and Handler serves. Besides the server's own metrics
+// it exposes the Go runtime and process collectors.
+var Registry = newRegistry()
+
+func newRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	return registry
+}
+
+// Register adds collectors to Registry. Unlike MustRegister, it returns an error for a collector
+// that is invalid or clashes with one already registered, after registering the others.
+func Register(cs ...prometheus.Collector) error {
+	var failed error
+	for _, c := range cs {
+		if err := Registry.Register(c); err != nil && failed == nil {
+			failed = err
+		}
+	}
+	return failed
+}
+
+// Observe records value in the series of a histogram with the given label values. A label count
+// that does not match the histogram is logged rather than panicking on the request path.
+func Observe(vec *prometheus.HistogramVec, value float64, labelValues ...string) {
+	observer, err := vec.GetMetricWithLabelValues(labelValues...)
+	if err != nil {
+		metricsLog.Error("observing metric failed", "error", err)
+		return
+	}
+	observer.Observe(value)
+}
+
+// Handler serves Registry in the Prometheus exposition format. Failures to gather a metric are
+// logged and the remaining metrics are still served.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{
+		ErrorLog:      promhttpLogger{},
+		ErrorHandling: promhttp.ContinueOnError,
+	})
+}
+
+// promhttpLogger passes promhttp's errors on to the metrics log
+type promhttpLogger struct{}
+
+func (promhttpLogger) Println(v ...any) {
+	metricsLog.Error("serving metrics failed", "error", fmt.Sprint(v...))
+}
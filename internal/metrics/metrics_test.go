@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// useTestRegistry swaps Registry for an empty one for the duration of a test
+func useTestRegistry(t *testing.T) {
+	previous := Registry
+	Registry = prometheus.NewRegistry()
+	t.Cleanup(func() { Registry = previous })
+}
+
+func TestRegisterReturnsErrors(t *testing.T) {
+	useTestRegistry(t)
+	requests := prometheus.NewCounter(prometheus.CounterOpts{Name: "requests_total", Help: "Requests served."})
+	duplicate := prometheus.NewCounter(prometheus.CounterOpts{Name: "requests_total", Help: "Requests served."})
+	temperature := prometheus.NewGauge(prometheus.GaugeOpts{Name: "temperature", Help: "Current temperature."})
+
+	assert.NoError(t, Register(requests))
+	assert.Error(t, Register(duplicate, temperature), "A duplicate metric should be an error, not a panic")
+	assert.Equal(t, 2, testutil.CollectAndCount(Registry), "Collectors after a failing one should still be registered")
+}
+
+func TestObserve(t *testing.T) {
+	latency := prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "latency_seconds", Help: "Latency."}, []string{"method"})
+	Observe(latency, 0.05, "get")
+	Observe(latency, 3, "get")
+
+	assert.NotPanics(t, func() { Observe(latency, 1, "get", "extra") })
+	assert.NotPanics(t, func() { Observe(latency, 1) })
+	assert.Equal(t, 1, testutil.CollectAndCount(latency), "Mismatching label values should not create series")
+}
+
+func TestHandler(t *testing.T) {
+	useTestRegistry(t)
+	requests := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "requests_total", Help: "Requests\nserved."}, []string{"code"})
+	assert.NoError(t, Register(requests))
+	requests.WithLabelValues("200").Add(3)
+	requests.WithLabelValues(`5"0\0`).Inc()
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `# HELP requests_total Requests\nserved.
+# TYPE requests_total counter
+requests_total{code="200"} 3
+requests_total{code="5\"0\\0"} 1
+`)
+}
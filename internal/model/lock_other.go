@@ -0,0 +1,10 @@
+//go:build !unix
+
+package model
+
+import "os"
+
+// lockFile is a no-op where advisory file locks are unavailable
+func lockFile(f *os.File) error {
+	return nil
+}
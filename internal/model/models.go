@@ -1,7 +1,10 @@
 // internal/model/storage.go
 package model
 
-import "sync"
+import (
+	"sync"
+	"time"
+)
 
 // EthereumRPC holds the RPC URL for connecting to the Ethereum network
 type EthereumRPC struct {
@@ -15,33 +18,354 @@ type JsonRPCResponse struct {
 	Result  Block  `json:"result"`
 }
 
+// JSON-RPC response for getting a transaction receipt
+type JsonRPCReceiptResponse struct {
+	JsonRPC string  `json:"jsonrpc"`
+	ID      int     `json:"id"`
+	Result  Receipt `json:"result"`
+}
+
 // Transaction represents an Ethereum transaction
 type Transaction struct {
-	Hash  string `json:"hash"`
-	From  string `json:"from"`
-	To    string `json:"to"`
-	Value string `json:"value"`
+	Hash                 string          `json:"hash"`
+	From                 string          `json:"from"`
+	To                   string          `json:"to"`
+	Value                string          `json:"value"`
+	Nonce                string          `json:"nonce,omitempty"`
+	Input                string          `json:"input,omitempty"`
+	BlockNumber          string          `json:"blockNumber,omitempty"`
+	TransactionIndex     string          `json:"transactionIndex,omitempty"`
+	Timestamp            int64           `json:"timestamp,omitempty"` // Unix time of the including block
+	GasPrice             string          `json:"gasPrice,omitempty"`
+	MaxFeePerGas         string          `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas string          `json:"maxPriorityFeePerGas,omitempty"`
+	Type                 string          `json:"type,omitempty"`
+	ChainID              string          `json:"chainId,omitempty"`
+	Gas                  string          `json:"gas,omitempty"`
+	AccessList           []AccessTuple   `json:"accessList,omitempty"`
+	MaxFeePerBlobGas     string          `json:"maxFeePerBlobGas,omitempty"`
+	BlobVersionedHashes  []string        `json:"blobVersionedHashes,omitempty"`
+	AuthorizationList    []Authorization `json:"authorizationList,omitempty"`
+	V                    string          `json:"v,omitempty"`
+	R                    string          `json:"r,omitempty"`
+	S                    string          `json:"s,omitempty"`
+	YParity              string          `json:"yParity,omitempty"`
+	ContractAddress      string          `json:"contractAddress,omitempty"`   // Set for contract-creation transactions
+	Status               string          `json:"status,omitempty"`            // One of the TxStatus values
+	Replaces             string          `json:"replaces,omitempty"`          // Hash of the transaction this one replaced
+	ReplacedBy           string          `json:"replacedBy,omitempty"`        // Hash of the transaction that replaced this one
+	ReplacementType      string          `json:"replacementType,omitempty"`   // One of the Replacement values
+	SeenAt               int64           `json:"seenAt,omitempty"`            // Unix time the transaction was first seen pending
+	VerificationError    string          `json:"verificationError,omitempty"` // Why the hash or sender did not match, if verification is enabled
+}
+
+// Authorization is an entry of an EIP-7702 authorization list
+type Authorization struct {
+	ChainID string `json:"chainId"`
+	Address string `json:"address"`
+	Nonce   string `json:"nonce"`
+	YParity string `json:"yParity"`
+	R       string `json:"r"`
+	S       string `json:"s"`
+}
+
+// AccessTuple is an entry of an EIP-2930 access list
+type AccessTuple struct {
+	Address     string   `json:"address"`
+	StorageKeys []string `json:"storageKeys"`
+}
+
+// Transaction lifecycle states
+const (
+	TxStatusPending  = "pending"  // Seen in the mempool, not yet included in a block
+	TxStatusMined    = "mined"    // Included in a processed block
+	TxStatusDropped  = "dropped"  // Evicted from the mempool without being mined
+	TxStatusReplaced = "replaced" // Superseded by another transaction with the same sender and nonce
+)
+
+// IsContractCreation reports whether the transaction deploys a contract (no recipient)
+func (tx Transaction) IsContractCreation() bool {
+	return tx.To == ""
+}
+
+// Kinds of same-nonce replacement
+const (
+	ReplacementSpeedUp = "speed-up" // Resubmission of the same intent, typically with a higher fee
+	ReplacementCancel  = "cancel"   // Zero-value self-transfer that voids the original transaction
+)
+
+// Alert is a problem detected with the pending transactions of a subscribed sender
+type Alert struct {
+	Type       string    `json:"type"` // One of the Alert values
+	Address    string    `json:"address"`
+	Nonce      uint64    `json:"nonce"`
+	TxHash     string    `json:"txHash,omitempty"`
+	Detail     string    `json:"detail"`
+	DetectedAt time.Time `json:"detectedAt"`
+}
+
+// Kinds of pending transaction alerts
+const (
+	AlertNonceGap    = "nonce_gap"   // A nonce is missing, blocking later transactions
+	AlertStuck       = "stuck"       // Pending for longer than the configured threshold
+	AlertUnderpriced = "underpriced" // Fee cap is below the current base fee
+)
+
+// Event is a notification published to in-process listeners
+type Event struct {
+	Seq     uint64      `json:"seq"`  // Monotonically increasing sequence number assigned on publish
+	Type    string      `json:"type"` // One of the Event values
+	Address string      `json:"address,omitempty"`
+	Data    interface{} `json:"data"`
+}
+
+// Kinds of events
+const (
+	EventAlert         = "alert"
+	EventTransaction   = "transaction"    // A transaction was saved or its status changed
+	EventTokenTransfer = "token_transfer" // An ERC-20 transfer involving a subscribed address was mined
+	EventBlock         = "block"          // A block was processed
+	EventReorg         = "reorg"          // A processed block is no longer part of the canonical chain
+)
+
+// TokenTransfer is an ERC-20 transfer decoded from a mined transfer or transferFrom call
+type TokenTransfer struct {
+	Token       string `json:"token"` // Token contract address
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Amount      string `json:"amount"` // Raw token units in decimal
+	TxHash      string `json:"txHash"`
+	BlockNumber string `json:"blockNumber"`
+}
+
+// DecodedCall is contract calldata decoded against a known function signature
+type DecodedCall struct {
+	Selector string         `json:"selector"`
+	Method   string         `json:"method"` // Function signature, e.g. "transfer(address,uint256)"
+	Params   []DecodedParam `json:"params"`
+}
+
+// DecodedParam is one argument of a decoded call
+type DecodedParam struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value string `json:"value"` // Addresses as 0x hex, integers in decimal
+}
+
+// BlockSummary is the payload of a block event
+type BlockSummary struct {
+	Number           string `json:"number"`
+	Hash             string `json:"hash"`
+	ParentHash       string `json:"parentHash"`
+	Timestamp        string `json:"timestamp"`
+	TransactionCount int    `json:"transactionCount"`
+}
+
+// Reorg is the payload of a reorg event
+type Reorg struct {
+	Number  string `json:"number"`  // Block that was replaced
+	OldHash string `json:"oldHash"` // Hash of the block that was processed
+	NewHash string `json:"newHash"` // Hash of the block now on the canonical chain
+}
+
+// Webhook is a registration to receive events by HTTP POST
+type Webhook struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"secret,omitempty"`    // HMAC-SHA256 signing key, only returned on creation
+	Addresses []string  `json:"addresses,omitempty"` // Addresses whose events are delivered; empty means all
+	Events    []string  `json:"events,omitempty"`    // Event types delivered; empty means all
+	Tenant    string    `json:"tenant,omitempty"`    // Set when registered with an API key; limits events to the tenant's subscriptions
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// APIKey authenticates requests as a tenant. Only a hash of the key is kept; the key itself is
+// returned once, when it is issued.
+type APIKey struct {
+	ID        string     `json:"id"`
+	Tenant    string     `json:"tenant"`
+	Name      string     `json:"name,omitempty"`
+	Prefix    string     `json:"prefix"`         // Leading characters of the key, to tell keys apart
+	Hash      string     `json:"hash,omitempty"` // Hex SHA-256 of the key, never returned by the API
+	CreatedAt time.Time  `json:"createdAt"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+}
+
+// Quota reports a tenant's usage of its limits
+type Quota struct {
+	Tenant        string            `json:"tenant"`
+	Subscriptions SubscriptionQuota `json:"subscriptions"`
+	Requests      *RequestQuota     `json:"requests,omitempty"` // Nil when rate limiting is off
+}
+
+// SubscriptionQuota is the number of subscribed addresses and the most allowed
+type SubscriptionQuota struct {
+	Used  int `json:"used"`
+	Limit int `json:"limit,omitempty"` // 0 means no limit
+}
+
+// RequestQuota is the token bucket rate limit of one client
+type RequestQuota struct {
+	PerSecond float64 `json:"perSecond"` // Sustained request rate
+	Burst     int     `json:"burst"`     // Requests that can be made at once
+	Remaining int     `json:"remaining"` // Requests that can be made now
+}
+
+// SyncStatus reports the progress of the block listener, served at /status
+type SyncStatus struct {
+	Version              string     `json:"version"`
+	StartedAt            time.Time  `json:"startedAt"`
+	UptimeSeconds        int64      `json:"uptimeSeconds"`
+	HeadBlock            *int64     `json:"headBlock"` // Null until the node has reported it
+	ProcessedBlock       int64      `json:"processedBlock"`
+	Lag                  *int64     `json:"lag"` // Blocks between the head and the processed block
+	LastRPCSuccess       *time.Time `json:"lastRpcSuccess"`
+	LastBlockProcessedAt *time.Time `json:"lastBlockProcessedAt"`
+	LastError            string     `json:"lastError,omitempty"` // Most recent failure of the processing loop
+	LastErrorAt          *time.Time `json:"lastErrorAt,omitempty"`
+	Paused               bool       `json:"paused"` // Whether an admin paused the processing loop
+}
+
+// ProcessorStatus is the state of the block processor shown to admins
+type ProcessorStatus struct {
+	SyncStatus
+	PausedAt  *time.Time     `json:"pausedAt,omitempty"`
+	Reprocess *ReprocessTask `json:"reprocess,omitempty"` // The running or most recent reprocess
+}
+
+// States of a ReprocessTask or BackfillJob
+const (
+	TaskQueued   = "queued"
+	TaskRunning  = "running"
+	TaskDone     = "done"
+	TaskFailed   = "failed"
+	TaskCanceled = "canceled" // The address was unsubscribed before the job finished
+)
+
+// ReprocessTask processes a range of blocks again for some addresses
+type ReprocessTask struct {
+	FromBlock    uint64     `json:"fromBlock"`
+	ToBlock      uint64     `json:"toBlock"`
+	Addresses    []string   `json:"addresses"`
+	State        string     `json:"state"`        // One of the Task values
+	NextBlock    uint64     `json:"nextBlock"`    // First block not yet reprocessed
+	Transactions int        `json:"transactions"` // Transactions matched so far
+	Error        string     `json:"error,omitempty"`
+	StartedAt    time.Time  `json:"startedAt"`
+	FinishedAt   *time.Time `json:"finishedAt,omitempty"`
+}
+
+// BackfillJob scans the blocks processed before an address was subscribed for its transactions
+type BackfillJob struct {
+	ID            string     `json:"id"`
+	Tenant        string     `json:"tenant"`
+	Address       string     `json:"address"`
+	FromBlock     uint64     `json:"fromBlock"`               // Resolved from FromTimestamp once the job runs, if given
+	FromTimestamp int64      `json:"fromTimestamp,omitempty"` // Unix seconds
+	ToBlock       uint64     `json:"toBlock"`                 // Last block processed before the subscription
+	State         string     `json:"state"`                   // One of the Task values
+	NextBlock     uint64     `json:"nextBlock"`               // First block not yet scanned
+	Transactions  int        `json:"transactions"`            // Transactions found so far
+	Error         string     `json:"error,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	FinishedAt    *time.Time `json:"finishedAt,omitempty"`
+}
+
+// HealthCheck is the result of one readiness check
+type HealthCheck struct {
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail,omitempty"` // Why the check failed
+}
+
+// WebhookDelivery is one event queued for delivery to a webhook
+type WebhookDelivery struct {
+	ID            string    `json:"id"`
+	WebhookID     string    `json:"webhookId"`
+	Event         Event     `json:"event"`
+	Status        string    `json:"status"` // One of the Delivery values
+	Attempts      int       `json:"attempts"`
+	NextAttemptAt time.Time `json:"nextAttemptAt,omitempty"`
+	LastError     string    `json:"lastError,omitempty"`
+	CreatedAt     time.Time `json:"createdAt"`
+	DeliveredAt   time.Time `json:"deliveredAt,omitempty"`
+}
+
+// Webhook delivery states
+const (
+	DeliveryPending   = "pending"   // Waiting for its first or next attempt
+	DeliveryDelivered = "delivered" // Acknowledged by the receiver with a 2xx response
+	DeliveryDead      = "dead"      // Gave up after the maximum number of attempts
+)
+
+// TxPoolContent is the result of the txpool_content RPC, keyed by sender and then nonce
+type TxPoolContent struct {
+	Pending map[string]map[string]Transaction `json:"pending"`
+	Queued  map[string]map[string]Transaction `json:"queued"`
+}
+
+// Receipt represents the subset of an Ethereum transaction receipt used by the parser
+type Receipt struct {
+	TransactionHash string `json:"transactionHash"`
+	BlockNumber     string `json:"blockNumber"`
+	ContractAddress string `json:"contractAddress"`
+	Status          string `json:"status"`
 }
 
 // Block represents the structure of an Ethereum block
 type Block struct {
-	Number       string
-	Transactions []Transaction
+	Number       string        `json:"number"`
+	Transactions []Transaction `json:"transactions"`
+
+	// Header fields, used to verify the block's integrity. Fields introduced by later forks
+	// are empty for blocks that predate them.
+	Hash                  string `json:"hash,omitempty"`
+	ParentHash            string `json:"parentHash,omitempty"`
+	Sha3Uncles            string `json:"sha3Uncles,omitempty"`
+	Miner                 string `json:"miner,omitempty"`
+	StateRoot             string `json:"stateRoot,omitempty"`
+	TransactionsRoot      string `json:"transactionsRoot,omitempty"`
+	ReceiptsRoot          string `json:"receiptsRoot,omitempty"`
+	LogsBloom             string `json:"logsBloom,omitempty"`
+	Difficulty            string `json:"difficulty,omitempty"`
+	GasLimit              string `json:"gasLimit,omitempty"`
+	GasUsed               string `json:"gasUsed,omitempty"`
+	Timestamp             string `json:"timestamp,omitempty"`
+	ExtraData             string `json:"extraData,omitempty"`
+	MixHash               string `json:"mixHash,omitempty"`
+	Nonce                 string `json:"nonce,omitempty"`
+	BaseFeePerGas         string `json:"baseFeePerGas,omitempty"`         // London
+	WithdrawalsRoot       string `json:"withdrawalsRoot,omitempty"`       // Shanghai
+	BlobGasUsed           string `json:"blobGasUsed,omitempty"`           // Cancun
+	ExcessBlobGas         string `json:"excessBlobGas,omitempty"`         // Cancun
+	ParentBeaconBlockRoot string `json:"parentBeaconBlockRoot,omitempty"` // Cancun
+	RequestsHash          string `json:"requestsHash,omitempty"`          // Prague
+
+	// Verified is set on a fetched block that passed verification against its own header
+	Verified bool `json:"-"`
 }
 
+// DefaultTenant owns the subscriptions made without an API key
+const DefaultTenant = "default"
+
 // BlockStorage is an in-memory storage for block-related data
 type BlockStorage struct {
 	mu           sync.RWMutex
-	currentBlock string          // Latest block number processed by the listener
-	subscribers  map[string]bool // Subscribed addresses
+	currentBlock string                     // Latest block number processed by the listener
+	subscribers  map[string]map[string]bool // Subscribed addresses of each tenant
 	transactions map[string][]Transaction
+	txIndex      map[string]map[string]int // Position of each transaction hash in transactions, per address
+	contracts    map[string][]string       // Contracts deployed by each subscribed address
+	transfers    map[string][]TokenTransfer
 }
 
 // NewBlockStorage initializes an in-memory block storage
 func NewBlockStorage() *BlockStorage {
 	return &BlockStorage{
 		transactions: make(map[string][]Transaction),
-		subscribers:  make(map[string]bool),
+		txIndex:      make(map[string]map[string]int),
+		subscribers:  make(map[string]map[string]bool),
+		contracts:    make(map[string][]string),
+		transfers:    make(map[string][]TokenTransfer),
 		currentBlock: "0x0", // Initialize to 0 or any appropriate value
 	}
 }
@@ -0,0 +1,129 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// StateFile is the name of the storage snapshot inside a data directory
+const StateFile = "state.json"
+
+// LockFile is the name of the file locked by the process writing to a data directory
+const LockFile = "server.lock"
+
+// ErrDataDirLocked is returned when another process holds the lock of a data directory
+var ErrDataDirLocked = errors.New("data directory is locked by another process")
+
+// snapshot is the on-disk form of a BlockStorage
+type snapshot struct {
+	CurrentBlock   string                     `json:"currentBlock"`
+	Subscribers    []string                   `json:"subscribers"`       // Default tenant
+	Tenants        map[string][]string        `json:"tenants,omitempty"` // Subscriptions of other tenants
+	Transactions   map[string][]Transaction   `json:"transactions"`
+	Contracts      map[string][]string        `json:"contracts"`
+	TokenTransfers map[string][]TokenTransfer `json:"tokenTransfers"`
+}
+
+// StatePath returns the path of the storage snapshot in a data directory
+func StatePath(dataDir string) string {
+	return filepath.Join(dataDir, StateFile)
+}
+
+// LockDataDir takes the lock of a data directory, so only one process writes its snapshot. The
+// lock is held until unlock is called or the process exits. Returns ErrDataDirLocked if another
+// process holds it.
+func LockDataDir(dataDir string) (unlock func() error, err error) {
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(filepath.Join(dataDir, LockFile), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f.Close, nil
+}
+
+// SaveFile writes a snapshot of the storage to path. The file is replaced atomically so readers
+// never see a partial snapshot.
+func (s *BlockStorage) SaveFile(path string) error {
+	s.mu.RLock()
+	snap := snapshot{
+		CurrentBlock:   s.currentBlock,
+		Transactions:   s.transactions,
+		Contracts:      s.contracts,
+		TokenTransfers: s.transfers,
+	}
+	for tenant, addresses := range s.subscribers {
+		for address := range addresses {
+			if tenant == DefaultTenant {
+				snap.Subscribers = append(snap.Subscribers, address)
+				continue
+			}
+			if snap.Tenants == nil {
+				snap.Tenants = make(map[string][]string)
+			}
+			snap.Tenants[tenant] = append(snap.Tenants[tenant], address)
+		}
+	}
+	data, err := json.Marshal(snap)
+	s.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// LoadBlockStorage reads a storage snapshot from path. A missing file yields empty storage.
+func LoadBlockStorage(path string) (*BlockStorage, error) {
+	storage := NewBlockStorage()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return storage, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, err
+	}
+	if snap.CurrentBlock != "" {
+		storage.currentBlock = snap.CurrentBlock
+	}
+	for _, address := range snap.Subscribers {
+		storage.SubscribeTenant(DefaultTenant, address)
+	}
+	for tenant, addresses := range snap.Tenants {
+		for _, address := range addresses {
+			storage.SubscribeTenant(tenant, address)
+		}
+	}
+	if snap.Transactions != nil {
+		storage.transactions = snap.Transactions
+		for address := range storage.transactions {
+			storage.indexTransactions(address)
+		}
+	}
+	if snap.Contracts != nil {
+		storage.contracts = snap.Contracts
+	}
+	if snap.TokenTransfers != nil {
+		storage.transfers = snap.TokenTransfers
+	}
+	return storage, nil
+}
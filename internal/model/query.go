@@ -0,0 +1,212 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Transaction directions relative to the queried address
+const (
+	DirectionIn  = "in"
+	DirectionOut = "out"
+)
+
+// Sort keys and orders for transaction queries
+const (
+	SortByBlock = "block"
+	SortByTime  = "time"
+	OrderAsc    = "asc"
+	OrderDesc   = "desc"
+)
+
+// TransactionQuery selects, orders and pages the stored transactions of an address.
+// Zero values leave the corresponding filter unset.
+type TransactionQuery struct {
+	Direction    string   // DirectionIn, DirectionOut or empty for both
+	FromBlock    *uint64  // Inclusive lower block bound
+	ToBlock      *uint64  // Inclusive upper block bound
+	FromTime     int64    // Inclusive lower unix time bound
+	ToTime       int64    // Inclusive upper unix time bound
+	MinValue     *big.Int // Inclusive lower value bound in wei
+	MaxValue     *big.Int // Inclusive upper value bound in wei
+	Counterparty string   // Other side of the transfer
+	Status       string   // One of the TxStatus values
+	SortBy       string   // SortByBlock (default) or SortByTime
+	Order        string   // OrderAsc (default) or OrderDesc
+	Limit        int      // Maximum number of results; 0 returns everything
+	Cursor       string   // NextCursor of the previous page
+}
+
+// TransactionPage is one page of query results
+type TransactionPage struct {
+	Transactions []Transaction
+	NextCursor   string // Empty when there are no more results
+}
+
+// cursorPosition identifies the last transaction of a page in sort order
+type cursorPosition struct {
+	Key   uint64 `json:"k"`
+	Index uint64 `json:"i"`
+	Hash  string `json:"h"`
+}
+
+// QueryTransactions returns the stored transactions of an address matching the query
+func (s *BlockStorage) QueryTransactions(address string, query TransactionQuery) (TransactionPage, error) {
+	return ApplyTransactionQuery(s.GetTransactions(strings.ToLower(address)), address, query)
+}
+
+// ApplyTransactionQuery filters, sorts and pages a list of transactions belonging to address
+func ApplyTransactionQuery(transactions []Transaction, address string, query TransactionQuery) (TransactionPage, error) {
+	address = strings.ToLower(address)
+
+	var matched []Transaction
+	for _, tx := range transactions {
+		if query.matches(address, tx) {
+			matched = append(matched, tx)
+		}
+	}
+
+	less := func(a, b cursorPosition) bool {
+		if a.Key != b.Key {
+			return a.Key < b.Key
+		}
+		if a.Index != b.Index {
+			return a.Index < b.Index
+		}
+		return a.Hash < b.Hash
+	}
+	if query.Order == OrderDesc {
+		ascending := less
+		less = func(a, b cursorPosition) bool { return ascending(b, a) }
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return less(query.position(matched[i]), query.position(matched[j]))
+	})
+
+	// Skip everything up to and including the cursor position
+	if query.Cursor != "" {
+		after, err := decodeCursor(query.Cursor)
+		if err != nil {
+			return TransactionPage{}, err
+		}
+		start := sort.Search(len(matched), func(i int) bool { return less(after, query.position(matched[i])) })
+		matched = matched[start:]
+	}
+
+	page := TransactionPage{Transactions: matched}
+	if query.Limit > 0 && len(matched) > query.Limit {
+		page.Transactions = matched[:query.Limit]
+		page.NextCursor = encodeCursor(query.position(matched[query.Limit-1]))
+	}
+	return page, nil
+}
+
+// CursorOf returns a cursor that resumes the query after the given transaction
+func (q TransactionQuery) CursorOf(tx Transaction) string {
+	return encodeCursor(q.position(tx))
+}
+
+// matches reports whether a transaction of address passes every filter of the query
+func (q TransactionQuery) matches(address string, tx Transaction) bool {
+	from, to := strings.ToLower(tx.From), strings.ToLower(tx.To)
+
+	switch q.Direction {
+	case DirectionIn:
+		if to != address {
+			return false
+		}
+	case DirectionOut:
+		if from != address {
+			return false
+		}
+	}
+
+	if q.Counterparty != "" {
+		counterparty := strings.ToLower(q.Counterparty)
+		if !(from == address && to == counterparty) && !(to == address && from == counterparty) {
+			return false
+		}
+	}
+
+	if q.Status != "" && tx.Status != q.Status {
+		return false
+	}
+
+	if q.FromBlock != nil || q.ToBlock != nil {
+		block, ok := parseQuantity(tx.BlockNumber)
+		if !ok || (q.FromBlock != nil && block < *q.FromBlock) || (q.ToBlock != nil && block > *q.ToBlock) {
+			return false
+		}
+	}
+
+	if q.FromTime != 0 || q.ToTime != 0 {
+		t := transactionTime(tx)
+		if (q.FromTime != 0 && t < q.FromTime) || (q.ToTime != 0 && t > q.ToTime) {
+			return false
+		}
+	}
+
+	if q.MinValue != nil || q.MaxValue != nil {
+		value, ok := new(big.Int).SetString(strings.TrimPrefix(tx.Value, "0x"), 16)
+		if !ok || (q.MinValue != nil && value.Cmp(q.MinValue) < 0) || (q.MaxValue != nil && value.Cmp(q.MaxValue) > 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// position returns the sort position of a transaction; pending transactions sort after mined ones
+func (q TransactionQuery) position(tx Transaction) cursorPosition {
+	var key uint64
+	if q.SortBy == SortByTime {
+		key = uint64(transactionTime(tx))
+	} else {
+		block, ok := parseQuantity(tx.BlockNumber)
+		if !ok {
+			block = math.MaxUint64
+		}
+		key = block
+	}
+	index, _ := parseQuantity(tx.TransactionIndex)
+	return cursorPosition{Key: key, Index: index, Hash: tx.Hash}
+}
+
+// transactionTime returns when a transaction was mined, or first seen if it is still pending
+func transactionTime(tx Transaction) int64 {
+	if tx.Timestamp != 0 {
+		return tx.Timestamp
+	}
+	return tx.SeenAt
+}
+
+// parseQuantity parses a 0x-prefixed hex quantity
+func parseQuantity(value string) (uint64, bool) {
+	n, err := strconv.ParseUint(strings.TrimPrefix(value, "0x"), 16, 64)
+	return n, err == nil && value != ""
+}
+
+func encodeCursor(position cursorPosition) string {
+	data, _ := json.Marshal(position)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(cursor string) (cursorPosition, error) {
+	var position cursorPosition
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return position, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, &position); err != nil {
+		return position, ErrInvalidCursor
+	}
+	return position, nil
+}
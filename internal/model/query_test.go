@@ -0,0 +1,103 @@
+package model
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	queryAddress = "0x1234567890abcdef1234567890abcdef12345678"
+	otherAddress = "0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef"
+)
+
+// queryFixture stores ten transactions alternating between outgoing and incoming, one per block
+func queryFixture(t *testing.T) *BlockStorage {
+	storage := NewBlockStorage()
+	for i := 0; i < 10; i++ {
+		tx := Transaction{
+			Hash:        fmt.Sprintf("0x%02d", i),
+			From:        queryAddress,
+			To:          otherAddress,
+			Value:       fmt.Sprintf("0x%x", i*100),
+			BlockNumber: fmt.Sprintf("0x%x", 100+i),
+			Timestamp:   int64(1000 - i), // Later blocks get earlier times to tell the sort keys apart
+			Status:      TxStatusMined,
+		}
+		if i%2 == 1 {
+			tx.From, tx.To = otherAddress, queryAddress
+		}
+		assert.NoError(t, storage.SaveTransaction(queryAddress, tx))
+	}
+	return storage
+}
+
+func hashes(page TransactionPage) []string {
+	var result []string
+	for _, tx := range page.Transactions {
+		result = append(result, tx.Hash)
+	}
+	return result
+}
+
+func TestQueryTransactionsFilters(t *testing.T) {
+	storage := queryFixture(t)
+	fromBlock, toBlock := uint64(102), uint64(105)
+
+	tests := []struct {
+		name     string
+		query    TransactionQuery
+		expected []string
+	}{
+		{"no filters", TransactionQuery{}, []string{"0x00", "0x01", "0x02", "0x03", "0x04", "0x05", "0x06", "0x07", "0x08", "0x09"}},
+		{"outgoing", TransactionQuery{Direction: DirectionOut}, []string{"0x00", "0x02", "0x04", "0x06", "0x08"}},
+		{"incoming", TransactionQuery{Direction: DirectionIn}, []string{"0x01", "0x03", "0x05", "0x07", "0x09"}},
+		{"block range", TransactionQuery{FromBlock: &fromBlock, ToBlock: &toBlock}, []string{"0x02", "0x03", "0x04", "0x05"}},
+		{"time range", TransactionQuery{FromTime: 997, ToTime: 998}, []string{"0x02", "0x03"}},
+		{"value range", TransactionQuery{MinValue: big.NewInt(200), MaxValue: big.NewInt(400)}, []string{"0x02", "0x03", "0x04"}},
+		{"counterparty", TransactionQuery{Counterparty: queryAddress}, nil},
+		{"status", TransactionQuery{Status: TxStatusPending}, nil},
+		{"sort by time", TransactionQuery{SortBy: SortByTime, Limit: 3}, []string{"0x09", "0x08", "0x07"}},
+		{"descending", TransactionQuery{Order: OrderDesc, Limit: 3}, []string{"0x09", "0x08", "0x07"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := storage.QueryTransactions(queryAddress, tt.query)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, hashes(page))
+		})
+	}
+}
+
+func TestQueryTransactionsPagination(t *testing.T) {
+	storage := queryFixture(t)
+	query := TransactionQuery{Order: OrderDesc, Limit: 4}
+
+	var pages [][]string
+	for {
+		page, err := storage.QueryTransactions(queryAddress, query)
+		assert.NoError(t, err)
+		pages = append(pages, hashes(page))
+		if page.NextCursor == "" {
+			break
+		}
+		query.Cursor = page.NextCursor
+
+		// A transaction saved between pages must not shift the following pages
+		if len(pages) == 1 {
+			assert.NoError(t, storage.SaveTransaction(queryAddress, Transaction{Hash: "0xnew", From: queryAddress, BlockNumber: "0x200"}))
+		}
+	}
+
+	assert.Equal(t, [][]string{
+		{"0x09", "0x08", "0x07", "0x06"},
+		{"0x05", "0x04", "0x03", "0x02"},
+		{"0x01", "0x00"},
+	}, pages)
+
+	_, err := storage.QueryTransactions(queryAddress, TransactionQuery{Cursor: "not-a-cursor"})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
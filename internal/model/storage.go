@@ -2,6 +2,7 @@
 package model
 
 import (
+	"sort"
 	"strings"
 )
 
@@ -20,32 +21,244 @@ func (s *BlockStorage) GetCurrentBlock() (string, error) {
 	return s.currentBlock, nil
 }
 
-// SaveTransaction stores a transaction for an address
+// SaveTransaction stores a transaction for an address, replacing any stored transaction with the same hash
 func (s *BlockStorage) SaveTransaction(address string, tx Transaction) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	if i, ok := s.txIndex[address][tx.Hash]; ok {
+		s.transactions[address][i] = tx
+		return nil
+	}
+	if s.txIndex[address] == nil {
+		s.txIndex[address] = make(map[string]int)
+	}
+	s.txIndex[address][tx.Hash] = len(s.transactions[address])
 	s.transactions[address] = append(s.transactions[address], tx)
 	return nil
 }
 
-// Get All Subscription
+// AddTransaction stores a transaction for an address unless one with the same hash is stored.
+// Returns false if it was already stored.
+func (s *BlockStorage) AddTransaction(address string, tx Transaction) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.txIndex[address][tx.Hash]; ok {
+		return false, nil
+	}
+	if s.txIndex[address] == nil {
+		s.txIndex[address] = make(map[string]int)
+	}
+	s.txIndex[address][tx.Hash] = len(s.transactions[address])
+	s.transactions[address] = append(s.transactions[address], tx)
+	return true, nil
+}
+
+// UpdateTransactionStatus changes the status of a stored transaction from one status to another,
+// returning the updated transaction. Returns false if it is not stored with status from, such as
+// a pending transaction the block loop has meanwhile stored as mined.
+func (s *BlockStorage) UpdateTransactionStatus(address string, hash string, from string, to string) (Transaction, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i, ok := s.txIndex[address][hash]
+	if !ok || s.transactions[address][i].Status != from {
+		return Transaction{}, false, nil
+	}
+	s.transactions[address][i].Status = to
+	return s.transactions[address][i], true, nil
+}
+
+// indexTransactions rebuilds the hash index of an address's transactions
+func (s *BlockStorage) indexTransactions(address string) {
+	index := make(map[string]int, len(s.transactions[address]))
+	for i, tx := range s.transactions[address] {
+		index[tx.Hash] = i
+	}
+	s.txIndex[address] = index
+}
+
+// GetAllSubscriptions returns the addresses subscribed by any tenant
 func (s *BlockStorage) GetAllSubscriptions() map[string]bool {
-	return s.subscribers
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	// Return a copy so callers can iterate while new subscriptions are added
+	subscribers := make(map[string]bool)
+	for _, addresses := range s.subscribers {
+		for address := range addresses {
+			subscribers[address] = true
+		}
+	}
+	return subscribers
+}
+
+// GetTenantSubscriptions returns the addresses subscribed by one tenant
+func (s *BlockStorage) GetTenantSubscriptions(tenant string) map[string]bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	subscribers := make(map[string]bool, len(s.subscribers[tenant]))
+	for address := range s.subscribers[tenant] {
+		subscribers[address] = true
+	}
+	return subscribers
+}
+
+// GetSubscribedTenants returns the tenants subscribed to an address, sorted
+func (s *BlockStorage) GetSubscribedTenants(address string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var tenants []string
+	for tenant, addresses := range s.subscribers {
+		if addresses[strings.ToLower(address)] {
+			tenants = append(tenants, tenant)
+		}
+	}
+	sort.Strings(tenants)
+	return tenants
 }
 
 // GetTransactions retrieves all transactions for a given address
 func (s *BlockStorage) GetTransactions(address string) []Transaction {
 	s.mu.RLock() // Read lock for concurrent reads
 	defer s.mu.RUnlock()
-	return s.transactions[address]
+	// Return a copy so callers never observe in-place status updates mid-read
+	return append([]Transaction(nil), s.transactions[address]...)
 }
 
-// Subscribe adds an address to the list of observed addresses
+// Subscribe adds an address to the subscriptions of the default tenant
 func (s *BlockStorage) Subscribe(address string) (bool, error) {
-	if _, exists := s.subscribers[strings.ToLower(address)]; !exists {
-		s.subscribers[strings.ToLower(address)] = true // Mark the address as subscribed
-		return true, nil
+	return s.SubscribeTenant(DefaultTenant, address)
+}
+
+// Unsubscribe removes an address from the subscriptions of the default tenant
+func (s *BlockStorage) Unsubscribe(address string) (bool, error) {
+	return s.UnsubscribeTenant(DefaultTenant, address)
+}
+
+// SubscribeTenant adds an address to the subscriptions of a tenant
+func (s *BlockStorage) SubscribeTenant(tenant string, address string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	address = strings.ToLower(address)
+	if s.subscribers[tenant][address] {
+		return false, nil
 	}
+	if s.subscribers[tenant] == nil {
+		s.subscribers[tenant] = make(map[string]bool)
+	}
+	s.subscribers[tenant][address] = true
+	return true, nil
+}
+
+// UnsubscribeTenant removes an address from the subscriptions of a tenant. Its stored
+// transactions are kept, and it stays observed while any other tenant subscribes to it.
+func (s *BlockStorage) UnsubscribeTenant(tenant string, address string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	address = strings.ToLower(address)
+	if !s.subscribers[tenant][address] {
+		return false, nil
+	}
+	delete(s.subscribers[tenant], address)
+	if len(s.subscribers[tenant]) == 0 {
+		delete(s.subscribers, tenant)
+	}
+	return true, nil
+}
+
+// SaveContract records a contract address deployed by the given address, ignoring a contract
+// that is already recorded
+func (s *BlockStorage) SaveContract(deployer string, contract string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	deployer = strings.ToLower(deployer)
+	contract = strings.ToLower(contract)
+	for _, existing := range s.contracts[deployer] {
+		if existing == contract {
+			return nil
+		}
+	}
+	s.contracts[deployer] = append(s.contracts[deployer], contract)
+	return nil
+}
+
+// GetContracts retrieves all contracts deployed by the given address
+func (s *BlockStorage) GetContracts(deployer string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]string(nil), s.contracts[strings.ToLower(deployer)]...)
+}
 
-	return false, nil
+// SaveTokenTransfer records a token transfer involving the given address, replacing any stored
+// transfer from the same transaction
+func (s *BlockStorage) SaveTokenTransfer(address string, transfer TokenTransfer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	address = strings.ToLower(address)
+	for i, existing := range s.transfers[address] {
+		if existing.TxHash == transfer.TxHash {
+			s.transfers[address][i] = transfer
+			return nil
+		}
+	}
+	s.transfers[address] = append(s.transfers[address], transfer)
+	return nil
+}
+
+// GetTokenTransfers retrieves all token transfers involving the given address
+func (s *BlockStorage) GetTokenTransfers(address string) []TokenTransfer {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]TokenTransfer(nil), s.transfers[strings.ToLower(address)]...)
+}
+
+// RemoveTransactionsAbove deletes the mined transactions, token transfers and created contracts of
+// blocks after blockNumber, so they can be processed again. Pending transactions have no block and
+// are kept.
+func (s *BlockStorage) RemoveTransactionsAbove(blockNumber uint64) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	above := func(block string) bool {
+		n, ok := parseQuantity(block)
+		return ok && n > blockNumber
+	}
+
+	removed := 0
+	contracts := make(map[string]bool)
+	for address, transactions := range s.transactions {
+		kept := transactions[:0]
+		for _, tx := range transactions {
+			if above(tx.BlockNumber) {
+				if tx.ContractAddress != "" {
+					contracts[strings.ToLower(tx.From)+":"+strings.ToLower(tx.ContractAddress)] = true
+				}
+				removed++
+				continue
+			}
+			kept = append(kept, tx)
+		}
+		s.transactions[address] = kept
+		s.indexTransactions(address)
+	}
+	for address, transfers := range s.transfers {
+		kept := transfers[:0]
+		for _, transfer := range transfers {
+			if !above(transfer.BlockNumber) {
+				kept = append(kept, transfer)
+			}
+		}
+		s.transfers[address] = kept
+	}
+	for deployer, deployed := range s.contracts {
+		kept := deployed[:0]
+		for _, contract := range deployed {
+			if !contracts[deployer+":"+contract] {
+				kept = append(kept, contract)
+			}
+		}
+		if len(kept) == 0 {
+			delete(s.contracts, deployer)
+			continue
+		}
+		s.contracts[deployer] = kept
+	}
+	return removed, nil
 }
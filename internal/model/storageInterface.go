@@ -1,5 +1,10 @@
 package model
 
+import "errors"
+
+// errSnapshotsUnsupported is returned when snapshotting a store that cannot be written to a file
+var errSnapshotsUnsupported = errors.New("store does not support snapshots")
+
 // Store is a global variable to hold the instance of the storeInterface.
 var store storeInterface
 
@@ -8,8 +13,12 @@ func SharedStore() storeInterface {
 	return store
 }
 
-// InitializeStore initializes the global store with a concrete implementation.
+// InitializeStore initializes the global store with a concrete implementation. Its operations
+// are timed when StorageObserver is set.
 func InitializeStore(s storeInterface) {
+	if StorageObserver != nil {
+		s = timedStore{inner: s}
+	}
 	store = s
 }
 
@@ -18,18 +27,57 @@ type storeInterface interface {
 	// GetCurrentBlock retrieves the number of the current Ethereum block being tracked.
 	GetCurrentBlock() (string, error)
 
-	// Subscribe adds an Ethereum address to be tracked. Returns true if the subscription is new, false if the address is already subscribed.
+	// Subscribe adds an Ethereum address to be tracked for the default tenant. Returns true if the subscription is new, false if the address is already subscribed.
 	Subscribe(address string) (bool, error)
 
+	// Unsubscribe stops tracking an Ethereum address for the default tenant. Returns false if the address was not subscribed.
+	Unsubscribe(address string) (bool, error)
+
+	// SubscribeTenant adds an Ethereum address to be tracked for a tenant. Returns false if the tenant already subscribes to it.
+	SubscribeTenant(tenant string, address string) (bool, error)
+
+	// UnsubscribeTenant stops tracking an Ethereum address for a tenant. Returns false if the tenant did not subscribe to it.
+	UnsubscribeTenant(tenant string, address string) (bool, error)
+
+	// GetTenantSubscriptions retrieves the Ethereum addresses a tenant subscribes to.
+	GetTenantSubscriptions(tenant string) map[string]bool
+
+	// GetSubscribedTenants retrieves the tenants that subscribe to an Ethereum address.
+	GetSubscribedTenants(address string) []string
+
 	// GetTransactions retrieves the list of transactions associated with a specific address.
 	GetTransactions(address string) []Transaction
 
+	// QueryTransactions retrieves a filtered, sorted page of the transactions associated with a specific address.
+	QueryTransactions(address string, query TransactionQuery) (TransactionPage, error)
+
 	// SaveBlock persists the latest block number.
 	SaveBlock(blockNumber string) error
 
-	// GetAllSubscriptions retrieves all Ethereum addresses that are currently being tracked.
+	// GetAllSubscriptions retrieves all Ethereum addresses that are currently being tracked by any tenant.
 	GetAllSubscriptions() map[string]bool
 
-	// SaveTransaction saves a transaction associated with an Ethereum address.
+	// SaveTransaction saves a transaction associated with an Ethereum address, replacing any stored transaction with the same hash.
+
+	// AddTransaction saves a transaction associated with an Ethereum address unless one with the same hash is already stored. Returns false if it was already stored.
+	AddTransaction(address string, tx Transaction) (bool, error)
+
+	// UpdateTransactionStatus changes the status of a stored transaction if it still has the status from. Returns the updated transaction, or false if it is not stored with that status.
+	UpdateTransactionStatus(address string, hash string, from string, to string) (Transaction, bool, error)
 	SaveTransaction(address string, tx Transaction) error
+
+	// SaveContract records a contract address deployed by an Ethereum address.
+	SaveContract(deployer string, contract string) error
+
+	// GetContracts retrieves the contracts deployed by an Ethereum address.
+	GetContracts(deployer string) []string
+
+	// SaveTokenTransfer records a token transfer involving an Ethereum address, replacing any stored transfer from the same transaction.
+	SaveTokenTransfer(address string, transfer TokenTransfer) error
+
+	// GetTokenTransfers retrieves the token transfers involving an Ethereum address.
+	GetTokenTransfers(address string) []TokenTransfer
+
+	// RemoveTransactionsAbove deletes the mined transactions, token transfers and created contracts of blocks after a block number. Returns how many transactions were deleted.
+	RemoveTransactionsAbove(blockNumber uint64) (int, error)
 }
@@ -2,6 +2,7 @@ package model
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -71,3 +72,190 @@ func TestMultipleTransactions(t *testing.T) {
 	transactions := storage.GetTransactions(address)
 	assert.Equal(t, 5, len(transactions), "Should have five transactions")
 }
+
+func TestContracts(t *testing.T) {
+	storage := NewBlockStorage()
+	deployer := "0x1234567890ABCDEF1234567890abcdef12345678"
+
+	assert.Empty(t, storage.GetContracts(deployer), "Should have no contracts for a new address")
+
+	err := storage.SaveContract(deployer, "0xDEADBEEFdeadbeefdeadbeefdeadbeefdeadbeef")
+	assert.NoError(t, err, "Error should be nil when saving contract")
+
+	contracts := storage.GetContracts(strings.ToLower(deployer))
+	assert.Equal(t, []string{"0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef"}, contracts, "Contract should be stored lowercased")
+
+	contracts[0] = "0x0"
+	assert.Equal(t, []string{"0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef"}, storage.GetContracts(deployer), "Callers should get a copy")
+}
+
+func TestSaveTransactionReplacesByHash(t *testing.T) {
+	storage := NewBlockStorage()
+	address := "0x1234567890abcdef1234567890abcdef12345678"
+
+	pending := Transaction{Hash: "0xabc", From: address, Status: TxStatusPending}
+	assert.NoError(t, storage.SaveTransaction(address, pending))
+
+	mined := pending
+	mined.Status = TxStatusMined
+	mined.BlockNumber = "0x10"
+	assert.NoError(t, storage.SaveTransaction(address, mined))
+
+	transactions := storage.GetTransactions(address)
+	assert.Equal(t, 1, len(transactions), "Saving the same hash twice should not duplicate it")
+	assert.Equal(t, TxStatusMined, transactions[0].Status, "Stored transaction should be updated")
+}
+
+func TestAddTransactionAndUpdateStatus(t *testing.T) {
+	storage := NewBlockStorage()
+	address := "0x1234567890abcdef1234567890abcdef12345678"
+
+	added, err := storage.AddTransaction(address, Transaction{Hash: "0xabc", Status: TxStatusPending})
+	assert.NoError(t, err)
+	assert.True(t, added)
+	added, err = storage.AddTransaction(address, Transaction{Hash: "0xabc", Status: TxStatusMined})
+	assert.NoError(t, err)
+	assert.False(t, added, "A stored transaction should not be replaced")
+
+	updated, ok, err := storage.UpdateTransactionStatus(address, "0xabc", TxStatusPending, TxStatusDropped)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, Transaction{Hash: "0xabc", Status: TxStatusDropped}, updated)
+
+	_, ok, err = storage.UpdateTransactionStatus(address, "0xabc", TxStatusPending, TxStatusDropped)
+	assert.NoError(t, err)
+	assert.False(t, ok, "Only a transaction with the expected status should change")
+	_, ok, _ = storage.UpdateTransactionStatus(address, "0xdef", TxStatusPending, TxStatusDropped)
+	assert.False(t, ok)
+	assert.Equal(t, []Transaction{{Hash: "0xabc", Status: TxStatusDropped}}, storage.GetTransactions(address))
+}
+
+func TestTokenTransfers(t *testing.T) {
+	storage := NewBlockStorage()
+
+	address := "0xAbC0000000000000000000000000000000000001"
+	assert.NoError(t, storage.SaveTokenTransfer(address, TokenTransfer{TxHash: "0x1", Amount: "1"}))
+	assert.NoError(t, storage.SaveTokenTransfer(address, TokenTransfer{TxHash: "0x2", Amount: "2"}))
+	assert.NoError(t, storage.SaveTokenTransfer(address, TokenTransfer{TxHash: "0x1", Amount: "3"}))
+
+	transfers := storage.GetTokenTransfers(strings.ToLower(address))
+	assert.Equal(t, []TokenTransfer{{TxHash: "0x1", Amount: "3"}, {TxHash: "0x2", Amount: "2"}}, transfers)
+}
+
+func TestRemoveTransactionsAbove(t *testing.T) {
+	storage := NewBlockStorage()
+	address := "0xabc0000000000000000000000000000000000001"
+	assert.NoError(t, storage.SaveTransaction(address, Transaction{Hash: "0x1", BlockNumber: "0x10"}))
+	assert.NoError(t, storage.SaveTransaction(address, Transaction{Hash: "0x2", BlockNumber: "0x11"}))
+	assert.NoError(t, storage.SaveTransaction(address, Transaction{Hash: "0x3", Status: TxStatusPending}))
+	assert.NoError(t, storage.SaveTokenTransfer(address, TokenTransfer{TxHash: "0x1", BlockNumber: "0x10"}))
+	assert.NoError(t, storage.SaveTokenTransfer(address, TokenTransfer{TxHash: "0x2", BlockNumber: "0x11"}))
+
+	removed, err := storage.RemoveTransactionsAbove(0x10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	assert.Equal(t, []Transaction{{Hash: "0x1", BlockNumber: "0x10"}, {Hash: "0x3", Status: TxStatusPending}}, storage.GetTransactions(address))
+	assert.Equal(t, []TokenTransfer{{TxHash: "0x1", BlockNumber: "0x10"}}, storage.GetTokenTransfers(address))
+
+	// The hash index follows the remaining transactions
+	assert.NoError(t, storage.SaveTransaction(address, Transaction{Hash: "0x3", BlockNumber: "0x11"}))
+	assert.NoError(t, storage.SaveTransaction(address, Transaction{Hash: "0x2", BlockNumber: "0x11"}))
+	assert.Equal(t, []Transaction{{Hash: "0x1", BlockNumber: "0x10"}, {Hash: "0x3", BlockNumber: "0x11"}, {Hash: "0x2", BlockNumber: "0x11"}}, storage.GetTransactions(address))
+}
+
+func TestRemoveContractsAbove(t *testing.T) {
+	storage := NewBlockStorage()
+	deployer := "0xabc0000000000000000000000000000000000001"
+	for _, tx := range []Transaction{
+		{Hash: "0x1", BlockNumber: "0x10", From: deployer, ContractAddress: "0xC1"},
+		{Hash: "0x2", BlockNumber: "0x11", From: deployer, ContractAddress: "0xC2"},
+	} {
+		assert.NoError(t, storage.SaveTransaction(deployer, tx))
+		assert.NoError(t, storage.SaveContract(deployer, tx.ContractAddress))
+	}
+
+	_, err := storage.RemoveTransactionsAbove(0x10)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"0xc1"}, storage.GetContracts(deployer))
+
+	// Reprocessing the rewound block records its contract once
+	assert.NoError(t, storage.SaveContract(deployer, "0xC2"))
+	assert.NoError(t, storage.SaveContract(deployer, "0xc2"))
+	assert.Equal(t, []string{"0xc1", "0xc2"}, storage.GetContracts(deployer))
+}
+
+func TestSaveAndLoadFile(t *testing.T) {
+	storage := NewBlockStorage()
+	storage.SaveBlock("0x10")
+	storage.Subscribe("0xabc")
+	storage.SaveTransaction("0xabc", Transaction{Hash: "0x1"})
+	storage.SaveContract("0xabc", "0xdef")
+
+	path := StatePath(t.TempDir())
+	assert.NoError(t, storage.SaveFile(path))
+
+	loaded, err := LoadBlockStorage(path)
+	assert.NoError(t, err)
+	block, _ := loaded.GetCurrentBlock()
+	assert.Equal(t, "0x10", block)
+	assert.Equal(t, map[string]bool{"0xabc": true}, loaded.GetAllSubscriptions())
+	assert.Equal(t, []Transaction{{Hash: "0x1"}}, loaded.GetTransactions("0xabc"))
+	assert.NoError(t, loaded.SaveTransaction("0xabc", Transaction{Hash: "0x1", BlockNumber: "0x10"}))
+	assert.Equal(t, []Transaction{{Hash: "0x1", BlockNumber: "0x10"}}, loaded.GetTransactions("0xabc"), "Loaded transactions should be indexed by hash")
+	assert.Equal(t, []string{"0xdef"}, loaded.GetContracts("0xabc"))
+
+	unsubscribed, _ := loaded.Unsubscribe("0xABC")
+	assert.True(t, unsubscribed)
+	unsubscribed, _ = loaded.Unsubscribe("0xabc")
+	assert.False(t, unsubscribed)
+
+	empty, err := LoadBlockStorage(StatePath(t.TempDir()))
+	assert.NoError(t, err)
+	block, _ = empty.GetCurrentBlock()
+	assert.Equal(t, "0x0", block)
+}
+
+func TestTenantSubscriptions(t *testing.T) {
+	storage := NewBlockStorage()
+
+	subscribed, _ := storage.SubscribeTenant("acme", "0xABC")
+	assert.True(t, subscribed)
+	subscribed, _ = storage.SubscribeTenant("acme", "0xabc")
+	assert.False(t, subscribed)
+	subscribed, _ = storage.Subscribe("0xabc")
+	assert.True(t, subscribed)
+	storage.SubscribeTenant("globex", "0xdef")
+
+	assert.Equal(t, map[string]bool{"0xabc": true}, storage.GetTenantSubscriptions("acme"))
+	assert.Equal(t, map[string]bool{"0xdef": true}, storage.GetTenantSubscriptions("globex"))
+	assert.Equal(t, map[string]bool{"0xabc": true, "0xdef": true}, storage.GetAllSubscriptions())
+	assert.Equal(t, []string{"acme", DefaultTenant}, storage.GetSubscribedTenants("0xABC"))
+
+	unsubscribed, _ := storage.UnsubscribeTenant("acme", "0xabc")
+	assert.True(t, unsubscribed)
+	unsubscribed, _ = storage.UnsubscribeTenant("globex", "0xabc")
+	assert.False(t, unsubscribed)
+	assert.Empty(t, storage.GetTenantSubscriptions("acme"))
+	assert.True(t, storage.GetAllSubscriptions()["0xabc"], "still subscribed by the default tenant")
+
+	path := StatePath(t.TempDir())
+	assert.NoError(t, storage.SaveFile(path))
+	loaded, err := LoadBlockStorage(path)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"0xabc": true}, loaded.GetTenantSubscriptions(DefaultTenant))
+	assert.Equal(t, map[string]bool{"0xdef": true}, loaded.GetTenantSubscriptions("globex"))
+}
+
+func TestLockDataDir(t *testing.T) {
+	dir := t.TempDir()
+	unlock, err := LockDataDir(dir)
+	assert.NoError(t, err)
+
+	_, err = LockDataDir(dir)
+	assert.ErrorIs(t, err, ErrDataDirLocked)
+
+	assert.NoError(t, unlock())
+	unlock, err = LockDataDir(dir)
+	assert.NoError(t, err, "The lock should be free once released")
+	assert.NoError(t, unlock())
+}
@@ -0,0 +1,122 @@
+package model
+
+import "time"
+
+// StorageObserver, when set before the store is initialized, is called with the name and
+// duration of every store operation
+var StorageObserver func(operation string, elapsed time.Duration)
+
+// timedStore reports the duration of each operation of a store to StorageObserver
+type timedStore struct {
+	inner storeInterface
+}
+
+// observe reports an operation that started at start
+func observe(operation string, start time.Time) {
+	StorageObserver(operation, time.Since(start))
+}
+
+// SaveFile snapshots the underlying store, if it supports snapshots
+func (t timedStore) SaveFile(path string) error {
+	defer observe("save_file", time.Now())
+	snapshotter, ok := t.inner.(interface{ SaveFile(path string) error })
+	if !ok {
+		return errSnapshotsUnsupported
+	}
+	return snapshotter.SaveFile(path)
+}
+
+func (t timedStore) GetCurrentBlock() (string, error) {
+	defer observe("get_current_block", time.Now())
+	return t.inner.GetCurrentBlock()
+}
+
+func (t timedStore) Subscribe(address string) (bool, error) {
+	defer observe("subscribe", time.Now())
+	return t.inner.Subscribe(address)
+}
+
+func (t timedStore) Unsubscribe(address string) (bool, error) {
+	defer observe("unsubscribe", time.Now())
+	return t.inner.Unsubscribe(address)
+}
+
+func (t timedStore) SubscribeTenant(tenant string, address string) (bool, error) {
+	defer observe("subscribe", time.Now())
+	return t.inner.SubscribeTenant(tenant, address)
+}
+
+func (t timedStore) UnsubscribeTenant(tenant string, address string) (bool, error) {
+	defer observe("unsubscribe", time.Now())
+	return t.inner.UnsubscribeTenant(tenant, address)
+}
+
+func (t timedStore) GetTenantSubscriptions(tenant string) map[string]bool {
+	defer observe("get_subscriptions", time.Now())
+	return t.inner.GetTenantSubscriptions(tenant)
+}
+
+func (t timedStore) GetSubscribedTenants(address string) []string {
+	defer observe("get_subscribed_tenants", time.Now())
+	return t.inner.GetSubscribedTenants(address)
+}
+
+func (t timedStore) GetTransactions(address string) []Transaction {
+	defer observe("get_transactions", time.Now())
+	return t.inner.GetTransactions(address)
+}
+
+func (t timedStore) QueryTransactions(address string, query TransactionQuery) (TransactionPage, error) {
+	defer observe("query_transactions", time.Now())
+	return t.inner.QueryTransactions(address, query)
+}
+
+func (t timedStore) SaveBlock(blockNumber string) error {
+	defer observe("save_block", time.Now())
+	return t.inner.SaveBlock(blockNumber)
+}
+
+func (t timedStore) GetAllSubscriptions() map[string]bool {
+	defer observe("get_subscriptions", time.Now())
+	return t.inner.GetAllSubscriptions()
+}
+
+func (t timedStore) SaveTransaction(address string, tx Transaction) error {
+	defer observe("save_transaction", time.Now())
+	return t.inner.SaveTransaction(address, tx)
+}
+
+func (t timedStore) AddTransaction(address string, tx Transaction) (bool, error) {
+	defer observe("add_transaction", time.Now())
+	return t.inner.AddTransaction(address, tx)
+}
+
+func (t timedStore) UpdateTransactionStatus(address string, hash string, from string, to string) (Transaction, bool, error) {
+	defer observe("update_transaction_status", time.Now())
+	return t.inner.UpdateTransactionStatus(address, hash, from, to)
+}
+
+func (t timedStore) SaveContract(deployer string, contract string) error {
+	defer observe("save_contract", time.Now())
+	return t.inner.SaveContract(deployer, contract)
+}
+
+func (t timedStore) GetContracts(deployer string) []string {
+	defer observe("get_contracts", time.Now())
+	return t.inner.GetContracts(deployer)
+}
+
+func (t timedStore) SaveTokenTransfer(address string, transfer TokenTransfer) error {
+	defer observe("save_token_transfer", time.Now())
+	return t.inner.SaveTokenTransfer(address, transfer)
+}
+
+func (t timedStore) GetTokenTransfers(address string) []TokenTransfer {
+	defer observe("get_token_transfers", time.Now())
+	return t.inner.GetTokenTransfers(address)
+}
+
+func (t timedStore) RemoveTransactionsAbove(blockNumber uint64) (int, error) {
+	defer observe("remove_transactions", time.Now())
+	return t.inner.RemoveTransactionsAbove(blockNumber)
+}
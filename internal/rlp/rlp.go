@@ -0,0 +1,64 @@
+// Package rlp implements the Recursive Length Prefix encoding used by Ethereum
+package rlp
+
+import (
+	"encoding/binary"
+	"math/big"
+)
+
+// EncodeBytes encodes a byte string
+func EncodeBytes(b []byte) []byte {
+	if len(b) == 1 && b[0] < 0x80 {
+		return []byte{b[0]}
+	}
+	return append(encodeLength(len(b), 0x80), b...)
+}
+
+// EncodeUint encodes an unsigned integer as a big-endian byte string without leading zeros
+func EncodeUint(u uint64) []byte {
+	if u == 0 {
+		return EncodeBytes(nil)
+	}
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], u)
+	i := 0
+	for buf[i] == 0 {
+		i++
+	}
+	return EncodeBytes(buf[i:])
+}
+
+// EncodeBigInt encodes a non-negative big integer; nil is encoded as zero
+func EncodeBigInt(n *big.Int) []byte {
+	if n == nil {
+		return EncodeBytes(nil)
+	}
+	return EncodeBytes(n.Bytes())
+}
+
+// EncodeList wraps already encoded items into an RLP list
+func EncodeList(items ...[]byte) []byte {
+	size := 0
+	for _, item := range items {
+		size += len(item)
+	}
+	out := encodeLength(size, 0xc0)
+	for _, item := range items {
+		out = append(out, item...)
+	}
+	return out
+}
+
+// encodeLength builds the header for a payload of the given size
+func encodeLength(size int, offset byte) []byte {
+	if size < 56 {
+		return []byte{offset + byte(size)}
+	}
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(size))
+	i := 0
+	for buf[i] == 0 {
+		i++
+	}
+	return append([]byte{offset + 55 + byte(8-i)}, buf[i:]...)
+}
@@ -0,0 +1,36 @@
+package rlp
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoded  []byte
+		expected string
+	}{
+		{"empty string", EncodeBytes(nil), "80"},
+		{"single byte", EncodeBytes([]byte{0x7f}), "7f"},
+		{"single high byte", EncodeBytes([]byte{0x80}), "8180"},
+		{"dog", EncodeBytes([]byte("dog")), "83646f67"},
+		{"zero", EncodeUint(0), "80"},
+		{"fifteen", EncodeUint(15), "0f"},
+		{"1024", EncodeUint(1024), "820400"},
+		{"big int", EncodeBigInt(big.NewInt(1024)), "820400"},
+		{"empty list", EncodeList(), "c0"},
+		{"cat dog", EncodeList(EncodeBytes([]byte("cat")), EncodeBytes([]byte("dog"))), "c88363617483646f67"},
+		{"long string", EncodeBytes(bytes.Repeat([]byte{'a'}, 56)), "b838" + hex.EncodeToString(bytes.Repeat([]byte{'a'}, 56))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, hex.EncodeToString(tt.encoded))
+		})
+	}
+}
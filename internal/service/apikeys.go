@@ -0,0 +1,193 @@
+package service
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"ethereum-tx-parser/internal/model"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+)
+
+// apiKeyPrefix starts every issued key, so leaked keys are easy to scan for
+const apiKeyPrefix = "txp_"
+
+var (
+	// RequireAPIKey rejects requests without a valid API key when enabled. When disabled, requests
+	// without a key act for the default tenant and read only the addresses it subscribes to.
+	RequireAPIKey = false
+
+	// AdminToken authorizes the key management endpoints; empty disables them
+	AdminToken = ""
+
+	// APIKeyStatePath is the file API keys are persisted to; empty keeps them in memory only
+	APIKeyStatePath = ""
+
+	// ErrAPIKeyNotFound is returned for an unknown API key ID
+	ErrAPIKeyNotFound = errors.New("API key not found")
+
+	// ErrInvalidTenant is returned when a tenant name is malformed
+	ErrInvalidTenant = errors.New("tenant must be 1-64 lowercase letters, digits, '-' or '_'")
+)
+
+var (
+	apiKeyMu sync.Mutex
+	apiKeys  = make(map[string]model.APIKey) // By ID
+)
+
+var tenantPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// IssueAPIKey creates a key for a tenant and returns its record along with the key itself,
+// which is not stored and cannot be retrieved again
+func IssueAPIKey(tenant string, name string) (model.APIKey, string, error) {
+	if !tenantPattern.MatchString(tenant) {
+		return model.APIKey{}, "", ErrInvalidTenant
+	}
+
+	key := apiKeyPrefix + randomID(24)
+	record := model.APIKey{
+		ID:        randomID(8),
+		Tenant:    tenant,
+		Name:      name,
+		Prefix:    key[:len(apiKeyPrefix)+6],
+		Hash:      hashAPIKey(key),
+		CreatedAt: time.Now().UTC(),
+	}
+
+	apiKeyMu.Lock()
+	defer apiKeyMu.Unlock()
+	apiKeys[record.ID] = record
+	saveAPIKeyState()
+
+	record.Hash = ""
+	return record, key, nil
+}
+
+// GetAPIKeys returns the issued keys, oldest first and without their hashes, optionally only
+// those of one tenant
+func GetAPIKeys(tenant string) []model.APIKey {
+	apiKeyMu.Lock()
+	defer apiKeyMu.Unlock()
+
+	list := make([]model.APIKey, 0, len(apiKeys))
+	for _, record := range apiKeys {
+		if tenant != "" && record.Tenant != tenant {
+			continue
+		}
+		record.Hash = ""
+		list = append(list, record)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].CreatedAt.Equal(list[j].CreatedAt) {
+			return list[i].ID < list[j].ID
+		}
+		return list[i].CreatedAt.Before(list[j].CreatedAt)
+	})
+	return list
+}
+
+// RevokeAPIKey stops a key from authenticating. The record is kept so the revocation can be audited.
+func RevokeAPIKey(id string) error {
+	apiKeyMu.Lock()
+	defer apiKeyMu.Unlock()
+
+	record, ok := apiKeys[id]
+	if !ok {
+		return ErrAPIKeyNotFound
+	}
+	if record.RevokedAt == nil {
+		now := time.Now().UTC()
+		record.RevokedAt = &now
+		apiKeys[id] = record
+		saveAPIKeyState()
+	}
+	return nil
+}
+
+// AuthenticateAPIKey returns the record of a key, without its hash, if the key was issued and is
+// not revoked
+func AuthenticateAPIKey(key string) (model.APIKey, bool) {
+	hash := hashAPIKey(key)
+
+	apiKeyMu.Lock()
+	defer apiKeyMu.Unlock()
+	for _, record := range apiKeys {
+		if record.RevokedAt == nil && subtle.ConstantTimeCompare([]byte(record.Hash), []byte(hash)) == 1 {
+			record.Hash = ""
+			return record, true
+		}
+	}
+	return model.APIKey{}, false
+}
+
+// IsAdminToken reports whether token is the configured admin token
+func IsAdminToken(token string) bool {
+	return AdminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(AdminToken)) == 1
+}
+
+// hashAPIKey returns the stored form of a key. Keys are long and random, so a plain SHA-256 is
+// enough to make the stored hashes useless to anyone who reads them.
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// LoadAPIKeys restores API keys from APIKeyStatePath, if it exists
+func LoadAPIKeys() error {
+	if APIKeyStatePath == "" {
+		return nil
+	}
+	data, err := os.ReadFile(APIKeyStatePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var records []model.APIKey
+	if err := json.Unmarshal(data, &records); err != nil {
+		return fmt.Errorf("reading %s: %w", APIKeyStatePath, err)
+	}
+
+	apiKeyMu.Lock()
+	defer apiKeyMu.Unlock()
+	apiKeys = make(map[string]model.APIKey)
+	for _, record := range records {
+		apiKeys[record.ID] = record
+	}
+	return nil
+}
+
+// saveAPIKeyState writes API keys to APIKeyStatePath, replacing the file atomically.
+// apiKeyMu must be held.
+func saveAPIKeyState() {
+	if APIKeyStatePath == "" {
+		return
+	}
+
+	records := make([]model.APIKey, 0, len(apiKeys))
+	for _, record := range apiKeys {
+		records = append(records, record)
+	}
+	data, err := json.Marshal(records)
+	if err != nil {
+		authLog.Error("encoding API keys failed", "error", err)
+		return
+	}
+
+	tmp := APIKeyStatePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		authLog.Error("writing API keys failed", "path", tmp, "error", err)
+		return
+	}
+	if err := os.Rename(tmp, APIKeyStatePath); err != nil {
+		authLog.Error("replacing API keys failed", "path", APIKeyStatePath, "error", err)
+	}
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"ethereum-tx-parser/internal/model"
+)
+
+// resetAPIKeys clears issued keys
+func resetAPIKeys(t *testing.T) {
+	apiKeyMu.Lock()
+	apiKeys = make(map[string]model.APIKey)
+	apiKeyMu.Unlock()
+}
+
+func TestIssueAndRevokeAPIKey(t *testing.T) {
+	resetAPIKeys(t)
+
+	if _, _, err := IssueAPIKey("Not A Tenant", ""); err != ErrInvalidTenant {
+		t.Errorf("expected ErrInvalidTenant, got: %v", err)
+	}
+
+	record, key, err := IssueAPIKey("acme", "ci")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if record.Hash != "" || record.Prefix != key[:len(record.Prefix)] {
+		t.Errorf("expected a record without its hash and with the key prefix, got: %+v", record)
+	}
+
+	if authenticated, ok := AuthenticateAPIKey(key); !ok || authenticated.Tenant != "acme" || authenticated.ID != record.ID {
+		t.Errorf("expected key to authenticate as acme, got: %+v, %v", authenticated, ok)
+	}
+	if _, ok := AuthenticateAPIKey(key + "x"); ok {
+		t.Errorf("expected an unknown key to be rejected")
+	}
+
+	if err := RevokeAPIKey(record.ID); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, ok := AuthenticateAPIKey(key); ok {
+		t.Errorf("expected a revoked key to be rejected")
+	}
+	if err := RevokeAPIKey("missing"); err != ErrAPIKeyNotFound {
+		t.Errorf("expected ErrAPIKeyNotFound, got: %v", err)
+	}
+
+	keys := GetAPIKeys("acme")
+	if len(keys) != 1 || keys[0].RevokedAt == nil || keys[0].Hash != "" {
+		t.Errorf("expected the revoked key to be listed without its hash, got: %+v", keys)
+	}
+}
+
+func TestAPIKeyStatePersists(t *testing.T) {
+	resetAPIKeys(t)
+	APIKeyStatePath = filepath.Join(t.TempDir(), "keys.json")
+	defer func() { APIKeyStatePath = "" }()
+
+	_, key, _ := IssueAPIKey("acme", "")
+
+	resetAPIKeys(t)
+	if err := LoadAPIKeys(); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if authenticated, ok := AuthenticateAPIKey(key); !ok || authenticated.Tenant != "acme" {
+		t.Errorf("expected key to survive a reload, got: %+v, %v", authenticated, ok)
+	}
+}
+
+func TestTenantIsolation(t *testing.T) {
+	InitializeModelLayer()
+	acme := ParserFor(WithTenant(context.Background(), "acme"))
+	other := ParserFor(WithTenant(context.Background(), "other"))
+
+	if ok, err := acme.Subscribe(subscribedAddress); !ok || err != nil {
+		t.Fatalf("expected subscription, got: %v, %v", ok, err)
+	}
+	if _, err := other.GetTransactions(subscribedAddress); err != ErrNotSubscribed {
+		t.Errorf("expected ErrNotSubscribed for another tenant, got: %v", err)
+	}
+	if _, err := acme.GetTransactions(subscribedAddress); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+	keyless := ParserFor(context.Background())
+	if keyless.CanRead(subscribedAddress) || keyless.Subscriptions()[subscribedAddress] {
+		t.Errorf("expected requests without a key to only see the default tenant's subscriptions")
+	}
+	keyless.Subscribe(subscribedAddress)
+	if !keyless.CanRead(subscribedAddress) {
+		t.Errorf("expected requests without a key to read the default tenant's subscriptions")
+	}
+
+	// A second tenant subscribing the same address is independent of the first
+	other.Subscribe(subscribedAddress)
+	acme.Unsubscribe(subscribedAddress)
+	if acme.CanRead(subscribedAddress) || !other.CanRead(subscribedAddress) {
+		t.Errorf("expected unsubscribing to affect only its own tenant")
+	}
+}
+
+func TestTenantWebhookFilter(t *testing.T) {
+	InitializeModelLayer()
+	SubscribeTenant("acme", subscribedAddress)
+	event := model.Event{Type: model.EventTransaction, Address: subscribedAddress}
+
+	if !webhookMatches(model.Webhook{Tenant: "acme"}, event) {
+		t.Errorf("expected a tenant webhook to receive events of its subscriptions")
+	}
+	if webhookMatches(model.Webhook{Tenant: "other"}, event) {
+		t.Errorf("expected a tenant webhook not to receive events of other tenants")
+	}
+	if !webhookMatches(model.Webhook{Tenant: "other"}, model.Event{Type: model.EventBlock}) {
+		t.Errorf("expected block events to reach every tenant")
+	}
+}
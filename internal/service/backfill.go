@@ -0,0 +1,326 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"ethereum-tx-parser/internal/model"
+	"ethereum-tx-parser/internal/tracing"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	// MaxConcurrentBackfills is how many backfill jobs scan blocks at once; later jobs wait queued
+	MaxConcurrentBackfills = 2
+
+	// MaxQueuedBackfills is how many backfill jobs may wait for a free slot
+	MaxQueuedBackfills = 100
+
+	// MaxBackfillBlocks is the most blocks one backfill may cover
+	MaxBackfillBlocks = uint64(10000)
+
+	// ErrBackfillNotFound is returned for a backfill job that does not exist or belongs to another tenant
+	ErrBackfillNotFound = errors.New("backfill job not found")
+
+	// ErrBackfillQueueFull is returned when MaxQueuedBackfills jobs are already waiting
+	ErrBackfillQueueFull = errors.New("too many backfills queued")
+)
+
+var (
+	backfillMu     sync.Mutex
+	backfills      = make(map[string]*model.BackfillJob)
+	backfillQueue  []*model.BackfillJob // Jobs waiting for one of the MaxConcurrentBackfills slots
+	backfillActive int                  // Jobs scanning blocks
+
+	// A block that fails to fetch is tried backfillAttempts times, waiting backfillRetryDelay
+	// before the first retry and twice as long before each later one
+	backfillAttempts   = 3
+	backfillRetryDelay = time.Second
+
+	// Finished jobs stay listed for backfillRetention, and only the backfillHistory most recent
+	backfillRetention = time.Hour
+	backfillHistory   = 1000
+)
+
+// StartBackfill schedules a job that scans the blocks processed before now, from fromBlock or,
+// when fromTimestamp is set, from the first block mined at or after it, for the transactions of an
+// address the tenant subscribes to. The live cursor carries on meanwhile. It returns false
+// without a job when there are no such blocks, and the queued or running job instead of a new
+// one while the address already has one. A backfill may cover at most MaxBackfillBlocks blocks.
+func StartBackfill(tenant string, address string, fromBlock uint64, fromTimestamp int64) (model.BackfillJob, bool, error) {
+	if err := ValidateAddress(address); err != nil {
+		return model.BackfillJob{}, false, err
+	}
+	address = strings.ToLower(address)
+	if !model.SharedStore().GetTenantSubscriptions(tenant)[address] {
+		return model.BackfillJob{}, false, ErrNotSubscribed
+	}
+
+	// Wait out a running cycle, which may have read the subscriptions before this one was added,
+	// so every block from the cursor on is processed with the address subscribed
+	cycleMu.Lock()
+	current, err := currentCursor()
+	cycleMu.Unlock()
+	if err != nil {
+		return model.BackfillJob{}, false, err
+	}
+	if current == 0 || (fromTimestamp == 0 && fromBlock >= current) {
+		return model.BackfillJob{}, false, nil
+	}
+	if fromTimestamp == 0 {
+		if err := checkBackfillRange(fromBlock, current); err != nil {
+			return model.BackfillJob{}, false, err
+		}
+	}
+
+	job := &model.BackfillJob{
+		ID:            randomID(8),
+		Tenant:        tenant,
+		Address:       address,
+		FromBlock:     fromBlock,
+		FromTimestamp: fromTimestamp,
+		ToBlock:       current - 1,
+		State:         model.TaskQueued,
+		NextBlock:     fromBlock,
+		CreatedAt:     time.Now().UTC(),
+	}
+	backfillMu.Lock()
+	defer backfillMu.Unlock()
+	pruneBackfills(time.Now())
+	for _, existing := range backfills {
+		if existing.Tenant == tenant && existing.Address == address && backfillActiveState(existing.State) {
+			return *existing, true, nil
+		}
+	}
+	if len(backfillQueue) >= MaxQueuedBackfills {
+		return model.BackfillJob{}, false, ErrBackfillQueueFull
+	}
+	backfills[job.ID] = job
+	backfillQueue = append(backfillQueue, job)
+	processorLog.Info("backfill scheduled", "job", job.ID, "address", address, "from", fromBlock, "from_timestamp", fromTimestamp, "to", job.ToBlock)
+	startQueuedBackfills()
+	return *job, true, nil
+}
+
+// CheckBackfillStart reports whether a backfill from fromBlock would cover at most
+// MaxBackfillBlocks of the processed blocks, so callers can reject it before subscribing
+func CheckBackfillStart(fromBlock uint64) error {
+	current, err := currentCursor()
+	if err != nil || fromBlock >= current {
+		return err
+	}
+	return checkBackfillRange(fromBlock, current)
+}
+
+// checkBackfillRange checks the blocks from fromBlock up to the cursor fit in MaxBackfillBlocks
+func checkBackfillRange(fromBlock uint64, current uint64) error {
+	if current-fromBlock > MaxBackfillBlocks {
+		return fmt.Errorf("%w: a backfill may cover at most %d blocks, start at block %d or later", ErrInvalidBlockRange, MaxBackfillBlocks, current-MaxBackfillBlocks)
+	}
+	return nil
+}
+
+// backfillActiveState reports whether a job in state is yet to finish
+func backfillActiveState(state string) bool {
+	return state == model.TaskQueued || state == model.TaskRunning
+}
+
+// startQueuedBackfills starts queued jobs while slots are free. backfillMu must be held.
+func startQueuedBackfills() {
+	for backfillActive < max(MaxConcurrentBackfills, 1) && len(backfillQueue) > 0 {
+		job := backfillQueue[0]
+		backfillQueue = backfillQueue[1:]
+		backfillActive++
+		job.State = model.TaskRunning
+		go runBackfill(job)
+	}
+}
+
+// pruneBackfills drops finished jobs older than backfillRetention, and the oldest finished jobs
+// beyond backfillHistory. backfillMu must be held.
+func pruneBackfills(now time.Time) {
+	var finished []*model.BackfillJob
+	for id, job := range backfills {
+		if job.FinishedAt == nil {
+			continue
+		}
+		if now.Sub(*job.FinishedAt) > backfillRetention {
+			delete(backfills, id)
+			continue
+		}
+		finished = append(finished, job)
+	}
+	if len(finished) <= backfillHistory {
+		return
+	}
+	sort.Slice(finished, func(i, j int) bool { return finished[i].FinishedAt.Before(*finished[j].FinishedAt) })
+	for _, job := range finished[:len(finished)-backfillHistory] {
+		delete(backfills, job.ID)
+	}
+}
+
+// GetBackfillJobs returns the backfill jobs of a tenant, or of every tenant when it is empty,
+// oldest first
+func GetBackfillJobs(tenant string) []model.BackfillJob {
+	backfillMu.Lock()
+	defer backfillMu.Unlock()
+	jobs := []model.BackfillJob{}
+	for _, job := range backfills {
+		if tenant == "" || job.Tenant == tenant {
+			jobs = append(jobs, *job)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		if jobs[i].CreatedAt.Equal(jobs[j].CreatedAt) {
+			return jobs[i].ID < jobs[j].ID
+		}
+		return jobs[i].CreatedAt.Before(jobs[j].CreatedAt)
+	})
+	return jobs
+}
+
+// GetBackfillJob returns a backfill job of a tenant, or of any tenant when it is empty
+func GetBackfillJob(tenant string, id string) (model.BackfillJob, error) {
+	backfillMu.Lock()
+	defer backfillMu.Unlock()
+	job, ok := backfills[id]
+	if !ok || (tenant != "" && job.Tenant != tenant) {
+		return model.BackfillJob{}, ErrBackfillNotFound
+	}
+	return *job, nil
+}
+
+// runBackfill runs a job in one of the MaxConcurrentBackfills slots, then frees the slot for the
+// next queued job
+func runBackfill(job *model.BackfillJob) {
+	backfillMu.Lock()
+	tenant, address, from, to, fromTimestamp := job.Tenant, job.Address, job.FromBlock, job.ToBlock, job.FromTimestamp
+	backfillMu.Unlock()
+
+	ctx, span := processorTracer.Start(context.Background(), "backfill", trace.WithAttributes(
+		attribute.String("backfill.id", job.ID),
+		attribute.String("backfill.address", address),
+	))
+	state, err := model.TaskDone, error(nil)
+	defer func() {
+		backfillMu.Lock()
+		job.State = state
+		if err != nil {
+			job.Error = err.Error()
+		}
+		finished := time.Now().UTC()
+		job.FinishedAt = &finished
+		backfillActive--
+		startQueuedBackfills()
+		backfillMu.Unlock()
+		tracing.End(span, err)
+		processorLog.InfoContext(ctx, "backfill finished", "job", job.ID, "address", address, "state", state)
+	}()
+
+	if fromTimestamp != 0 {
+		if from, err = backfillStartAt(ctx, fromTimestamp, to); err != nil {
+			state = model.TaskFailed
+			return
+		}
+		backfillMu.Lock()
+		job.FromBlock, job.NextBlock = from, from
+		backfillMu.Unlock()
+	}
+	span.SetAttributes(attribute.Int64("backfill.from_block", int64(from)), attribute.Int64("backfill.to_block", int64(to)))
+
+	addressMap := map[string]bool{address: true}
+	for block := from; block <= to; block++ {
+		if !model.SharedStore().GetTenantSubscriptions(tenant)[address] {
+			state = model.TaskCanceled
+			return
+		}
+		matched, blockErr := backfillBlock(ctx, block, addressMap)
+		backfillMu.Lock()
+		job.Transactions += matched
+		if blockErr == nil {
+			job.NextBlock = block + 1
+		}
+		backfillMu.Unlock()
+		if blockErr != nil {
+			state, err = model.TaskFailed, fmt.Errorf("block %d: %w", block, blockErr)
+			return
+		}
+	}
+}
+
+// backfillBlock fetches one block, retrying failed fetches, and stores its transactions involving
+// the addresses in addressMap
+func backfillBlock(ctx context.Context, block uint64, addressMap map[string]bool) (int, error) {
+	blockHex := fmt.Sprintf("0x%x", block)
+	delay := backfillRetryDelay
+	for attempt := 1; ; attempt++ {
+		fetched, err := GetEthBlockByNumberContext(ctx, blockHex)
+		if err == nil {
+			return filterTransactions(ctx, fetched.Transactions, addressMap)
+		}
+		if attempt == backfillAttempts {
+			return 0, err
+		}
+		processorLog.WarnContext(ctx, "backfill fetch failed, retrying", "block", blockHex, "attempt", attempt, "error", err)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// backfillStartAt returns the first block mined at or after timestamp among the last
+// MaxBackfillBlocks blocks up to last, or an ErrInvalidBlockRange error when an earlier block
+// qualifies too, since the backfill would then cover more than MaxBackfillBlocks blocks
+func backfillStartAt(ctx context.Context, timestamp int64, last uint64) (uint64, error) {
+	first := uint64(0)
+	if last >= MaxBackfillBlocks {
+		first = last + 1 - MaxBackfillBlocks
+	}
+	from, err := blockAtTimestamp(ctx, timestamp, first, last)
+	if err != nil || from > first || first == 0 {
+		return from, err
+	}
+	minedAt, err := blockTimestamp(ctx, first-1)
+	if err != nil {
+		return 0, err
+	}
+	if minedAt >= uint64(timestamp) {
+		return 0, checkBackfillRange(first-1, last+1)
+	}
+	return from, nil
+}
+
+// blockAtTimestamp binary searches blocks first through last for the first one mined at or after
+// timestamp, returning last+1 if there is none
+func blockAtTimestamp(ctx context.Context, timestamp int64, first uint64, last uint64) (uint64, error) {
+	lo, hi := first, last+1
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		minedAt, err := blockTimestamp(ctx, mid)
+		if err != nil {
+			return 0, err
+		}
+		if minedAt >= uint64(timestamp) {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	return lo, nil
+}
+
+// blockTimestamp returns when a block was mined, fetching its header only
+func blockTimestamp(ctx context.Context, block uint64) (uint64, error) {
+	var header struct {
+		Timestamp string `json:"timestamp"`
+	}
+	if err := callRPCContext(ctx, "eth_getBlockByNumber", []interface{}{fmt.Sprintf("0x%x", block), false}, &header); err != nil {
+		return 0, err
+	}
+	return parseHexUint(header.Timestamp)
+}
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"ethereum-tx-parser/internal/model"
+)
+
+// serveTimedBlocks answers eth_getBlockByNumber for blocks mined every 12 seconds, each with one
+// transaction from processorSender; headers are returned without transactions
+func serveTimedBlocks(t *testing.T) *int {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Params []interface{} `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		number, _ := req.Params[0].(string)
+		block, _ := strconv.ParseUint(strings.TrimPrefix(number, "0x"), 16, 64)
+		timestamp := "0x" + strconv.FormatUint(block*12, 16)
+		calls++
+		if full, _ := req.Params[1].(bool); !full {
+			json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "result": map[string]string{"number": number, "timestamp": timestamp}})
+			return
+		}
+		result := model.Block{Number: number, Timestamp: timestamp, Transactions: []model.Transaction{
+			{Hash: "0xbb" + number[2:], From: processorSender, To: processorRecipient, BlockNumber: number},
+		}}
+		json.NewEncoder(w).Encode(model.JsonRPCResponse{JsonRPC: "2.0", ID: 1, Result: result})
+	}))
+	t.Cleanup(server.Close)
+	useRPCServer(t, server.URL)
+	return &calls
+}
+
+// waitForBackfill waits until a backfill job is no longer queued or running
+func waitForBackfill(t *testing.T, id string) model.BackfillJob {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		job, err := GetBackfillJob("", id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.State != model.TaskQueued && job.State != model.TaskRunning {
+			return job
+		}
+	}
+	t.Fatal("backfill did not finish")
+	return model.BackfillJob{}
+}
+
+func TestBackfillFromBlock(t *testing.T) {
+	InitializeModelLayer()
+	serveTimedBlocks(t)
+	SaveLatestBlock("0x20")
+
+	if _, _, err := StartBackfill(model.DefaultTenant, processorSender, 0x1c, 0); !errors.Is(err, ErrNotSubscribed) {
+		t.Errorf("expected an unsubscribed address to be rejected, got: %v", err)
+	}
+	Subscribe(processorSender)
+	if _, scheduled, err := StartBackfill(model.DefaultTenant, processorSender, 0x20, 0); err != nil || scheduled {
+		t.Errorf("expected nothing to backfill from the cursor, got: %v, %v", scheduled, err)
+	}
+
+	job, scheduled, err := StartBackfill(model.DefaultTenant, processorSender, 0x1c, 0)
+	if err != nil || !scheduled {
+		t.Fatalf("expected a backfill job, got: %v, %v", scheduled, err)
+	}
+	if job.ToBlock != 0x1f || job.Address != processorSender {
+		t.Errorf("expected a job up to block 0x1f, got: %+v", job)
+	}
+
+	job = waitForBackfill(t, job.ID)
+	if job.State != model.TaskDone || job.NextBlock != 0x20 || job.Transactions != 4 || job.FinishedAt == nil {
+		t.Errorf("expected a finished job with 4 transactions, got: %+v", job)
+	}
+	if transactions := model.SharedStore().GetTransactions(processorSender); len(transactions) != 4 {
+		t.Errorf("expected 4 stored transactions, got: %d", len(transactions))
+	}
+	if current, _ := GetBlockNumber(); current != "0x20" {
+		t.Errorf("expected the live cursor to stay at 0x20, got: %s", current)
+	}
+}
+
+func TestBackfillFromTimestamp(t *testing.T) {
+	InitializeModelLayer()
+	serveTimedBlocks(t)
+	SaveLatestBlock("0x400")
+	Subscribe(processorSender)
+
+	// Block 0x3fd is mined at 12252, the first block at or after 12250
+	job, scheduled, err := StartBackfill(model.DefaultTenant, processorSender, 0, 12250)
+	if err != nil || !scheduled {
+		t.Fatalf("expected a backfill job, got: %v, %v", scheduled, err)
+	}
+	job = waitForBackfill(t, job.ID)
+	if job.State != model.TaskDone || job.FromBlock != 0x3fd || job.Transactions != 3 {
+		t.Errorf("expected a job from block 0x3fd with 3 transactions, got: %+v", job)
+	}
+
+	// A timestamp after every processed block leaves nothing to scan
+	job, _, _ = StartBackfill(model.DefaultTenant, processorSender, 0, 1<<40)
+	if job = waitForBackfill(t, job.ID); job.State != model.TaskDone || job.Transactions != 0 {
+		t.Errorf("expected an empty finished job, got: %+v", job)
+	}
+}
+
+func TestBackfillTenantsAndFailures(t *testing.T) {
+	InitializeModelLayer()
+	useRPCServer(t, "http://127.0.0.1:1")
+	previous := backfillRetryDelay
+	backfillRetryDelay = time.Millisecond
+	t.Cleanup(func() { backfillRetryDelay = previous })
+	SaveLatestBlock("0x20")
+	SubscribeTenant("acme", processorSender)
+
+	job, _, err := StartBackfill("acme", processorSender, 0x10, 0)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := GetBackfillJob("other", job.ID); !errors.Is(err, ErrBackfillNotFound) {
+		t.Errorf("expected another tenant's job to be hidden, got: %v", err)
+	}
+	for _, listed := range GetBackfillJobs("other") {
+		if listed.ID == job.ID {
+			t.Error("expected another tenant's job to be left out of its list")
+		}
+	}
+
+	job = waitForBackfill(t, job.ID)
+	if job.State != model.TaskFailed || job.NextBlock != 0x10 || !strings.Contains(job.Error, "block 16") {
+		t.Errorf("expected a job failed at block 16, got: %+v", job)
+	}
+}
+
+func TestBackfillLimits(t *testing.T) {
+	InitializeModelLayer()
+	calls := serveTimedBlocks(t)
+	previous := MaxBackfillBlocks
+	MaxBackfillBlocks = 0x10
+	t.Cleanup(func() { MaxBackfillBlocks = previous })
+	SaveLatestBlock("0x400")
+	Subscribe(processorSender)
+
+	if _, _, err := StartBackfill(model.DefaultTenant, processorSender, 0, 0); !errors.Is(err, ErrInvalidBlockRange) {
+		t.Errorf("expected a backfill from genesis to exceed the range, got: %v", err)
+	}
+	if err := CheckBackfillStart(0x3f0); err != nil {
+		t.Errorf("expected the last 0x10 blocks to fit, got: %v", err)
+	}
+	if err := CheckBackfillStart(0x3ef); !errors.Is(err, ErrInvalidBlockRange) {
+		t.Errorf("expected 0x11 blocks to exceed the range, got: %v", err)
+	}
+
+	// Only the last 0x10 headers are searched, plus one to tell an earlier start apart
+	before := *calls
+	job, _, _ := StartBackfill(model.DefaultTenant, processorSender, 0, 12)
+	if job = waitForBackfill(t, job.ID); job.State != model.TaskFailed || !strings.Contains(job.Error, "at most 16 blocks") {
+		t.Errorf("expected a timestamp too far back to fail, got: %+v", job)
+	}
+	if searched := *calls - before; searched > 7 {
+		t.Errorf("expected a bounded header search, got %d calls", searched)
+	}
+}
+
+func TestBackfillQueue(t *testing.T) {
+	InitializeModelLayer()
+	useRPCServer(t, "http://127.0.0.1:1")
+	previousDelay, previousQueued, previousConcurrent := backfillRetryDelay, MaxQueuedBackfills, MaxConcurrentBackfills
+	backfillRetryDelay, MaxQueuedBackfills, MaxConcurrentBackfills = 50*time.Millisecond, 1, 1
+	t.Cleanup(func() {
+		backfillRetryDelay, MaxQueuedBackfills, MaxConcurrentBackfills = previousDelay, previousQueued, previousConcurrent
+	})
+	SaveLatestBlock("0x20")
+	SubscribeTenant("acme", processorSender)
+	SubscribeTenant("acme", processorRecipient)
+	SubscribeTenant("other", processorSender)
+
+	running, _, err := StartBackfill("acme", processorSender, 0x10, 0)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if again, scheduled, err := StartBackfill("acme", processorSender, 0x18, 0); err != nil || !scheduled || again.ID != running.ID {
+		t.Errorf("expected the running job to be returned, got: %+v, %v", again, err)
+	}
+	queued, _, err := StartBackfill("other", processorSender, 0x10, 0)
+	if err != nil || queued.State != model.TaskQueued {
+		t.Errorf("expected another tenant's job to be queued, got: %+v, %v", queued, err)
+	}
+	if _, _, err := StartBackfill("acme", processorRecipient, 0x10, 0); !errors.Is(err, ErrBackfillQueueFull) {
+		t.Errorf("expected a full queue to be refused, got: %v", err)
+	}
+
+	waitForBackfill(t, running.ID)
+	if job := waitForBackfill(t, queued.ID); job.State != model.TaskFailed {
+		t.Errorf("expected the queued job to run once a slot freed, got: %+v", job)
+	}
+}
+
+func TestPruneBackfills(t *testing.T) {
+	backfillMu.Lock()
+	defer backfillMu.Unlock()
+	previous, previousHistory := backfills, backfillHistory
+	defer func() { backfills, backfillHistory = previous, previousHistory }()
+
+	now := time.Now()
+	at := func(age time.Duration) *time.Time {
+		finished := now.Add(-age)
+		return &finished
+	}
+	backfills = map[string]*model.BackfillJob{
+		"expired": {ID: "expired", State: model.TaskDone, FinishedAt: at(2 * backfillRetention)},
+		"older":   {ID: "older", State: model.TaskFailed, FinishedAt: at(2 * time.Minute)},
+		"newer":   {ID: "newer", State: model.TaskDone, FinishedAt: at(time.Minute)},
+		"running": {ID: "running", State: model.TaskRunning},
+	}
+	backfillHistory = 1
+	pruneBackfills(now)
+	if len(backfills) != 2 || backfills["newer"] == nil || backfills["running"] == nil {
+		t.Errorf("expected only the newest finished job and the running one to remain, got: %v", backfills)
+	}
+}
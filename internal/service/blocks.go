@@ -0,0 +1,59 @@
+package service
+
+import (
+	"ethereum-tx-parser/internal/model"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// reorgWindow is how many recent block hashes are remembered for reorg detection
+const reorgWindow = 128
+
+var (
+	processedMu     sync.Mutex
+	processedHashes = make(map[uint64]string) // Hashes of recently processed blocks by number
+)
+
+// RecordProcessedBlock publishes a block event once a block has been handled by the processing
+// loop. If the block's parent differs from the block processed at the previous height, the
+// chain has reorganised and a reorg event is published first.
+func RecordProcessedBlock(block model.Block) {
+	number, err := parseHexUint(block.Number)
+	if err != nil {
+		return
+	}
+
+	processedMu.Lock()
+	previous, known := processedHashes[number-1]
+	processedHashes[number] = strings.ToLower(block.Hash)
+	delete(processedHashes, number-reorgWindow)
+	processedMu.Unlock()
+	blocksProcessed.Inc()
+	recordBlockProcessed()
+
+	if known && block.ParentHash != "" && !strings.EqualFold(previous, block.ParentHash) {
+		processorLog.Warn("reorg detected", "block", fmt.Sprintf("0x%x", number-1), "old_hash", previous, "new_hash", block.ParentHash)
+		publishEvent(model.Event{Type: model.EventReorg, Data: model.Reorg{
+			Number:  fmt.Sprintf("0x%x", number-1),
+			OldHash: previous,
+			NewHash: strings.ToLower(block.ParentHash),
+		}})
+	}
+
+	publishEvent(model.Event{Type: model.EventBlock, Data: model.BlockSummary{
+		Number:           block.Number,
+		Hash:             block.Hash,
+		ParentHash:       block.ParentHash,
+		Timestamp:        block.Timestamp,
+		TransactionCount: len(block.Transactions),
+	}})
+}
+
+// ProcessedBlockHash returns the hash of a recently processed block, if it is still remembered
+func ProcessedBlockHash(number uint64) (string, bool) {
+	processedMu.Lock()
+	defer processedMu.Unlock()
+	hash, ok := processedHashes[number]
+	return hash, ok
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"testing"
+
+	"ethereum-tx-parser/internal/model"
+)
+
+func TestRecordProcessedBlockDetectsReorg(t *testing.T) {
+	events, unsubscribe := SubscribeEvents(10)
+	defer unsubscribe()
+
+	RecordProcessedBlock(model.Block{Number: "0x100", Hash: "0xaaa"})
+	RecordProcessedBlock(model.Block{Number: "0x101", Hash: "0xbbb", ParentHash: "0xaaa"})
+	RecordProcessedBlock(model.Block{Number: "0x102", Hash: "0xccc", ParentHash: "0xfff"})
+
+	var types []string
+	var reorg model.Reorg
+	for len(events) > 0 {
+		event := <-events
+		types = append(types, event.Type)
+		if event.Type == model.EventReorg {
+			reorg = event.Data.(model.Reorg)
+		}
+	}
+
+	expected := []string{model.EventBlock, model.EventBlock, model.EventReorg, model.EventBlock}
+	if len(types) != len(expected) {
+		t.Fatalf("expected events %v, got: %v", expected, types)
+	}
+	for i := range expected {
+		if types[i] != expected[i] {
+			t.Errorf("expected events %v, got: %v", expected, types)
+		}
+	}
+	if reorg != (model.Reorg{Number: "0x101", OldHash: "0xbbb", NewHash: "0xfff"}) {
+		t.Errorf("unexpected reorg payload: %+v", reorg)
+	}
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"encoding/hex"
+	"errors"
+	"ethereum-tx-parser/internal/crypto"
+	"ethereum-tx-parser/internal/model"
+	"ethereum-tx-parser/internal/rlp"
+	"ethereum-tx-parser/internal/trie"
+	"fmt"
+	"strings"
+)
+
+// VerifyBlocks enables checking every fetched block against its transactions root and header hash
+var VerifyBlocks = false
+
+// ErrBlockVerification is returned when a fetched block does not match its own commitments
+var ErrBlockVerification = errors.New("block verification failed")
+
+// VerifyBlock recomputes the transactions trie root and the header hash of a block and compares
+// them with the transactionsRoot and hash reported by the RPC provider
+func VerifyBlock(block model.Block) error {
+	encoded := make([][]byte, len(block.Transactions))
+	for i, tx := range block.Transactions {
+		var err error
+		if encoded[i], err = encodeTransaction(tx); err != nil {
+			return fmt.Errorf("transaction %s: %v", tx.Hash, err)
+		}
+	}
+	root := "0x" + hex.EncodeToString(trie.DeriveListRoot(encoded))
+	if !strings.EqualFold(root, block.TransactionsRoot) {
+		return fmt.Errorf("transactions root mismatch: reported %s, computed %s", block.TransactionsRoot, root)
+	}
+
+	hash, err := headerHash(block)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(hash, block.Hash) {
+		return fmt.Errorf("header hash mismatch: reported %s, computed %s", block.Hash, hash)
+	}
+	return nil
+}
+
+// headerHash computes the Keccak-256 hash of the RLP-encoded block header. Fields introduced by
+// later forks are appended only when the block carries them.
+func headerHash(block model.Block) (string, error) {
+	e := &txEncoder{}
+	fields := [][]byte{
+		e.bytes(block.ParentHash), e.bytes(block.Sha3Uncles), e.bytes(block.Miner), e.bytes(block.StateRoot),
+		e.bytes(block.TransactionsRoot), e.bytes(block.ReceiptsRoot), e.bytes(block.LogsBloom), e.quantity(block.Difficulty),
+		e.quantity(block.Number), e.quantity(block.GasLimit), e.quantity(block.GasUsed), e.quantity(block.Timestamp),
+		e.bytes(block.ExtraData), e.bytes(block.MixHash), e.bytes(block.Nonce),
+	}
+
+	optional := []struct {
+		value    string
+		quantity bool
+	}{
+		{block.BaseFeePerGas, true},
+		{block.WithdrawalsRoot, false},
+		{block.BlobGasUsed, true},
+		{block.ExcessBlobGas, true},
+		{block.ParentBeaconBlockRoot, false},
+		{block.RequestsHash, false},
+	}
+	for _, field := range optional {
+		if field.value == "" {
+			break
+		}
+		if field.quantity {
+			fields = append(fields, e.quantity(field.value))
+		} else {
+			fields = append(fields, e.bytes(field.value))
+		}
+	}
+
+	if e.err != nil {
+		return "", e.err
+	}
+	return "0x" + hex.EncodeToString(crypto.Keccak256(rlp.EncodeList(fields...))), nil
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ethereum-tx-parser/internal/model"
+	"ethereum-tx-parser/internal/trie"
+)
+
+const zeroHash = "0x0000000000000000000000000000000000000000000000000000000000000000"
+
+// genesisBlock is the Ethereum mainnet genesis block
+func genesisBlock() model.Block {
+	return model.Block{
+		Number:           "0x0",
+		Hash:             "0xd4e56740f876aef8c010b86a40d5f56745a118d0906a34e69aec8c0db1cb8fa3",
+		ParentHash:       zeroHash,
+		Sha3Uncles:       "0x1dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d49347",
+		Miner:            "0x0000000000000000000000000000000000000000",
+		StateRoot:        "0xd7f8974fb5ac78d9ac099b9ad5018bedc2ce0a72dad1827a1709da30580f0544",
+		TransactionsRoot: "0x56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421",
+		ReceiptsRoot:     "0x56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421",
+		LogsBloom:        "0x" + strings.Repeat("00", 256),
+		Difficulty:       "0x400000000",
+		GasLimit:         "0x1388",
+		GasUsed:          "0x0",
+		Timestamp:        "0x0",
+		ExtraData:        "0x11bbe8db4e347b4e8c937c1c8370e4b5ed33adb3db69cbdb7a38e1e50b1b82fa",
+		MixHash:          zeroHash,
+		Nonce:            "0x0000000000000042",
+	}
+}
+
+// blockWithTransaction returns a London-style block containing the EIP-155 example transaction
+// with a consistent transactions root and header hash
+func blockWithTransaction(t *testing.T) model.Block {
+	block := genesisBlock()
+	block.Number = "0x10d4f"
+	block.BaseFeePerGas = "0x7"
+	block.Transactions = []model.Transaction{eip155Transaction()}
+
+	encoded, err := encodeTransaction(block.Transactions[0])
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	block.TransactionsRoot = "0x" + hex.EncodeToString(trie.DeriveListRoot([][]byte{encoded}))
+	if block.Hash, err = headerHash(block); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	return block
+}
+
+func TestVerifyBlock(t *testing.T) {
+	if err := VerifyBlock(genesisBlock()); err != nil {
+		t.Errorf("expected genesis block to verify, got: %v", err)
+	}
+	if err := VerifyBlock(blockWithTransaction(t)); err != nil {
+		t.Errorf("expected block to verify, got: %v", err)
+	}
+
+	tamperedTx := blockWithTransaction(t)
+	tamperedTx.Transactions[0].To = "0xabcdefabcdefabcdefabcdefabcdefabcdefabcd"
+	if err := VerifyBlock(tamperedTx); err == nil || !strings.Contains(err.Error(), "transactions root") {
+		t.Errorf("expected transactions root mismatch, got: %v", err)
+	}
+
+	droppedTx := blockWithTransaction(t)
+	droppedTx.Transactions = nil
+	if err := VerifyBlock(droppedTx); err == nil {
+		t.Errorf("expected omitted transaction to be detected")
+	}
+
+	tamperedHeader := genesisBlock()
+	tamperedHeader.Timestamp = "0x1"
+	if err := VerifyBlock(tamperedHeader); err == nil || !strings.Contains(err.Error(), "header hash") {
+		t.Errorf("expected header hash mismatch, got: %v", err)
+	}
+}
+
+func TestAdvancePastBlockRequiresVerifiedBlock(t *testing.T) {
+	block := blockWithTransaction(t)
+	tampered := blockWithTransaction(t)
+	tampered.Transactions[0].Value = "0x1"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(model.JsonRPCResponse{JsonRPC: "2.0", ID: 1, Result: block})
+	}))
+	defer server.Close()
+	useRPCServer(t, server.URL)
+
+	mockStore := &MockStore{subscriptions: make(map[string]bool)}
+	model.InitializeStore(mockStore)
+	mockStore.SaveBlock(block.Number)
+	VerifyBlocks = true
+	defer func() { VerifyBlocks = false }()
+
+	if err := AdvancePastBlock(block); !errors.Is(err, ErrBlockVerification) {
+		t.Fatalf("expected advancing past a block that was not verified to be refused, got: %v", err)
+	}
+	fetched, err := GetEthBlockByNumber(block.Number)
+	if err != nil || !fetched.Verified {
+		t.Fatalf("expected a verified block, got: %v, %v", fetched.Verified, err)
+	}
+
+	// Another fetch failing verification in between, as a backfill or reprocess may, changes nothing
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(model.JsonRPCResponse{JsonRPC: "2.0", ID: 1, Result: tampered})
+	})
+	if _, err := GetEthBlockByNumber(block.Number); !errors.Is(err, ErrBlockVerification) {
+		t.Fatalf("expected verification error, got: %v", err)
+	}
+
+	if err := AdvancePastBlock(fetched); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	number, _ := parseHexUint(block.Number)
+	if blockNum, _ := mockStore.GetCurrentBlock(); blockNum != fmt.Sprintf("0x%x", number+1) {
+		t.Errorf("expected cursor to advance past %s, got: %s", block.Number, blockNum)
+	}
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"ethereum-tx-parser/internal/model"
+	"sync"
+)
+
+// EventHistorySize is how many recent events are kept for listeners resuming after a disconnect
+var EventHistorySize = 10000
+
+var (
+	eventMu        sync.RWMutex
+	eventListeners = make(map[chan model.Event]struct{})
+	eventSeq       uint64        // Sequence number of the last published event
+	eventHistory   []model.Event // Most recent events, oldest first
+)
+
+// SubscribeEvents registers a listener for published events. The returned function must be
+// called to unregister it. A listener whose buffer fills up is dropped and its channel closed,
+// so it can resume with EventsSince instead of silently missing events.
+func SubscribeEvents(buffer int) (<-chan model.Event, func()) {
+	ch := make(chan model.Event, buffer)
+
+	eventMu.Lock()
+	eventListeners[ch] = struct{}{}
+	eventMu.Unlock()
+
+	unsubscribe := func() {
+		eventMu.Lock()
+		defer eventMu.Unlock()
+		if _, ok := eventListeners[ch]; ok {
+			delete(eventListeners, ch)
+			close(ch)
+		}
+	}
+	return ch, unsubscribe
+}
+
+// EventsSince returns the retained events with a sequence number greater than seq
+func EventsSince(seq uint64) []model.Event {
+	eventMu.RLock()
+	defer eventMu.RUnlock()
+
+	var events []model.Event
+	for _, event := range eventHistory {
+		if event.Seq > seq {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// LastEventSeq returns the sequence number of the most recently published event
+func LastEventSeq() uint64 {
+	eventMu.RLock()
+	defer eventMu.RUnlock()
+	return eventSeq
+}
+
+// publishEvent assigns the next sequence number to an event, retains it, delivers it to
+// every registered listener without blocking and queues it for matching webhooks
+func publishEvent(event model.Event) {
+	eventMu.Lock()
+
+	eventSeq++
+	event.Seq = eventSeq
+	eventHistory = append(eventHistory, event)
+	if len(eventHistory) > EventHistorySize {
+		eventHistory = eventHistory[len(eventHistory)-EventHistorySize:]
+	}
+
+	for ch := range eventListeners {
+		select {
+		case ch <- event:
+		default:
+			eventLog.Warn("dropping slow event listener", "event", event.Type, "seq", event.Seq)
+			delete(eventListeners, ch)
+			close(ch)
+		}
+	}
+	eventMu.Unlock()
+
+	enqueueWebhookDeliveries(event)
+}
+
+// saveTransaction stores a transaction for an address and publishes it as a transaction event
+func saveTransaction(address string, tx model.Transaction) error {
+	if err := model.SharedStore().SaveTransaction(address, tx); err != nil {
+		return err
+	}
+	publishEvent(model.Event{Type: model.EventTransaction, Address: address, Data: tx})
+	return nil
+}
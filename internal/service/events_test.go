@@ -0,0 +1,35 @@
+package service
+
+import (
+	"testing"
+
+	"ethereum-tx-parser/internal/model"
+)
+
+func TestPublishEventSequenceAndHistory(t *testing.T) {
+	start := LastEventSeq()
+	for i := 0; i < 3; i++ {
+		publishEvent(model.Event{Type: model.EventTransaction})
+	}
+
+	events := EventsSince(start + 1)
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events after seq %d, got: %d", start+1, len(events))
+	}
+	if events[0].Seq != start+2 || events[1].Seq != start+3 {
+		t.Errorf("expected consecutive sequence numbers, got: %d, %d", events[0].Seq, events[1].Seq)
+	}
+}
+
+func TestSlowListenerIsDropped(t *testing.T) {
+	events, unsubscribe := SubscribeEvents(1)
+	defer unsubscribe()
+
+	publishEvent(model.Event{Type: model.EventTransaction})
+	publishEvent(model.Event{Type: model.EventTransaction})
+
+	<-events
+	if _, ok := <-events; ok {
+		t.Errorf("expected listener channel to be closed after overflowing")
+	}
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"ethereum-tx-parser/internal/model"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	// Version identifies the build in /status. Release builds set it with
+	// -ldflags "-X ethereum-tx-parser/internal/service.Version=v1.2.3".
+	Version = "dev"
+
+	// ReadyMaxLag is the most blocks the listener may trail the chain head by and still be ready
+	ReadyMaxLag = int64(100)
+
+	// ReadyRPCWindow is how recently a call to the node must have succeeded for it to count as
+	// reachable. The listener polls the node every few seconds, so a quiet window means it is down.
+	ReadyRPCWindow = 30 * time.Second
+)
+
+var (
+	startedAt = time.Now()
+
+	syncMu          sync.Mutex
+	lastRPCSuccess  time.Time
+	lastBlockAt     time.Time
+	lastSyncError   string
+	lastSyncErrorAt time.Time
+)
+
+// recordRPCSuccess notes that the node answered a call
+func recordRPCSuccess() {
+	syncMu.Lock()
+	defer syncMu.Unlock()
+	lastRPCSuccess = time.Now()
+}
+
+// recordBlockProcessed notes that the listener finished a block
+func recordBlockProcessed() {
+	syncMu.Lock()
+	defer syncMu.Unlock()
+	lastBlockAt = time.Now()
+}
+
+// RecordSyncError notes why the block processing loop failed to make progress, for /status
+func RecordSyncError(err error) {
+	syncMu.Lock()
+	defer syncMu.Unlock()
+	lastSyncError, lastSyncErrorAt = err.Error(), time.Now()
+}
+
+// GetSyncStatus reports how far the listener has come and how its node connection is doing
+func GetSyncStatus() model.SyncStatus {
+	status := model.SyncStatus{
+		Version:        Version,
+		StartedAt:      startedAt.UTC(),
+		UptimeSeconds:  int64(time.Since(startedAt).Seconds()),
+		ProcessedBlock: processedBlock(),
+	}
+	if head := chainHead.Load(); head >= 0 {
+		lag := max(head-status.ProcessedBlock, 0)
+		status.HeadBlock, status.Lag = &head, &lag
+	}
+
+	status.Paused = ProcessorPaused()
+
+	syncMu.Lock()
+	defer syncMu.Unlock()
+	status.LastRPCSuccess = timePointer(lastRPCSuccess)
+	status.LastBlockProcessedAt = timePointer(lastBlockAt)
+	if lastSyncError != "" {
+		status.LastError = lastSyncError
+		status.LastErrorAt = timePointer(lastSyncErrorAt)
+	}
+	return status
+}
+
+// CheckReadiness runs the readiness checks: the store answers, the node answered recently, and
+// the listener is within ReadyMaxLag blocks of the chain head
+func CheckReadiness() (bool, map[string]model.HealthCheck) {
+	checks := map[string]model.HealthCheck{
+		"storage": checkStorage(),
+		"rpc":     checkRPC(),
+		"lag":     checkLag(),
+	}
+	ready := true
+	for _, check := range checks {
+		ready = ready && check.OK
+	}
+	return ready, checks
+}
+
+func checkStorage() model.HealthCheck {
+	if model.SharedStore() == nil {
+		return model.HealthCheck{Detail: "storage is not initialized"}
+	}
+	if _, err := model.SharedStore().GetCurrentBlock(); err != nil {
+		return model.HealthCheck{Detail: err.Error()}
+	}
+	return model.HealthCheck{OK: true}
+}
+
+func checkRPC() model.HealthCheck {
+	syncMu.Lock()
+	last := lastRPCSuccess
+	syncMu.Unlock()
+
+	if last.IsZero() {
+		return model.HealthCheck{Detail: "no successful call to the node yet"}
+	}
+	if age := time.Since(last); age > ReadyRPCWindow {
+		return model.HealthCheck{Detail: fmt.Sprintf("last successful call to the node was %s ago", age.Round(time.Second))}
+	}
+	return model.HealthCheck{OK: true}
+}
+
+func checkLag() model.HealthCheck {
+	head := chainHead.Load()
+	if head < 0 {
+		return model.HealthCheck{Detail: "chain head not known yet"}
+	}
+	if lag := head - processedBlock(); lag > ReadyMaxLag {
+		return model.HealthCheck{Detail: fmt.Sprintf("%d blocks behind the chain head, more than %d", lag, ReadyMaxLag)}
+	}
+	return model.HealthCheck{OK: true}
+}
+
+// timePointer returns nil for the zero time, so it is omitted from JSON
+func timePointer(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	t = t.UTC()
+	return &t
+}
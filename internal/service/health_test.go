@@ -0,0 +1,74 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// resetSyncState forgets the chain head and node activity seen by earlier tests
+func resetSyncState(t *testing.T) {
+	chainHead.Store(-1)
+	syncMu.Lock()
+	lastRPCSuccess, lastBlockAt, lastSyncError, lastSyncErrorAt = time.Time{}, time.Time{}, "", time.Time{}
+	syncMu.Unlock()
+}
+
+func TestCheckReadiness(t *testing.T) {
+	resetSyncState(t)
+	InitializeModelLayer()
+	SaveLatestBlock("0x10")
+
+	ready, checks := CheckReadiness()
+	if ready || !checks["storage"].OK || checks["rpc"].OK || checks["lag"].OK {
+		t.Errorf("expected only storage to pass before the node answered, got: %+v", checks)
+	}
+
+	head := "0x20"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"` + head + `"}`))
+	}))
+	defer server.Close()
+	useRPCServer(t, server.URL)
+
+	GetLatestETHBlock()
+	if ready, checks := CheckReadiness(); !ready {
+		t.Errorf("expected ready 16 blocks behind, got: %+v", checks)
+	}
+
+	head = "0x1000"
+	GetLatestETHBlock()
+	if ready, checks := CheckReadiness(); ready || checks["lag"].OK || checks["lag"].Detail == "" {
+		t.Errorf("expected the lag check to fail, got: %+v", checks)
+	}
+
+	ReadyRPCWindow = 0
+	defer func() { ReadyRPCWindow = 30 * time.Second }()
+	if _, checks := CheckReadiness(); checks["rpc"].OK {
+		t.Errorf("expected the rpc check to fail once the last answer is too old")
+	}
+}
+
+func TestGetSyncStatus(t *testing.T) {
+	resetSyncState(t)
+	InitializeModelLayer()
+	SaveLatestBlock("0x10")
+
+	status := GetSyncStatus()
+	if status.Version != Version || status.ProcessedBlock != 16 || status.HeadBlock != nil || status.Lag != nil || status.LastRPCSuccess != nil {
+		t.Errorf("expected only the processed block before the node answered, got: %+v", status)
+	}
+
+	chainHead.Store(20)
+	recordRPCSuccess()
+	RecordSyncError(errors.New("node unreachable"))
+	status = GetSyncStatus()
+	if status.HeadBlock == nil || *status.HeadBlock != 20 || status.Lag == nil || *status.Lag != 4 {
+		t.Errorf("expected head 20 and lag 4, got: %+v", status)
+	}
+	if status.LastRPCSuccess == nil || status.LastError != "node unreachable" || status.LastErrorAt == nil {
+		t.Errorf("expected the last RPC success and error to be reported, got: %+v", status)
+	}
+}
@@ -0,0 +1,13 @@
+package service
+
+import "ethereum-tx-parser/internal/logging"
+
+// Loggers of the service components, whose levels can be set separately
+var (
+	processorLog = logging.For("processor")
+	rpcLog       = logging.For("rpc")
+	mempoolLog   = logging.For("mempool")
+	eventLog     = logging.For("events")
+	webhookLog   = logging.For("webhooks")
+	authLog      = logging.For("auth")
+)
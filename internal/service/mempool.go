@@ -0,0 +1,139 @@
+package service
+
+import (
+	"errors"
+	"ethereum-tx-parser/internal/model"
+	"strings"
+	"time"
+)
+
+// GetTxPoolContent retrieves the pending and queued transactions from the node's mempool via RPC
+func GetTxPoolContent() (model.TxPoolContent, error) {
+	var content model.TxPoolContent
+	if err := callRPC("txpool_content", []interface{}{}, &content); err != nil {
+		return model.TxPoolContent{}, err
+	}
+	return content, nil
+}
+
+// GetTransactionByHash retrieves a transaction by its hash via RPC.
+// The boolean result is false if the node does not know the transaction.
+func GetTransactionByHash(txHash string) (model.Transaction, bool, error) {
+	var tx model.Transaction
+	if err := callRPC("eth_getTransactionByHash", []interface{}{txHash}, &tx); err != nil {
+		if errors.Is(err, errNoResult) {
+			return model.Transaction{}, false, nil
+		}
+		return model.Transaction{}, false, err
+	}
+	return tx, true, nil
+}
+
+// PollPendingTransactions stores mempool transactions involving subscribed addresses as pending
+// and marks previously pending transactions that left the mempool without being mined as dropped
+func PollPendingTransactions() error {
+	addressMap := model.SharedStore().GetAllSubscriptions()
+	if len(addressMap) == 0 {
+		return nil
+	}
+
+	content, err := GetTxPoolContent()
+	if err != nil {
+		return err
+	}
+
+	// Queued transactions are not yet executable (e.g. behind a nonce gap) but are still pending
+	index := buildPendingIndex(addressMap)
+	inPool := make(map[string]bool)
+	for _, byNonce := range mergePool(content) {
+		for _, tx := range byNonce {
+			inPool[tx.Hash] = true
+			if len(matchedAddresses(addressMap, tx)) > 0 && !verifyMatchedTransaction(&tx) {
+				continue
+			}
+			if err := applyReplacements(index, &tx); err != nil {
+				return err
+			}
+			for _, address := range matchedAddresses(addressMap, tx) {
+				if err := savePendingTransaction(address, tx); err != nil {
+					mempoolLog.Error("saving pending transaction failed", "tx", tx.Hash, "address", address, "error", err)
+					return err
+				}
+			}
+		}
+	}
+
+	return dropMissingPendingTransactions(addressMap, inPool)
+}
+
+// mergePool combines the pending and queued sections of the mempool
+func mergePool(content model.TxPoolContent) []map[string]model.Transaction {
+	var pool []map[string]model.Transaction
+	for _, byNonce := range content.Pending {
+		pool = append(pool, byNonce)
+	}
+	for _, byNonce := range content.Queued {
+		pool = append(pool, byNonce)
+	}
+	return pool
+}
+
+// matchedAddresses returns the subscribed addresses a transaction is sent from or to
+func matchedAddresses(addressMap map[string]bool, tx model.Transaction) []string {
+	var addresses []string
+	lowerFrom := strings.ToLower(tx.From)
+	lowerTo := strings.ToLower(tx.To)
+	if addressMap[lowerFrom] {
+		addresses = append(addresses, lowerFrom)
+	}
+	if addressMap[lowerTo] && lowerFrom != lowerTo {
+		addresses = append(addresses, lowerTo)
+	}
+	return addresses
+}
+
+// savePendingTransaction stores a mempool transaction unless it is already known for the address,
+// so that a transaction already marked mined is never downgraded back to pending
+func savePendingTransaction(address string, tx model.Transaction) error {
+	tx.Status = model.TxStatusPending
+	tx.SeenAt = time.Now().Unix()
+	added, err := model.SharedStore().AddTransaction(address, tx)
+	if err != nil || !added {
+		return err
+	}
+	publishEvent(model.Event{Type: model.EventTransaction, Address: address, Data: tx})
+	return nil
+}
+
+// dropMissingPendingTransactions marks pending transactions absent from the mempool as dropped
+// once the node no longer knows about them
+func dropMissingPendingTransactions(addressMap map[string]bool, inPool map[string]bool) error {
+	for address := range addressMap {
+		for _, tx := range model.SharedStore().GetTransactions(address) {
+			if tx.Status != model.TxStatusPending || inPool[tx.Hash] {
+				continue
+			}
+
+			_, known, err := GetTransactionByHash(tx.Hash)
+			if err != nil {
+				return err
+			}
+			// A known transaction has been mined and will be picked up by the block processing loop
+			if known {
+				continue
+			}
+
+			// The block loop may have stored the transaction as mined since it was read
+			dropped, updated, err := model.SharedStore().UpdateTransactionStatus(address, tx.Hash, model.TxStatusPending, model.TxStatusDropped)
+			if err != nil {
+				return err
+			}
+			if !updated {
+				continue
+			}
+			publishEvent(model.Event{Type: model.EventTransaction, Address: address, Data: dropped})
+			mempoolLog.Info("pending transaction dropped", "tx", tx.Hash, "address", address)
+		}
+	}
+	return nil
+}
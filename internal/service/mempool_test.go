@@ -0,0 +1,135 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ethereum-tx-parser/internal/model"
+	"ethereum-tx-parser/internal/parser"
+)
+
+const subscribedAddress = "0x1234567890abcdef1234567890abcdef12345678"
+
+// mockMempoolHandler serves txpool_content with the given pool and reports every other transaction as unknown
+func mockMempoolHandler(pool model.TxPoolContent) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req parser.RpcRequest
+		json.NewDecoder(r.Body).Decode(&req)
+
+		if req.Method == "txpool_content" {
+			json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "result": pool})
+			return
+		}
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":null}`))
+	}
+}
+
+func TestPollPendingTransactions(t *testing.T) {
+	pendingTx := model.Transaction{Hash: "0xaaa", From: subscribedAddress, To: "0xabcdefabcdefabcdefabcdefabcdefabcdefabcd", Nonce: "0x5"}
+	server := httptest.NewServer(mockMempoolHandler(model.TxPoolContent{
+		Pending: map[string]map[string]model.Transaction{subscribedAddress: {"5": pendingTx}},
+	}))
+	defer server.Close()
+	useRPCServer(t, server.URL)
+
+	mockStore := &MockStore{subscriptions: map[string]bool{subscribedAddress: true}}
+	model.InitializeStore(mockStore)
+
+	if err := PollPendingTransactions(); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(mockStore.transactions) != 1 || mockStore.transactions[0].Status != model.TxStatusPending {
+		t.Fatalf("expected one pending transaction, got: %+v", mockStore.transactions)
+	}
+
+	// The transaction leaves the mempool and the node no longer knows it
+	server.Config.Handler = mockMempoolHandler(model.TxPoolContent{})
+	if err := PollPendingTransactions(); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if mockStore.transactions[0].Status != model.TxStatusDropped {
+		t.Errorf("expected status %s, got: %s", model.TxStatusDropped, mockStore.transactions[0].Status)
+	}
+}
+
+func TestPollPendingTransactionsKeepsMined(t *testing.T) {
+	InitializeModelLayer()
+	Subscribe(subscribedAddress)
+	pendingTx := model.Transaction{Hash: "0xaaa", From: subscribedAddress, Nonce: "0x5"}
+	minedTx := model.Transaction{Hash: "0xbbb", From: subscribedAddress, Nonce: "0x6", Status: model.TxStatusMined, BlockNumber: "0x10"}
+	model.SharedStore().SaveTransaction(subscribedAddress, minedTx)
+
+	server := httptest.NewServer(mockMempoolHandler(model.TxPoolContent{
+		Pending: map[string]map[string]model.Transaction{subscribedAddress: {"5": pendingTx, "6": minedTx}},
+	}))
+	defer server.Close()
+	useRPCServer(t, server.URL)
+
+	// A transaction in both the pool and a processed block stays mined
+	if err := PollPendingTransactions(); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	// The block loop stores the pending transaction as mined while the poller asks the node about
+	// it, after the poller has read it as pending
+	mock := mockMempoolHandler(model.TxPoolContent{})
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req parser.RpcRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Method == "eth_getTransactionByHash" {
+			mined := pendingTx
+			mined.Status = model.TxStatusMined
+			mined.BlockNumber = "0x11"
+			model.SharedStore().SaveTransaction(subscribedAddress, mined)
+		}
+		body, _ := json.Marshal(req)
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		mock(w, r)
+	})
+	if err := PollPendingTransactions(); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	for _, tx := range model.SharedStore().GetTransactions(subscribedAddress) {
+		if tx.Status != model.TxStatusMined {
+			t.Errorf("expected transaction %s to stay mined, got: %s", tx.Hash, tx.Status)
+		}
+	}
+}
+
+func TestFilterTransactionsTransitionsPending(t *testing.T) {
+	mockStore := &MockStore{
+		subscriptions: map[string]bool{subscribedAddress: true},
+		transactions: []model.Transaction{
+			{Hash: "0xaaa", From: subscribedAddress, Nonce: "0x5", Status: model.TxStatusPending},
+			{Hash: "0xbbb", From: subscribedAddress, Nonce: "0x6", Status: model.TxStatusPending},
+		},
+	}
+	model.InitializeStore(mockStore)
+
+	block := []model.Transaction{
+		{Hash: "0xccc", From: subscribedAddress, To: subscribedAddress, Value: "0x1", Nonce: "0x5"},
+		{Hash: "0xbbb", From: subscribedAddress, Nonce: "0x6"},
+	}
+	if err := FilterTransactionsByAddress(block); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	expected := map[string]string{
+		"0xaaa": model.TxStatusReplaced,
+		"0xbbb": model.TxStatusMined,
+		"0xccc": model.TxStatusMined,
+	}
+	for _, tx := range mockStore.transactions {
+		if tx.Status != expected[tx.Hash] {
+			t.Errorf("expected transaction %s to be %s, got: %s", tx.Hash, expected[tx.Hash], tx.Status)
+		}
+		if tx.Hash == "0xaaa" && (tx.ReplacedBy != "0xccc" || tx.ReplacementType != model.ReplacementSpeedUp) {
+			t.Errorf("expected 0xaaa to be sped up by 0xccc, got: %+v", tx)
+		}
+	}
+}
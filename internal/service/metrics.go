@@ -0,0 +1,126 @@
+package service
+
+import (
+	"errors"
+	"ethereum-tx-parser/internal/metrics"
+	"ethereum-tx-parser/internal/model"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// storageBuckets are histogram upper bounds in seconds for in-memory store operations
+var storageBuckets = []float64{.000001, .000005, .00001, .00005, .0001, .0005, .001, .005, .01, .05, .1}
+
+var (
+	rpcDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "txparser_rpc_request_duration_seconds",
+		Help:    "Duration of JSON-RPC calls to the Ethereum node by method and outcome (ok, node_error, no_result or error).",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "outcome"})
+
+	blocksProcessed = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "txparser_blocks_processed_total",
+		Help: "Blocks processed by the listener.",
+	})
+
+	matchedTransactions = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "txparser_matched_transactions_total",
+		Help: "Mined transactions involving a subscribed address.",
+	})
+
+	storageDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "txparser_storage_operation_duration_seconds",
+		Help:    "Duration of storage operations by operation.",
+		Buckets: storageBuckets,
+	}, []string{"operation"})
+
+	// chainHead is the latest block number reported by the node, or -1 before the first report
+	chainHead atomic.Int64
+)
+
+func init() {
+	chainHead.Store(-1)
+
+	err := metrics.Register(rpcDuration, blocksProcessed, matchedTransactions, storageDuration,
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "txparser_processed_block",
+			Help: "Number of the next block the listener will process.",
+		}, func() float64 {
+			return float64(processedBlock())
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "txparser_chain_head_block",
+			Help: "Latest block number reported by the Ethereum node.",
+		}, func() float64 {
+			return float64(chainHead.Load())
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "txparser_block_lag",
+			Help: "Blocks between the chain head and the next block to process.",
+		}, func() float64 {
+			if head := chainHead.Load(); head >= 0 {
+				return float64(max(head-processedBlock(), 0))
+			}
+			return 0
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "txparser_subscriptions",
+			Help: "Addresses subscribed by any tenant.",
+		}, func() float64 {
+			if model.SharedStore() == nil {
+				return 0
+			}
+			return float64(len(model.SharedStore().GetAllSubscriptions()))
+		}),
+	)
+	if err != nil {
+		processorLog.Error("registering metrics failed", "error", err)
+	}
+
+	model.StorageObserver = func(operation string, elapsed time.Duration) {
+		metrics.Observe(storageDuration, elapsed.Seconds(), operation)
+	}
+}
+
+// processedBlock returns the block number the listener is at, or 0 before it has one
+func processedBlock() int64 {
+	if model.SharedStore() == nil {
+		return 0
+	}
+	blockHex, err := model.SharedStore().GetCurrentBlock()
+	if err != nil {
+		return 0
+	}
+	number, err := parseHexUint(blockHex)
+	if err != nil {
+		return 0
+	}
+	return int64(number)
+}
+
+// recordChainHead remembers the latest block number reported by the node
+func recordChainHead(blockHex string) {
+	if number, err := parseHexUint(blockHex); err == nil {
+		chainHead.Store(int64(number))
+	}
+}
+
+// observeRPC records the duration and outcome of a call to the node, and when it answered
+func observeRPC(method string, start time.Time, err error) {
+	var nodeErr *rpcError
+	outcome := "ok"
+	switch {
+	case err == nil:
+		recordRPCSuccess()
+	case errors.As(err, &nodeErr):
+		outcome = "node_error"
+	case errors.Is(err, errNoResult):
+		outcome = "no_result"
+		recordRPCSuccess()
+	default:
+		outcome = "error"
+	}
+	metrics.Observe(rpcDuration, time.Since(start).Seconds(), method, outcome)
+}
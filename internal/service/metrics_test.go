@@ -0,0 +1,70 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ethereum-tx-parser/internal/model"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// histogramCount returns how many values a histogram series has observed
+func histogramCount(vec *prometheus.HistogramVec, labelValues ...string) uint64 {
+	var m dto.Metric
+	vec.WithLabelValues(labelValues...).(prometheus.Histogram).Write(&m)
+	return m.GetHistogram().GetSampleCount()
+}
+
+func TestRPCMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x20"}`))
+	}))
+	defer server.Close()
+	useRPCServer(t, server.URL)
+	InitializeModelLayer()
+	SaveLatestBlock("0x1a")
+
+	before := histogramCount(rpcDuration, "eth_blockNumber", "ok")
+	if _, err := GetLatestETHBlock(); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if got := histogramCount(rpcDuration, "eth_blockNumber", "ok"); got != before+1 {
+		t.Errorf("expected one more successful eth_blockNumber call, got: %d", got-before)
+	}
+	if head, processed := chainHead.Load(), processedBlock(); head != 32 || processed != 26 {
+		t.Errorf("expected head 32 and processed block 26, got: %d, %d", head, processed)
+	}
+
+	useRPCServer(t, "http://127.0.0.1:1")
+	before = histogramCount(rpcDuration, "eth_blockNumber", "error")
+	GetLatestETHBlock()
+	if got := histogramCount(rpcDuration, "eth_blockNumber", "error"); got != before+1 {
+		t.Errorf("expected one more failed eth_blockNumber call, got: %d", got-before)
+	}
+}
+
+func TestProcessingMetrics(t *testing.T) {
+	InitializeModelLayer()
+	Subscribe(subscribedAddress)
+
+	matched, processed := testutil.ToFloat64(matchedTransactions), testutil.ToFloat64(blocksProcessed)
+	FilterTransactionsByAddress([]model.Transaction{
+		{Hash: "0x01", From: subscribedAddress, To: "0x0000000000000000000000000000000000000002"},
+		{Hash: "0x02", From: "0x0000000000000000000000000000000000000003", To: "0x0000000000000000000000000000000000000002"},
+	})
+	RecordProcessedBlock(model.Block{Number: "0x1"})
+
+	if got := testutil.ToFloat64(matchedTransactions) - matched; got != 1 {
+		t.Errorf("expected 1 matched transaction, got: %v", got)
+	}
+	if got := testutil.ToFloat64(blocksProcessed) - processed; got != 1 {
+		t.Errorf("expected 1 processed block, got: %v", got)
+	}
+	if histogramCount(storageDuration, "save_transaction") == 0 {
+		t.Errorf("expected storage operations to be timed")
+	}
+}
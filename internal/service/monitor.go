@@ -0,0 +1,188 @@
+package service
+
+import (
+	"ethereum-tx-parser/internal/model"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StuckAfter is how long a transaction may stay pending before it is reported as stuck
+var StuckAfter = 10 * time.Minute
+
+var (
+	alertsMu sync.RWMutex
+	alerts   = make(map[string][]model.Alert) // Latest findings per subscribed address
+)
+
+// GetTransactionCount retrieves the number of transactions sent by an address at the given block tag via RPC
+func GetTransactionCount(address string, blockTag string) (uint64, error) {
+	var count string
+	if err := callRPC("eth_getTransactionCount", []interface{}{address, blockTag}, &count); err != nil {
+		return 0, err
+	}
+	return parseHexUint(count)
+}
+
+// GetBaseFee retrieves the base fee per gas of the latest block via RPC
+func GetBaseFee() (*big.Int, error) {
+	var header struct {
+		BaseFeePerGas string `json:"baseFeePerGas"`
+	}
+	if err := callRPC("eth_getBlockByNumber", []interface{}{"latest", false}, &header); err != nil {
+		return nil, err
+	}
+	return parseHexBig(header.BaseFeePerGas), nil
+}
+
+// CheckPendingTransactions inspects the pending transactions of every subscribed sender for nonce
+// gaps, transactions pending longer than StuckAfter and fee caps below the current base fee.
+// New findings are published as alert events.
+func CheckPendingTransactions() ([]model.Alert, error) {
+	baseFee, err := GetBaseFee()
+	if err != nil {
+		return nil, err
+	}
+
+	var found []model.Alert
+	for address := range model.SharedStore().GetAllSubscriptions() {
+		addressAlerts, err := checkSender(address, baseFee, time.Now())
+		if err != nil {
+			return nil, err
+		}
+
+		alertsMu.Lock()
+		previous := alerts[address]
+		alerts[address] = addressAlerts
+		alertsMu.Unlock()
+
+		for _, alert := range addressAlerts {
+			if !containsAlert(previous, alert) {
+				publishEvent(model.Event{Type: model.EventAlert, Address: address, Data: alert})
+			}
+		}
+		found = append(found, addressAlerts...)
+	}
+	return found, nil
+}
+
+// GetAlerts returns the latest findings for an address, or for all addresses if address is empty
+func GetAlerts(address string) []model.Alert {
+	alertsMu.RLock()
+	defer alertsMu.RUnlock()
+
+	if address != "" {
+		return append([]model.Alert(nil), alerts[strings.ToLower(address)]...)
+	}
+	var all []model.Alert
+	for _, addressAlerts := range alerts {
+		all = append(all, addressAlerts...)
+	}
+	return all
+}
+
+// checkSender evaluates the pending transactions sent by a single address
+func checkSender(address string, baseFee *big.Int, now time.Time) ([]model.Alert, error) {
+	var pending []model.Transaction
+	for _, tx := range model.SharedStore().GetTransactions(address) {
+		if tx.Status == model.TxStatusPending && strings.EqualFold(tx.From, address) {
+			pending = append(pending, tx)
+		}
+	}
+	if len(pending) == 0 {
+		return nil, nil
+	}
+
+	minedCount, err := GetTransactionCount(address, "latest")
+	if err != nil {
+		return nil, err
+	}
+	pendingCount, err := GetTransactionCount(address, "pending")
+	if err != nil {
+		return nil, err
+	}
+
+	var found []model.Alert
+	nonces := make(map[uint64]bool)
+	for _, tx := range pending {
+		nonce, err := parseHexUint(tx.Nonce)
+		if err != nil {
+			continue
+		}
+		nonces[nonce] = true
+
+		if tx.SeenAt > 0 && now.Sub(time.Unix(tx.SeenAt, 0)) > StuckAfter {
+			found = append(found, model.Alert{
+				Type: model.AlertStuck, Address: address, Nonce: nonce, TxHash: tx.Hash, DetectedAt: now,
+				Detail: fmt.Sprintf("pending since %s", time.Unix(tx.SeenAt, 0).UTC().Format(time.RFC3339)),
+			})
+		}
+
+		if feeCap := feeCap(tx); feeCap != nil && baseFee != nil && feeCap.Cmp(baseFee) < 0 {
+			found = append(found, model.Alert{
+				Type: model.AlertUnderpriced, Address: address, Nonce: nonce, TxHash: tx.Hash, DetectedAt: now,
+				Detail: fmt.Sprintf("fee cap %s wei is below base fee %s wei", feeCap, baseFee),
+			})
+		}
+	}
+
+	// Nonces between the node's executable pending count and the highest known pending nonce
+	// that nobody has submitted block every later transaction
+	var highest uint64
+	for nonce := range nonces {
+		if nonce > highest {
+			highest = nonce
+		}
+	}
+	start := pendingCount
+	if minedCount > start {
+		start = minedCount
+	}
+	for nonce := start; nonce < highest; nonce++ {
+		if !nonces[nonce] {
+			found = append(found, model.Alert{
+				Type: model.AlertNonceGap, Address: address, Nonce: nonce, DetectedAt: now,
+				Detail: fmt.Sprintf("nonce %d is missing; %d transaction(s) confirmed, highest pending nonce %d", nonce, minedCount, highest),
+			})
+		}
+	}
+
+	sort.Slice(found, func(i, j int) bool { return found[i].Nonce < found[j].Nonce })
+	return found, nil
+}
+
+// feeCap returns the maximum fee per gas a transaction is willing to pay
+func feeCap(tx model.Transaction) *big.Int {
+	if tx.MaxFeePerGas != "" {
+		return parseHexBig(tx.MaxFeePerGas)
+	}
+	return parseHexBig(tx.GasPrice)
+}
+
+// containsAlert reports whether an equivalent finding is already in the list
+func containsAlert(list []model.Alert, alert model.Alert) bool {
+	for _, existing := range list {
+		if existing.Type == alert.Type && existing.Nonce == alert.Nonce && existing.TxHash == alert.TxHash {
+			return true
+		}
+	}
+	return false
+}
+
+// parseHexUint parses a 0x-prefixed hex quantity
+func parseHexUint(value string) (uint64, error) {
+	return strconv.ParseUint(strings.TrimPrefix(value, "0x"), 16, 64)
+}
+
+// parseHexBig parses a 0x-prefixed hex quantity of arbitrary size, returning nil if it is invalid
+func parseHexBig(value string) *big.Int {
+	n, ok := new(big.Int).SetString(strings.TrimPrefix(value, "0x"), 16)
+	if !ok {
+		return nil
+	}
+	return n
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ethereum-tx-parser/internal/model"
+	"ethereum-tx-parser/internal/parser"
+)
+
+// mockMonitorHandler reports 3 mined transactions, 4 executable pending ones and a base fee of 100 wei
+func mockMonitorHandler(w http.ResponseWriter, r *http.Request) {
+	var req parser.RpcRequest
+	json.NewDecoder(r.Body).Decode(&req)
+
+	var result interface{}
+	switch req.Method {
+	case "eth_getTransactionCount":
+		result = "0x3"
+		if req.Params[1] == "pending" {
+			result = "0x4"
+		}
+	case "eth_getBlockByNumber":
+		result = map[string]string{"baseFeePerGas": "0x64"}
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "result": result})
+}
+
+func TestCheckPendingTransactions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(mockMonitorHandler))
+	defer server.Close()
+	useRPCServer(t, server.URL)
+
+	longAgo := time.Now().Add(-2 * StuckAfter).Unix()
+	mockStore := &MockStore{
+		subscriptions: map[string]bool{subscribedAddress: true},
+		transactions: []model.Transaction{
+			{Hash: "0xaaa", From: subscribedAddress, Nonce: "0x3", GasPrice: "0xc8", Status: model.TxStatusPending, SeenAt: longAgo},
+			{Hash: "0xbbb", From: subscribedAddress, Nonce: "0x6", MaxFeePerGas: "0x32", Status: model.TxStatusPending, SeenAt: time.Now().Unix()},
+			{Hash: "0xccc", From: subscribedAddress, Nonce: "0x2", Status: model.TxStatusMined},
+		},
+	}
+	model.InitializeStore(mockStore)
+
+	events, unsubscribe := SubscribeEvents(10)
+	defer unsubscribe()
+
+	found, err := CheckPendingTransactions()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	expected := []struct {
+		kind  string
+		nonce uint64
+	}{
+		{model.AlertStuck, 3},
+		{model.AlertNonceGap, 4},
+		{model.AlertNonceGap, 5},
+		{model.AlertUnderpriced, 6},
+	}
+	if len(found) != len(expected) {
+		t.Fatalf("expected %d alerts, got: %+v", len(expected), found)
+	}
+	for i, e := range expected {
+		if found[i].Type != e.kind || found[i].Nonce != e.nonce {
+			t.Errorf("expected alert %s for nonce %d, got: %+v", e.kind, e.nonce, found[i])
+		}
+	}
+
+	if len(events) != len(expected) {
+		t.Errorf("expected %d alert events, got: %d", len(expected), len(events))
+	}
+	if len(GetAlerts(subscribedAddress)) != len(expected) {
+		t.Errorf("expected alerts to be queryable by address")
+	}
+
+	// Unchanged findings are not published again
+	if _, err := CheckPendingTransactions(); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(events) != len(expected) {
+		t.Errorf("expected no new events, got: %d", len(events)-len(expected))
+	}
+}
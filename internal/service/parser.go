@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"ethereum-tx-parser/internal/model"
+	"ethereum-tx-parser/internal/parser"
+	"strconv"
+	"strings"
+)
+
+// LocalParser implements parser.Parser over the shared store of this process. It acts for its
+// Tenant, or the default tenant when Tenant is empty: subscriptions go to the tenant's namespace
+// and only addresses it subscribes to can be read.
+type LocalParser struct {
+	Tenant string
+}
+
+var _ parser.Parser = LocalParser{}
+
+// ParserFor returns a LocalParser acting for the tenant a request is authenticated as, if any
+func ParserFor(ctx context.Context) LocalParser {
+	tenant, _ := TenantFromContext(ctx)
+	return LocalParser{Tenant: tenant}
+}
+
+// GetCurrentBlock returns the last parsed block number
+func (LocalParser) GetCurrentBlock() (int, error) {
+	blockHex, err := GetBlockNumber()
+	if err != nil {
+		return 0, err
+	}
+	number, err := strconv.ParseInt(strings.TrimPrefix(blockHex, "0x"), 16, 64)
+	if err != nil {
+		return 0, err
+	}
+	return int(number), nil
+}
+
+// Subscribe adds an address to be observed for transactions
+func (p LocalParser) Subscribe(address string) (bool, error) {
+	return SubscribeTenant(p.tenant(), address)
+}
+
+// Unsubscribe stops observing an address
+func (p LocalParser) Unsubscribe(address string) (bool, error) {
+	return UnsubscribeTenant(p.tenant(), address)
+}
+
+// GetTransactions returns the stored transactions of an address
+func (p LocalParser) GetTransactions(address string) ([]model.Transaction, error) {
+	if err := p.checkReadable(address); err != nil {
+		return nil, err
+	}
+	return model.SharedStore().GetTransactions(strings.ToLower(address)), nil
+}
+
+// GetContracts returns the contracts deployed by an address
+func (p LocalParser) GetContracts(address string) ([]string, error) {
+	if err := p.checkReadable(address); err != nil {
+		return nil, err
+	}
+	return model.SharedStore().GetContracts(strings.ToLower(address)), nil
+}
+
+// GetAlerts returns the readable alerts of an address, or of every address when it is empty
+func (p LocalParser) GetAlerts(address string) []model.Alert {
+	var visible []model.Alert
+	for _, alert := range GetAlerts(address) {
+		if p.CanRead(alert.Address) {
+			visible = append(visible, alert)
+		}
+	}
+	return visible
+}
+
+// CanRead reports whether the parser may read the data of an address
+func (p LocalParser) CanRead(address string) bool {
+	return model.SharedStore().GetTenantSubscriptions(p.tenant())[strings.ToLower(address)]
+}
+
+// Subscriptions returns the subscribed addresses the parser may read
+func (p LocalParser) Subscriptions() map[string]bool {
+	return model.SharedStore().GetTenantSubscriptions(p.tenant())
+}
+
+// checkReadable validates an address and checks the parser may read it
+func (p LocalParser) checkReadable(address string) error {
+	if !isValidEthereumAddress(address) {
+		return ErrInvalidAddress
+	}
+	if !p.CanRead(address) {
+		return ErrNotSubscribed
+	}
+	return nil
+}
+
+// tenant returns the tenant whose subscriptions the parser reads and changes
+func (p LocalParser) tenant() string {
+	if p.Tenant == "" {
+		return model.DefaultTenant
+	}
+	return p.Tenant
+}
+
+// Backfill schedules a scan of past blocks for the transactions of an address the parser
+// subscribes to; see StartBackfill
+func (p LocalParser) Backfill(address string, fromBlock uint64, fromTimestamp int64) (model.BackfillJob, bool, error) {
+	return StartBackfill(p.tenant(), address, fromBlock, fromTimestamp)
+}
+
+// BackfillJobs returns the backfill jobs the parser may see
+func (p LocalParser) BackfillJobs() []model.BackfillJob {
+	return GetBackfillJobs(p.tenant())
+}
+
+// BackfillJob returns a backfill job the parser may see
+func (p LocalParser) BackfillJob(id string) (model.BackfillJob, error) {
+	return GetBackfillJob(p.tenant(), id)
+}
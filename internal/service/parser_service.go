@@ -1,21 +1,27 @@
 package service
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"errors"
+	"ethereum-tx-parser/internal/crypto"
 	"ethereum-tx-parser/internal/model"
-	"ethereum-tx-parser/internal/parser"
 	"fmt"
-	"io/ioutil"
-	"log"
-	"net/http"
 	"strconv"
 	"strings"
 )
 
-const (
+var (
+	// EthereumRPCURL is the JSON-RPC endpoint used for all chain queries
 	EthereumRPCURL = "https://ethereum-rpc.publicnode.com"
+
+	// AutoSubscribeContracts subscribes contracts deployed by subscribed addresses when enabled
+	AutoSubscribeContracts = false
+
+	// ErrInvalidAddress is returned when an address is not a 0x-prefixed 20-byte hex string
+	ErrInvalidAddress = errors.New("invalid Ethereum address")
+
+	// ErrNotSubscribed is returned when a tenant reads an address it does not subscribe to
+	ErrNotSubscribed = errors.New("address is not subscribed")
 )
 
 // InitializeModelLayer sets up the block storage model
@@ -24,6 +30,25 @@ func InitializeModelLayer() {
 	model.InitializeStore(blockStorage)
 }
 
+// LoadModelLayer initializes the store from the snapshot in dataDir, if there is one
+func LoadModelLayer(dataDir string) error {
+	blockStorage, err := model.LoadBlockStorage(model.StatePath(dataDir))
+	if err != nil {
+		return err
+	}
+	model.InitializeStore(blockStorage)
+	return nil
+}
+
+// SaveModelLayer writes a snapshot of the store to dataDir
+func SaveModelLayer(dataDir string) error {
+	snapshotter, ok := model.SharedStore().(interface{ SaveFile(path string) error })
+	if !ok {
+		return errors.New("store does not support snapshots")
+	}
+	return snapshotter.SaveFile(model.StatePath(dataDir))
+}
+
 // GetBlockNumber retrieves the current block number from the store
 func GetBlockNumber() (string, error) {
 	return model.SharedStore().GetCurrentBlock()
@@ -32,7 +57,7 @@ func GetBlockNumber() (string, error) {
 // SaveLatestBlock saves the latest block number to the store with error handling
 func SaveLatestBlock(currentBlockNum string) error {
 	if err := model.SharedStore().SaveBlock(currentBlockNum); err != nil {
-		log.Printf("Error saving block: %v", err)
+		processorLog.Error("saving block number failed", "block", currentBlockNum, "error", err)
 		return errors.New("error updating blocks")
 	}
 	return nil
@@ -40,130 +65,210 @@ func SaveLatestBlock(currentBlockNum string) error {
 
 // GetLatestETHBlock retrieves the latest Ethereum block number via RPC
 func GetLatestETHBlock() (string, error) {
-	requestPayload := parser.RpcRequest{
-		JsonRPC: "2.0",
-		Method:  "eth_blockNumber",
-		Params:  []interface{}{},
-		ID:      1,
-	}
-
-	payloadBytes, err := json.Marshal(requestPayload)
-	if err != nil {
-		log.Printf("Error marshaling request payload: %v", err)
-		return "0x0", err
-	}
-
-	resp, err := http.Post(EthereumRPCURL, "application/json", bytes.NewBuffer(payloadBytes))
-	if err != nil {
-		log.Printf("Error making RPC request: %v", err)
-		return "0x0", err
-	}
-	defer resp.Body.Close()
-
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		log.Printf("Error reading RPC response: %v", err)
-		return "0x0", err
-	}
+	return GetLatestETHBlockContext(context.Background())
+}
 
-	var rpcResp parser.RpcResponse
-	if err := json.Unmarshal(body, &rpcResp); err != nil {
-		log.Printf("Error unmarshaling RPC response: %v", err)
+// GetLatestETHBlockContext is GetLatestETHBlock with the RPC call traced under ctx
+func GetLatestETHBlockContext(ctx context.Context) (string, error) {
+	var blockNumber string
+	if err := callRPCContext(ctx, "eth_blockNumber", []interface{}{}, &blockNumber); err != nil {
 		return "0x0", err
 	}
-
-	return rpcResp.Result, nil
+	recordChainHead(blockNumber)
+	return blockNumber, nil
 }
 
 // GetEthBlockByNumber retrieves a block by its number via RPC
 func GetEthBlockByNumber(blockNumber string) (model.Block, error) {
+	return GetEthBlockByNumberContext(context.Background(), blockNumber)
+}
+
+// GetEthBlockByNumberContext is GetEthBlockByNumber with the RPC call traced under ctx
+func GetEthBlockByNumberContext(ctx context.Context, blockNumber string) (model.Block, error) {
 	if !strings.HasPrefix(blockNumber, "0x") {
 		return model.Block{}, fmt.Errorf("block number must be in hex format, e.g., '0x4b7'")
 	}
 
-	reqBody := map[string]interface{}{
-		"jsonrpc": "2.0",
-		"method":  "eth_getBlockByNumber",
-		"params":  []interface{}{blockNumber, true},
-		"id":      1,
-	}
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		log.Printf("Error marshaling request payload: %v", err)
+	var block model.Block
+	if err := callRPCContext(ctx, "eth_getBlockByNumber", []interface{}{blockNumber, true}, &block); err != nil {
 		return model.Block{}, err
 	}
 
-	resp, err := http.Post(EthereumRPCURL, "application/json", bytes.NewBuffer(jsonData))
-	if err != nil {
-		log.Printf("Error making RPC request: %v", err)
-		return model.Block{}, err
+	if VerifyBlocks {
+		if err := VerifyBlock(block); err != nil {
+			processorLog.WarnContext(ctx, "block failed verification", "block", blockNumber, "error", err)
+			return model.Block{}, fmt.Errorf("%w: block %s: %v", ErrBlockVerification, blockNumber, err)
+		}
+		block.Verified = true
 	}
-	defer resp.Body.Close()
 
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		log.Printf("Error reading response body: %v", err)
-		return model.Block{}, err
+	// Transactions carry their block's time so they can be sorted and filtered by it
+	if timestamp, err := parseHexUint(block.Timestamp); err == nil {
+		for i := range block.Transactions {
+			block.Transactions[i].Timestamp = int64(timestamp)
+		}
 	}
+	return block, nil
+}
 
-	var rpcResp model.JsonRPCResponse
-	if err := json.Unmarshal(body, &rpcResp); err != nil {
-		log.Printf("Error unmarshaling block response: %v", err)
-		return model.Block{}, err
+// GetTransactionReceipt retrieves the receipt of a mined transaction via RPC
+func GetTransactionReceipt(txHash string) (model.Receipt, error) {
+	return getTransactionReceipt(context.Background(), txHash)
+}
+
+func getTransactionReceipt(ctx context.Context, txHash string) (model.Receipt, error) {
+	var receipt model.Receipt
+	if err := callRPCContext(ctx, "eth_getTransactionReceipt", []interface{}{txHash}, &receipt); err != nil {
+		return model.Receipt{}, err
+	}
+	return receipt, nil
+}
+
+// ResolveContractAddress determines the address created by a contract-creation transaction.
+// The receipt is authoritative; if it is unavailable the address is derived from sender and nonce.
+func ResolveContractAddress(tx model.Transaction) (string, error) {
+	return resolveContractAddress(context.Background(), tx)
+}
+
+func resolveContractAddress(ctx context.Context, tx model.Transaction) (string, error) {
+	if receipt, err := getTransactionReceipt(ctx, tx.Hash); err == nil && receipt.ContractAddress != "" {
+		return strings.ToLower(receipt.ContractAddress), nil
 	}
 
-	return rpcResp.Result, nil
+	nonce, err := parseHexUint(tx.Nonce)
+	if err != nil {
+		return "", fmt.Errorf("invalid nonce %q for transaction %s", tx.Nonce, tx.Hash)
+	}
+	return crypto.CreateAddress(tx.From, nonce)
 }
 
 // FilterTransactionsByAddress filters transactions for subscribed addresses
 func FilterTransactionsByAddress(transactions []model.Transaction) error {
+	return FilterTransactionsByAddressContext(context.Background(), transactions)
+}
+
+// FilterTransactionsByAddressContext is FilterTransactionsByAddress with the receipt lookups of
+// contract creations traced under ctx
+func FilterTransactionsByAddressContext(ctx context.Context, transactions []model.Transaction) error {
 	addressMap := model.SharedStore().GetAllSubscriptions()
 	if len(addressMap) == 0 {
 		// Map is empty, handle the empty case here
-		fmt.Println("No subscriptions found.")
+		processorLog.DebugContext(ctx, "no subscriptions, skipping transactions")
 		return nil
 	}
 
+	matched, err := filterTransactions(ctx, transactions, addressMap)
+	matchedTransactions.Add(float64(matched))
+	return err
+}
+
+// filterTransactions stores the transactions involving the addresses in addressMap, returning
+// how many it matched
+func filterTransactions(ctx context.Context, transactions []model.Transaction, addressMap map[string]bool) (int, error) {
+	matched := 0
+	index := buildPendingIndex(addressMap)
 	for _, tx := range transactions {
 		lowerFrom := strings.ToLower(tx.From)
 		lowerTo := strings.ToLower(tx.To)
+		tx.Status = model.TxStatusMined
+
+		// Token transfers are stored for their decoded parties, so those count as matches too
+		transfer, isTransfer := DecodeTokenTransfer(tx)
+		involved := addressMap[lowerFrom] || addressMap[lowerTo] || (isTransfer && (addressMap[transfer.From] || addressMap[transfer.To]))
+		if involved && !verifyMatchedTransaction(&tx) {
+			continue
+		}
+
+		// Pending transactions sharing a sender and nonce with a mined one can never be mined
+		if err := applyReplacements(index, &tx); err != nil {
+			processorLog.ErrorContext(ctx, "updating replaced transactions failed", "block", tx.BlockNumber, "tx", tx.Hash, "error", err)
+			return matched, err
+		}
+
+		recordTokenTransfer(addressMap, tx)
+
+		// Contract deployments have no recipient; resolve and record the created address
+		if tx.IsContractCreation() && addressMap[lowerFrom] {
+			if err := recordContractCreation(ctx, &tx); err != nil {
+				processorLog.ErrorContext(ctx, "recording contract creation failed", "block", tx.BlockNumber, "tx", tx.Hash, "error", err)
+			}
+		}
 
 		// Check if From or To address is subscribed
 		if addressMap[lowerFrom] {
-			if err := model.SharedStore().SaveTransaction(lowerFrom, tx); err != nil {
-				log.Printf("Error saving transaction for address %s: %v", lowerFrom, err)
-				return err
+			if err := saveTransaction(lowerFrom, tx); err != nil {
+				processorLog.ErrorContext(ctx, "saving transaction failed", "block", tx.BlockNumber, "tx", tx.Hash, "address", lowerFrom, "error", err)
+				return matched, err
 			}
 		}
 
 		if addressMap[lowerTo] && lowerFrom != lowerTo {
-			if err := model.SharedStore().SaveTransaction(lowerTo, tx); err != nil {
-				log.Printf("Error saving transaction for address %s: %v", lowerTo, err)
-				return err
+			if err := saveTransaction(lowerTo, tx); err != nil {
+				processorLog.ErrorContext(ctx, "saving transaction failed", "block", tx.BlockNumber, "tx", tx.Hash, "address", lowerTo, "error", err)
+				return matched, err
 			}
 		}
+
+		if addressMap[lowerFrom] || addressMap[lowerTo] {
+			matched++
+		}
 	}
 
+	return matched, nil
+}
+
+// recordContractCreation resolves the created contract address, stores it against the deployer
+// and optionally subscribes to the new contract
+func recordContractCreation(ctx context.Context, tx *model.Transaction) error {
+	contract, err := resolveContractAddress(ctx, *tx)
+	if err != nil {
+		return err
+	}
+	tx.ContractAddress = contract
+
+	if err := model.SharedStore().SaveContract(tx.From, contract); err != nil {
+		return err
+	}
+
+	if AutoSubscribeContracts {
+		// The contract joins the subscriptions of every tenant watching its deployer
+		for _, tenant := range model.SharedStore().GetSubscribedTenants(tx.From) {
+			if _, err := model.SharedStore().SubscribeTenant(tenant, contract); err != nil {
+				return err
+			}
+		}
+		processorLog.InfoContext(ctx, "auto-subscribed contract", "block", tx.BlockNumber, "contract", contract, "deployer", tx.From)
+	}
 	return nil
 }
 
+// AdvancePastBlock moves the cursor past a fetched block. With VerifyBlocks it never moves past a
+// block whose contents were not verified.
+func AdvancePastBlock(block model.Block) error {
+	if VerifyBlocks && !block.Verified {
+		processorLog.Warn("refusing to advance past unverified block", "block", block.Number)
+		return fmt.Errorf("%w: block %s has not been verified", ErrBlockVerification, block.Number)
+	}
+	return IncrementBlockNumber(block.Number)
+}
+
 // IncrementBlockNumber increments and stores the block number
 func IncrementBlockNumber(blockHex string) error {
 	blockHex = strings.TrimPrefix(blockHex, "0x")
 	blockNumber, err := strconv.ParseInt(blockHex, 16, 64)
 	if err != nil {
-		log.Printf("Error parsing hex block number: %v", err)
+		processorLog.Error("parsing block number failed", "block", "0x"+blockHex, "error", err)
 		return errors.New("error parsing block number")
 	}
 
 	blockNumber++
 	newBlockHex := fmt.Sprintf("0x%x", blockNumber)
 	if err := SaveLatestBlock(newBlockHex); err != nil {
-		log.Printf("Error saving incremented block number: %v", err)
+		processorLog.Error("saving incremented block number failed", "block", newBlockHex, "error", err)
 		return err
 	}
 
-	log.Printf("Successfully incremented block number to: %d %s", blockNumber, newBlockHex)
+	processorLog.Debug("advanced to next block", "block", newBlockHex, "number", blockNumber)
 	return nil
 }
 
@@ -171,11 +276,27 @@ func isValidEthereumAddress(address string) bool {
 	return strings.HasPrefix(address, "0x") && len(address) == 42
 }
 
+// ValidateAddress returns ErrInvalidAddress if address is not a valid Ethereum address
+func ValidateAddress(address string) error {
+	if !isValidEthereumAddress(address) {
+		return ErrInvalidAddress
+	}
+	return nil
+}
+
 // Subscribe adds an address to the list of observed addresses
 func Subscribe(address string) (bool, error) {
 	if !isValidEthereumAddress(address) {
-		fmt.Println("Invalid Ethereum address")
-		return false, errors.New("invalid Ethereum address")
+		processorLog.Debug("invalid Ethereum address", "address", address)
+		return false, ErrInvalidAddress
+	}
+	return SubscribeTenant(model.DefaultTenant, address)
+}
+
+// Unsubscribe removes an address from the list of observed addresses
+func Unsubscribe(address string) (bool, error) {
+	if !isValidEthereumAddress(address) {
+		return false, ErrInvalidAddress
 	}
-	return model.SharedStore().Subscribe(address)
+	return UnsubscribeTenant(model.DefaultTenant, address)
 }
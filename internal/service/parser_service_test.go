@@ -14,7 +14,10 @@ import (
 type MockStore struct {
 	currentBlock  string
 	subscriptions map[string]bool
+	tenants       map[string]map[string]bool // Subscriptions of tenants other than the default
 	transactions  []model.Transaction
+	contracts     map[string][]string
+	transfers     map[string][]model.TokenTransfer
 }
 
 func (m *MockStore) GetCurrentBlock() (string, error) {
@@ -31,18 +34,132 @@ func (m *MockStore) Subscribe(address string) (bool, error) {
 	return true, nil
 }
 
+func (m *MockStore) Unsubscribe(address string) (bool, error) {
+	subscribed := m.subscriptions[address]
+	delete(m.subscriptions, address)
+	return subscribed, nil
+}
+
+func (m *MockStore) SubscribeTenant(tenant string, address string) (bool, error) {
+	if tenant == model.DefaultTenant {
+		return m.Subscribe(address)
+	}
+	if m.tenants == nil {
+		m.tenants = make(map[string]map[string]bool)
+	}
+	if m.tenants[tenant] == nil {
+		m.tenants[tenant] = make(map[string]bool)
+	}
+	subscribed := !m.tenants[tenant][address]
+	m.tenants[tenant][address] = true
+	return subscribed, nil
+}
+
+func (m *MockStore) UnsubscribeTenant(tenant string, address string) (bool, error) {
+	if tenant == model.DefaultTenant {
+		return m.Unsubscribe(address)
+	}
+	subscribed := m.tenants[tenant][address]
+	delete(m.tenants[tenant], address)
+	return subscribed, nil
+}
+
+func (m *MockStore) GetTenantSubscriptions(tenant string) map[string]bool {
+	if tenant == model.DefaultTenant {
+		return m.subscriptions
+	}
+	return m.tenants[tenant]
+}
+
+func (m *MockStore) GetSubscribedTenants(address string) []string {
+	var tenants []string
+	if m.subscriptions[address] {
+		tenants = append(tenants, model.DefaultTenant)
+	}
+	for tenant, addresses := range m.tenants {
+		if addresses[address] {
+			tenants = append(tenants, tenant)
+		}
+	}
+	return tenants
+}
+
 func (m *MockStore) GetAllSubscriptions() map[string]bool {
-	return m.subscriptions
+	all := make(map[string]bool)
+	for address := range m.subscriptions {
+		all[address] = true
+	}
+	for _, addresses := range m.tenants {
+		for address := range addresses {
+			all[address] = true
+		}
+	}
+	return all
 }
 
 func (m *MockStore) SaveTransaction(address string, tx model.Transaction) error {
+	for i, existing := range m.transactions {
+		if existing.Hash == tx.Hash {
+			m.transactions[i] = tx
+			return nil
+		}
+	}
+	m.transactions = append(m.transactions, tx)
 	return nil
 }
 
+func (m *MockStore) AddTransaction(address string, tx model.Transaction) (bool, error) {
+	for _, existing := range m.transactions {
+		if existing.Hash == tx.Hash {
+			return false, nil
+		}
+	}
+	m.transactions = append(m.transactions, tx)
+	return true, nil
+}
+
+func (m *MockStore) UpdateTransactionStatus(address string, hash string, from string, to string) (model.Transaction, bool, error) {
+	for i, existing := range m.transactions {
+		if existing.Hash == hash && existing.Status == from {
+			m.transactions[i].Status = to
+			return m.transactions[i], true, nil
+		}
+	}
+	return model.Transaction{}, false, nil
+}
+
 func (m *MockStore) GetTransactions(address string) []model.Transaction {
 	return m.transactions
 }
 
+func (m *MockStore) QueryTransactions(address string, query model.TransactionQuery) (model.TransactionPage, error) {
+	return model.ApplyTransactionQuery(m.transactions, address, query)
+}
+
+func (m *MockStore) SaveContract(deployer string, contract string) error {
+	if m.contracts == nil {
+		m.contracts = make(map[string][]string)
+	}
+	m.contracts[deployer] = append(m.contracts[deployer], contract)
+	return nil
+}
+
+func (m *MockStore) GetContracts(deployer string) []string {
+	return m.contracts[deployer]
+}
+
+func (m *MockStore) SaveTokenTransfer(address string, transfer model.TokenTransfer) error {
+	if m.transfers == nil {
+		m.transfers = make(map[string][]model.TokenTransfer)
+	}
+	m.transfers[address] = append(m.transfers[address], transfer)
+	return nil
+}
+
+func (m *MockStore) GetTokenTransfers(address string) []model.TokenTransfer {
+	return m.transfers[address]
+}
+
 // Mocking HTTP Client for RPC calls
 func mockEthBlockNumberHandler(w http.ResponseWriter, r *http.Request) {
 	response := parser.RpcResponse{
@@ -65,10 +182,18 @@ func mockGetBlockByNumberHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// useRPCServer points the service at a mock RPC server for the duration of the test
+func useRPCServer(t *testing.T, url string) {
+	previous := EthereumRPCURL
+	EthereumRPCURL = url
+	t.Cleanup(func() { EthereumRPCURL = previous })
+}
+
 func TestGetEthBlockByNumber(t *testing.T) {
 	// Setting up mock HTTP server
 	server := httptest.NewServer(http.HandlerFunc(mockGetBlockByNumberHandler))
 	defer server.Close()
+	useRPCServer(t, server.URL)
 
 	block, err := GetEthBlockByNumber("0x10d4f")
 	if err != nil {
@@ -148,3 +273,57 @@ func TestSubscribe(t *testing.T) {
 		})
 	}
 }
+
+func TestFilterTransactionsContractCreation(t *testing.T) {
+	deployer := "0x970e8128ab834e8eac17ab8e3812f010678cf791"
+
+	tests := []struct {
+		name            string
+		receiptAddress  string
+		autoSubscribe   bool
+		expectedAddress string
+	}{
+		{"Address from receipt", "0xAbCdEfabcdefabcdefabcdefabcdefabcdefabcd", false, "0xabcdefabcdefabcdefabcdefabcdefabcdefabcd"},
+		{"Address from sender and nonce", "", true, "0x8bda78331c916a08481428e4b07c96d3e916d165"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.receiptAddress == "" {
+					w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":null}`))
+					return
+				}
+				json.NewEncoder(w).Encode(model.JsonRPCReceiptResponse{
+					JsonRPC: "2.0",
+					ID:      1,
+					Result:  model.Receipt{TransactionHash: "0xdeploy", ContractAddress: tt.receiptAddress},
+				})
+			}))
+			defer server.Close()
+			useRPCServer(t, server.URL)
+
+			mockStore := &MockStore{subscriptions: map[string]bool{deployer: true}}
+			model.InitializeStore(mockStore)
+			AutoSubscribeContracts = tt.autoSubscribe
+			defer func() { AutoSubscribeContracts = false }()
+
+			err := FilterTransactionsByAddress([]model.Transaction{{Hash: "0xdeploy", From: deployer, Nonce: "0x1"}})
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+
+			contracts := mockStore.GetContracts(deployer)
+			if len(contracts) != 1 || contracts[0] != tt.expectedAddress {
+				t.Errorf("expected contract %s, got: %v", tt.expectedAddress, contracts)
+			}
+			if mockStore.subscriptions[tt.expectedAddress] != tt.autoSubscribe {
+				t.Errorf("expected contract subscription to be %v", tt.autoSubscribe)
+			}
+		})
+	}
+}
+
+func (m *MockStore) RemoveTransactionsAbove(blockNumber uint64) (int, error) {
+	return 0, nil
+}
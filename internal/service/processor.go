@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"ethereum-tx-parser/internal/model"
+	"ethereum-tx-parser/internal/tracing"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var (
+	// MaxReprocessBlocks is the most blocks one reprocess may cover
+	MaxReprocessBlocks = uint64(10000)
+
+	// ErrInvalidBlockRange is returned for a rewind or reprocess outside the processed blocks
+	ErrInvalidBlockRange = errors.New("invalid block range")
+
+	// ErrReprocessRunning is returned when starting a reprocess while another one runs
+	ErrReprocessRunning = errors.New("a reprocess is already running")
+)
+
+var (
+	// cycleMu is held for each cycle of the processing loop, and by the admin operations that
+	// move the cursor or rewrite stored transactions, so neither sees the other half done
+	cycleMu sync.Mutex
+
+	processorMu sync.Mutex
+	pausedAt    time.Time // Zero while the loop runs
+	reprocess   *model.ReprocessTask
+)
+
+// RunProcessorCycle runs one cycle of the block processing loop, unless an admin paused it, and
+// reports whether it ran
+func RunProcessorCycle(cycle func()) bool {
+	cycleMu.Lock()
+	defer cycleMu.Unlock()
+	if ProcessorPaused() {
+		return false
+	}
+	cycle()
+	return true
+}
+
+// ProcessorPaused reports whether an admin paused the processing loop
+func ProcessorPaused() bool {
+	processorMu.Lock()
+	defer processorMu.Unlock()
+	return !pausedAt.IsZero()
+}
+
+// PauseProcessor stops the processing loop after its current cycle, which it waits for
+func PauseProcessor() model.ProcessorStatus {
+	cycleMu.Lock()
+	processorMu.Lock()
+	if pausedAt.IsZero() {
+		pausedAt = time.Now()
+		processorLog.Info("processor paused")
+	}
+	processorMu.Unlock()
+	cycleMu.Unlock()
+	return GetProcessorStatus()
+}
+
+// ResumeProcessor restarts a paused processing loop
+func ResumeProcessor() model.ProcessorStatus {
+	processorMu.Lock()
+	if !pausedAt.IsZero() {
+		pausedAt = time.Time{}
+		processorLog.Info("processor resumed")
+	}
+	processorMu.Unlock()
+	return GetProcessorStatus()
+}
+
+// RewindProcessor makes block the last processed block, deleting the stored transactions of
+// later blocks so the loop processes them again. It returns how many transactions it deleted.
+func RewindProcessor(block uint64) (int, error) {
+	cycleMu.Lock()
+	defer cycleMu.Unlock()
+
+	current, err := currentCursor()
+	if err != nil {
+		return 0, err
+	}
+	if block >= current {
+		return 0, fmt.Errorf("%w: block %d has not been processed yet, the next block is %d", ErrInvalidBlockRange, block, current)
+	}
+
+	removed, err := model.SharedStore().RemoveTransactionsAbove(block)
+	if err != nil {
+		return 0, err
+	}
+	if err := SaveLatestBlock(fmt.Sprintf("0x%x", block+1)); err != nil {
+		return removed, err
+	}
+	processorLog.Warn("processor rewound", "block", block, "from", current, "removed", removed)
+	return removed, nil
+}
+
+// ReprocessBlocks starts processing blocks from through to again for addresses, in the
+// background. Matched transactions replace their stored copies and are published again.
+func ReprocessBlocks(from uint64, to uint64, addresses []string) (model.ReprocessTask, error) {
+	if from > to || to-from >= MaxReprocessBlocks {
+		return model.ReprocessTask{}, fmt.Errorf("%w: from %d to %d must be ordered and cover at most %d blocks", ErrInvalidBlockRange, from, to, MaxReprocessBlocks)
+	}
+	current, err := currentCursor()
+	if err != nil {
+		return model.ReprocessTask{}, err
+	}
+	if to >= current {
+		return model.ReprocessTask{}, fmt.Errorf("%w: block %d has not been processed yet, the next block is %d", ErrInvalidBlockRange, to, current)
+	}
+
+	addressMap := make(map[string]bool, len(addresses))
+	subscribed := model.SharedStore().GetAllSubscriptions()
+	for _, address := range addresses {
+		if err := ValidateAddress(address); err != nil {
+			return model.ReprocessTask{}, err
+		}
+		address = strings.ToLower(address)
+		if !subscribed[address] {
+			return model.ReprocessTask{}, fmt.Errorf("%w: %s", ErrNotSubscribed, address)
+		}
+		addressMap[address] = true
+	}
+	if len(addressMap) == 0 {
+		return model.ReprocessTask{}, fmt.Errorf("%w: at least one address is required", ErrInvalidAddress)
+	}
+
+	processorMu.Lock()
+	defer processorMu.Unlock()
+	if reprocess != nil && reprocess.State == model.TaskRunning {
+		return model.ReprocessTask{}, ErrReprocessRunning
+	}
+	task := &model.ReprocessTask{
+		FromBlock: from,
+		ToBlock:   to,
+		Addresses: sortedKeys(addressMap),
+		State:     model.TaskRunning,
+		NextBlock: from,
+		StartedAt: time.Now().UTC(),
+	}
+	reprocess = task
+	processorLog.Info("reprocess started", "from", from, "to", to, "addresses", task.Addresses)
+	go runReprocess(task, addressMap)
+	return copyTask(task), nil
+}
+
+// runReprocess processes the blocks of a task one at a time, between cycles of the loop
+func runReprocess(task *model.ReprocessTask, addressMap map[string]bool) {
+	for block := task.FromBlock; block <= task.ToBlock; block++ {
+		matched, err := reprocessBlock(block, addressMap)
+
+		processorMu.Lock()
+		task.Transactions += matched
+		if err != nil {
+			task.State, task.Error = model.TaskFailed, err.Error()
+		} else {
+			task.NextBlock = block + 1
+		}
+		processorMu.Unlock()
+		if err != nil {
+			processorLog.Error("reprocess failed", "block", fmt.Sprintf("0x%x", block), "error", err)
+			break
+		}
+	}
+
+	processorMu.Lock()
+	defer processorMu.Unlock()
+	if task.State == model.TaskRunning {
+		task.State = model.TaskDone
+	}
+	finished := time.Now().UTC()
+	task.FinishedAt = &finished
+	processorLog.Info("reprocess finished", "state", task.State, "next", task.NextBlock, "transactions", task.Transactions)
+}
+
+// reprocessBlock fetches and filters one block for addressMap in a span of its own
+func reprocessBlock(block uint64, addressMap map[string]bool) (int, error) {
+	cycleMu.Lock()
+	defer cycleMu.Unlock()
+
+	blockHex := fmt.Sprintf("0x%x", block)
+	ctx, span := processorTracer.Start(context.Background(), "reprocess block")
+	span.SetAttributes(attribute.String("block.number", blockHex))
+	fetched, err := GetEthBlockByNumberContext(ctx, blockHex)
+	if err != nil {
+		tracing.End(span, err)
+		return 0, err
+	}
+	matched, err := filterTransactions(ctx, fetched.Transactions, addressMap)
+	tracing.End(span, err)
+	return matched, err
+}
+
+// GetProcessorStatus returns the sync status with the pause state and the latest reprocess
+func GetProcessorStatus() model.ProcessorStatus {
+	status := model.ProcessorStatus{SyncStatus: GetSyncStatus()}
+	processorMu.Lock()
+	defer processorMu.Unlock()
+	status.PausedAt = timePointer(pausedAt)
+	if reprocess != nil {
+		task := copyTask(reprocess)
+		status.Reprocess = &task
+	}
+	return status
+}
+
+// currentCursor returns the next block the loop will process
+func currentCursor() (uint64, error) {
+	current, err := GetBlockNumber()
+	if err != nil {
+		return 0, err
+	}
+	return parseHexUint(current)
+}
+
+// copyTask copies a task so it can be read while runReprocess updates the original.
+// processorMu must be held.
+func copyTask(task *model.ReprocessTask) model.ReprocessTask {
+	copied := *task
+	copied.Addresses = append([]string(nil), task.Addresses...)
+	return copied
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
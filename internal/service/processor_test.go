@@ -0,0 +1,171 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ethereum-tx-parser/internal/model"
+)
+
+const (
+	processorSender    = "0x00000000000000000000000000000000000000a1"
+	processorRecipient = "0x0000000000000000000000000000000000000002"
+)
+
+// resetProcessor clears the pause state and reprocess task before and after a test
+func resetProcessor(t *testing.T) {
+	reset := func() {
+		processorMu.Lock()
+		pausedAt, reprocess = time.Time{}, nil
+		processorMu.Unlock()
+	}
+	reset()
+	t.Cleanup(reset)
+}
+
+// serveBlocks answers eth_getBlockByNumber with one transaction from processorSender per block
+func serveBlocks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Params []interface{} `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		number, _ := req.Params[0].(string)
+		block := model.Block{Number: number, Timestamp: "0x1", Transactions: []model.Transaction{
+			{Hash: "0xaa" + number[2:], From: processorSender, To: processorRecipient, BlockNumber: number},
+		}}
+		json.NewEncoder(w).Encode(model.JsonRPCResponse{JsonRPC: "2.0", ID: 1, Result: block})
+	}))
+	t.Cleanup(server.Close)
+	useRPCServer(t, server.URL)
+}
+
+// waitForReprocess waits until the reprocess task is no longer running
+func waitForReprocess(t *testing.T) model.ReprocessTask {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		if task := GetProcessorStatus().Reprocess; task != nil && task.State != model.TaskRunning {
+			return *task
+		}
+	}
+	t.Fatal("reprocess did not finish")
+	return model.ReprocessTask{}
+}
+
+func TestPauseAndResumeProcessor(t *testing.T) {
+	resetProcessor(t)
+	InitializeModelLayer()
+
+	ran := false
+	if !RunProcessorCycle(func() { ran = true }) || !ran {
+		t.Fatal("expected the cycle to run")
+	}
+
+	status := PauseProcessor()
+	if !status.Paused || status.PausedAt == nil {
+		t.Errorf("expected a paused status, got: %+v", status)
+	}
+	ran = false
+	if RunProcessorCycle(func() { ran = true }) || ran {
+		t.Error("expected no cycle to run while paused")
+	}
+	if !GetSyncStatus().Paused {
+		t.Error("expected /status to report the pause")
+	}
+
+	if status := ResumeProcessor(); status.Paused || status.PausedAt != nil {
+		t.Errorf("expected a running status, got: %+v", status)
+	}
+	if !RunProcessorCycle(func() {}) {
+		t.Error("expected cycles to run after resuming")
+	}
+}
+
+func TestRewindProcessor(t *testing.T) {
+	resetProcessor(t)
+	InitializeModelLayer()
+	SaveLatestBlock("0x20")
+	for _, block := range []string{"0x10", "0x11", "0x1f"} {
+		if err := saveTransaction(processorSender, model.Transaction{Hash: "0x" + block[2:], BlockNumber: block}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := RewindProcessor(0x20); !errors.Is(err, ErrInvalidBlockRange) {
+		t.Errorf("expected an unprocessed block to be rejected, got: %v", err)
+	}
+
+	removed, err := RewindProcessor(0x10)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if removed != 2 {
+		t.Errorf("expected 2 transactions removed, got: %d", removed)
+	}
+	if current, _ := GetBlockNumber(); current != "0x11" {
+		t.Errorf("expected the next block to be 0x11, got: %s", current)
+	}
+	if transactions := model.SharedStore().GetTransactions(processorSender); len(transactions) != 1 || transactions[0].BlockNumber != "0x10" {
+		t.Errorf("expected only the block 0x10 transaction to remain, got: %+v", transactions)
+	}
+}
+
+func TestReprocessBlocks(t *testing.T) {
+	resetProcessor(t)
+	InitializeModelLayer()
+	serveBlocks(t)
+	SaveLatestBlock("0x20")
+	Subscribe(processorSender)
+
+	if _, err := ReprocessBlocks(0x10, 0x20, []string{processorSender}); !errors.Is(err, ErrInvalidBlockRange) {
+		t.Errorf("expected a range reaching the cursor to be rejected, got: %v", err)
+	}
+	if _, err := ReprocessBlocks(0x12, 0x10, []string{processorSender}); !errors.Is(err, ErrInvalidBlockRange) {
+		t.Errorf("expected a reversed range to be rejected, got: %v", err)
+	}
+	if _, err := ReprocessBlocks(0x10, 0x12, []string{"0x00000000000000000000000000000000000000b2"}); !errors.Is(err, ErrNotSubscribed) {
+		t.Errorf("expected an unsubscribed address to be rejected, got: %v", err)
+	}
+	if _, err := ReprocessBlocks(0x10, 0x12, nil); !errors.Is(err, ErrInvalidAddress) {
+		t.Errorf("expected a missing address to be rejected, got: %v", err)
+	}
+
+	task, err := ReprocessBlocks(0x10, 0x12, []string{processorSender})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if task.State != model.TaskRunning || task.NextBlock != 0x10 {
+		t.Errorf("expected a running task at block 0x10, got: %+v", task)
+	}
+
+	task = waitForReprocess(t)
+	if task.State != model.TaskDone || task.NextBlock != 0x13 || task.Transactions != 3 || task.FinishedAt == nil {
+		t.Errorf("expected a finished task with 3 transactions, got: %+v", task)
+	}
+	if transactions := model.SharedStore().GetTransactions(processorSender); len(transactions) != 3 {
+		t.Errorf("expected 3 stored transactions, got: %d", len(transactions))
+	}
+	if current, _ := GetBlockNumber(); current != "0x20" {
+		t.Errorf("expected reprocessing to leave the cursor at 0x20, got: %s", current)
+	}
+}
+
+func TestReprocessFailure(t *testing.T) {
+	resetProcessor(t)
+	InitializeModelLayer()
+	useRPCServer(t, "http://127.0.0.1:1")
+	SaveLatestBlock("0x20")
+	Subscribe(processorSender)
+
+	if _, err := ReprocessBlocks(0x10, 0x12, []string{processorSender}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	task := waitForReprocess(t)
+	if task.State != model.TaskFailed || task.NextBlock != 0x10 || task.Error == "" {
+		t.Errorf("expected a task failed at block 0x10, got: %+v", task)
+	}
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"ethereum-tx-parser/internal/model"
+	"math"
+	"sync"
+	"time"
+)
+
+var (
+	// RateLimitPerSecond is the sustained request rate allowed to each client; 0 disables rate limiting
+	RateLimitPerSecond = 0.0
+
+	// RateLimitBurst is how many requests a client may make at once before it is limited
+	RateLimitBurst = 20
+)
+
+// tokenBucket holds the requests a client may still make, refilled at RateLimitPerSecond
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+var (
+	rateLimitMu    sync.Mutex
+	rateBuckets    = make(map[string]*tokenBucket) // By client, e.g. "key:<id>" or "ip:<address>"
+	rateLastPruned time.Time
+)
+
+// AllowRequest takes a token from the bucket of a client. It returns whether the request may
+// proceed, the client's usage after the request, and how long to wait when it may not.
+func AllowRequest(client string) (bool, model.RequestQuota, time.Duration) {
+	if RateLimitPerSecond <= 0 {
+		return true, model.RequestQuota{}, 0
+	}
+
+	rateLimitMu.Lock()
+	defer rateLimitMu.Unlock()
+	now := time.Now()
+	pruneBuckets(now)
+
+	bucket := refill(client, now)
+	if bucket.tokens < 1 {
+		wait := time.Duration((1 - bucket.tokens) / RateLimitPerSecond * float64(time.Second))
+		return false, requestQuota(bucket), wait
+	}
+	bucket.tokens--
+	return true, requestQuota(bucket), 0
+}
+
+// RequestUsage returns the rate limit usage of a client without taking a token, or nil when
+// rate limiting is off
+func RequestUsage(client string) *model.RequestQuota {
+	if RateLimitPerSecond <= 0 {
+		return nil
+	}
+
+	rateLimitMu.Lock()
+	defer rateLimitMu.Unlock()
+	quota := requestQuota(refill(client, time.Now()))
+	return &quota
+}
+
+// refill returns the bucket of a client, topped up for the time since it was last used.
+// rateLimitMu must be held.
+func refill(client string, now time.Time) *tokenBucket {
+	bucket, ok := rateBuckets[client]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(RateLimitBurst), updated: now}
+		rateBuckets[client] = bucket
+	}
+	elapsed := now.Sub(bucket.updated).Seconds()
+	bucket.tokens = math.Min(float64(RateLimitBurst), bucket.tokens+elapsed*RateLimitPerSecond)
+	bucket.updated = now
+	return bucket
+}
+
+// pruneBuckets drops, at most once a minute, the buckets that have refilled completely, so
+// clients that went away don't accumulate. rateLimitMu must be held.
+func pruneBuckets(now time.Time) {
+	if now.Sub(rateLastPruned) < time.Minute {
+		return
+	}
+	rateLastPruned = now
+	for client, bucket := range rateBuckets {
+		if bucket.tokens+now.Sub(bucket.updated).Seconds()*RateLimitPerSecond >= float64(RateLimitBurst) {
+			delete(rateBuckets, client)
+		}
+	}
+}
+
+// requestQuota reports the usage of a bucket
+func requestQuota(bucket *tokenBucket) model.RequestQuota {
+	return model.RequestQuota{
+		PerSecond: RateLimitPerSecond,
+		Burst:     RateLimitBurst,
+		Remaining: int(bucket.tokens),
+	}
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+// setRateLimit enables rate limiting for a test over empty buckets
+func setRateLimit(t *testing.T, perSecond float64, burst int) {
+	rateLimitMu.Lock()
+	rateBuckets = make(map[string]*tokenBucket)
+	rateLimitMu.Unlock()
+
+	RateLimitPerSecond, RateLimitBurst = perSecond, burst
+	t.Cleanup(func() { RateLimitPerSecond, RateLimitBurst = 0, 20 })
+}
+
+func TestAllowRequest(t *testing.T) {
+	setRateLimit(t, 1, 2)
+
+	for i := 0; i < 2; i++ {
+		if ok, usage, _ := AllowRequest("ip:a"); !ok || usage.Remaining != 1-i {
+			t.Fatalf("request %d: expected to be allowed with %d remaining, got: %v, %+v", i, 1-i, ok, usage)
+		}
+	}
+	ok, usage, wait := AllowRequest("ip:a")
+	if ok || usage.Remaining != 0 || wait <= 0 || wait > time.Second {
+		t.Errorf("expected the third request to wait up to a second, got: %v, %+v, %v", ok, usage, wait)
+	}
+
+	// Clients have separate buckets
+	if ok, _, _ := AllowRequest("ip:b"); !ok {
+		t.Errorf("expected another client to be allowed")
+	}
+	if usage := RequestUsage("ip:b"); usage == nil || usage.Remaining != 1 {
+		t.Errorf("expected usage to report one remaining request, got: %+v", usage)
+	}
+}
+
+func TestAllowRequestRefills(t *testing.T) {
+	setRateLimit(t, 1000, 1)
+
+	AllowRequest("ip:a")
+	if ok, _, _ := AllowRequest("ip:a"); ok {
+		t.Fatalf("expected the bucket to be empty")
+	}
+	time.Sleep(5 * time.Millisecond)
+	if ok, _, _ := AllowRequest("ip:a"); !ok {
+		t.Errorf("expected the bucket to refill")
+	}
+}
+
+func TestRateLimitDisabled(t *testing.T) {
+	if ok, _, _ := AllowRequest("ip:a"); !ok {
+		t.Errorf("expected requests to be allowed without a rate limit")
+	}
+	if usage := RequestUsage("ip:a"); usage != nil {
+		t.Errorf("expected no usage without a rate limit, got: %+v", usage)
+	}
+}
+
+func TestSubscriptionQuota(t *testing.T) {
+	InitializeModelLayer()
+	MaxSubscriptionsPerTenant = 1
+	defer func() { MaxSubscriptionsPerTenant = 0 }()
+
+	if ok, err := SubscribeTenant("acme", subscribedAddress); !ok || err != nil {
+		t.Fatalf("expected subscription, got: %v, %v", ok, err)
+	}
+	if _, err := SubscribeTenant("acme", "0x0000000000000000000000000000000000000002"); err != ErrQuotaExceeded {
+		t.Errorf("expected ErrQuotaExceeded, got: %v", err)
+	}
+	if ok, err := SubscribeTenant("acme", subscribedAddress); ok || err != nil {
+		t.Errorf("expected an existing subscription not to count against the quota, got: %v, %v", ok, err)
+	}
+	if ok, err := SubscribeTenant("other", "0x0000000000000000000000000000000000000002"); !ok || err != nil {
+		t.Errorf("expected the quota to apply per tenant, got: %v, %v", ok, err)
+	}
+
+	if quota := GetQuota("acme"); quota.Subscriptions.Used != 1 || quota.Subscriptions.Limit != 1 {
+		t.Errorf("expected 1 of 1 subscriptions used, got: %+v", quota)
+	}
+}
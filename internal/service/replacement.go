@@ -0,0 +1,75 @@
+package service
+
+import (
+	"ethereum-tx-parser/internal/model"
+	"math/big"
+	"strings"
+)
+
+// storedTx is a transaction together with the subscribed address it is stored under
+type storedTx struct {
+	address string
+	tx      model.Transaction
+}
+
+// pendingIndex maps a (sender, nonce) slot to the pending transactions stored for it
+type pendingIndex map[string][]storedTx
+
+// buildPendingIndex indexes the pending transactions of all subscribed addresses by sender and nonce
+func buildPendingIndex(addressMap map[string]bool) pendingIndex {
+	index := make(pendingIndex)
+	for address := range addressMap {
+		for _, tx := range model.SharedStore().GetTransactions(address) {
+			if tx.Status == model.TxStatusPending {
+				key := senderNonceKey(tx)
+				index[key] = append(index[key], storedTx{address, tx})
+			}
+		}
+	}
+	return index
+}
+
+// applyReplacements marks pending transactions in the same (sender, nonce) slot as tx, but with a
+// different hash, as replaced by tx, and records on tx which transaction it replaces
+func applyReplacements(index pendingIndex, tx *model.Transaction) error {
+	key := senderNonceKey(*tx)
+	for i, stored := range index[key] {
+		if stored.tx.Hash == tx.Hash || stored.tx.Status != model.TxStatusPending {
+			continue
+		}
+
+		kind := classifyReplacement(*tx)
+		stored.tx.Status = model.TxStatusReplaced
+		stored.tx.ReplacedBy = tx.Hash
+		stored.tx.ReplacementType = kind
+		if err := saveTransaction(stored.address, stored.tx); err != nil {
+			return err
+		}
+		index[key][i] = stored
+
+		tx.Replaces = stored.tx.Hash
+		tx.ReplacementType = kind
+		processorLog.Info("transaction replaced", "tx", stored.tx.Hash, "replaced_by", tx.Hash, "kind", kind)
+	}
+	return nil
+}
+
+// classifyReplacement labels a replacement as a cancel when it is a zero-value transfer back to
+// the sender, and as a speed-up otherwise
+func classifyReplacement(tx model.Transaction) string {
+	if strings.EqualFold(tx.From, tx.To) && isZeroValue(tx.Value) {
+		return model.ReplacementCancel
+	}
+	return model.ReplacementSpeedUp
+}
+
+// isZeroValue reports whether a hex or decimal wei amount is zero
+func isZeroValue(value string) bool {
+	amount, ok := new(big.Int).SetString(value, 0)
+	return value == "" || (ok && amount.Sign() == 0)
+}
+
+// senderNonceKey identifies a transaction slot by its sender and nonce
+func senderNonceKey(tx model.Transaction) string {
+	return strings.ToLower(tx.From) + "/" + strings.ToLower(tx.Nonce)
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"ethereum-tx-parser/internal/model"
+)
+
+func TestClassifyReplacement(t *testing.T) {
+	tests := []struct {
+		name     string
+		tx       model.Transaction
+		expected string
+	}{
+		{"Zero value self transfer", model.Transaction{From: subscribedAddress, To: subscribedAddress, Value: "0x0"}, model.ReplacementCancel},
+		{"Self transfer with value", model.Transaction{From: subscribedAddress, To: subscribedAddress, Value: "0x1"}, model.ReplacementSpeedUp},
+		{"Transfer to other address", model.Transaction{From: subscribedAddress, To: "0xabcdefabcdefabcdefabcdefabcdefabcdefabcd", Value: "0x0"}, model.ReplacementSpeedUp},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if kind := classifyReplacement(tt.tx); kind != tt.expected {
+				t.Errorf("expected %s, got: %s", tt.expected, kind)
+			}
+		})
+	}
+}
+
+func TestPendingReplacementLinksTransactions(t *testing.T) {
+	original := model.Transaction{Hash: "0xaaa", From: subscribedAddress, To: "0xabcdefabcdefabcdefabcdefabcdefabcdefabcd", Value: "0x1", Nonce: "0x5", Status: model.TxStatusPending}
+	speedUp := original
+	speedUp.Hash = "0xbbb"
+	speedUp.Status = ""
+
+	server := httptest.NewServer(mockMempoolHandler(model.TxPoolContent{
+		Pending: map[string]map[string]model.Transaction{subscribedAddress: {"5": speedUp}},
+	}))
+	defer server.Close()
+	useRPCServer(t, server.URL)
+
+	mockStore := &MockStore{
+		subscriptions: map[string]bool{subscribedAddress: true},
+		transactions:  []model.Transaction{original},
+	}
+	model.InitializeStore(mockStore)
+
+	if err := PollPendingTransactions(); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(mockStore.transactions) != 2 {
+		t.Fatalf("expected two transactions, got: %+v", mockStore.transactions)
+	}
+
+	replaced, replacement := mockStore.transactions[0], mockStore.transactions[1]
+	if replaced.Status != model.TxStatusReplaced || replaced.ReplacedBy != "0xbbb" || replaced.ReplacementType != model.ReplacementSpeedUp {
+		t.Errorf("unexpected replaced transaction: %+v", replaced)
+	}
+	if replacement.Status != model.TxStatusPending || replacement.Replaces != "0xaaa" || replacement.ReplacementType != model.ReplacementSpeedUp {
+		t.Errorf("unexpected replacement transaction: %+v", replacement)
+	}
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"ethereum-tx-parser/internal/parser"
+	"ethereum-tx-parser/internal/tracing"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// errNoResult is returned when the node answers with a null result, e.g. for an unknown transaction
+var errNoResult = errors.New("rpc returned no result")
+
+// rpcEnvelope is a JSON-RPC response whose result is decoded by the caller
+type rpcEnvelope struct {
+	ID      int             `json:"id"`
+	JsonRPC string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result"`
+	Error   *rpcError       `json:"error"`
+}
+
+// rpcError is the error object returned by an Ethereum node
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+// callRPC sends a JSON-RPC request to the Ethereum node and decodes the result into result
+func callRPC(method string, params []interface{}, result interface{}) error {
+	return callRPCContext(context.Background(), method, params, result)
+}
+
+// callRPCContext is callRPC in a client span under ctx. The span's trace context is sent to the
+// node in a traceparent header.
+func callRPCContext(ctx context.Context, method string, params []interface{}, result interface{}) (err error) {
+	defer func(start time.Time) { observeRPC(method, start, err) }(time.Now())
+	ctx, span := rpcTracer.Start(ctx, method, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("rpc.system", "jsonrpc"),
+		attribute.String("rpc.method", method),
+		attribute.String("server.address", rpcHost()),
+	))
+	defer func() {
+		var nodeErr *rpcError
+		if errors.As(err, &nodeErr) {
+			span.SetAttributes(attribute.Int("rpc.jsonrpc.error_code", nodeErr.Code))
+		}
+		tracing.End(span, err)
+	}()
+
+	requestPayload := parser.RpcRequest{
+		JsonRPC: "2.0",
+		Method:  method,
+		Params:  params,
+		ID:      1,
+	}
+
+	payloadBytes, err := json.Marshal(requestPayload)
+	if err != nil {
+		rpcLog.ErrorContext(ctx, "encoding request failed", "method", method, "error", err)
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, EthereumRPCURL, bytes.NewBuffer(payloadBytes))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		rpcLog.WarnContext(ctx, "request failed", "method", method, "error", err)
+		return err
+	}
+	defer resp.Body.Close()
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		rpcLog.WarnContext(ctx, "reading response failed", "method", method, "error", err)
+		return err
+	}
+
+	var rpcResp rpcEnvelope
+	if err := json.Unmarshal(body, &rpcResp); err != nil {
+		rpcLog.WarnContext(ctx, "decoding response failed", "method", method, "error", err)
+		return err
+	}
+	if rpcResp.Error != nil {
+		return rpcResp.Error
+	}
+	if len(rpcResp.Result) == 0 || string(rpcResp.Result) == "null" {
+		return fmt.Errorf("%s: %w", method, errNoResult)
+	}
+
+	if err := json.Unmarshal(rpcResp.Result, result); err != nil {
+		rpcLog.WarnContext(ctx, "decoding result failed", "method", method, "error", err)
+		return err
+	}
+	return nil
+}
+
+// rpcHost returns the host of the node's URL, which unlike the URL carries no API key
+func rpcHost() string {
+	if u, err := url.Parse(EthereumRPCURL); err == nil {
+		return u.Host
+	}
+	return ""
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"ethereum-tx-parser/internal/model"
+	"strings"
+	"sync"
+)
+
+var (
+	// MaxSubscriptionsPerTenant caps how many addresses each tenant may subscribe to; 0 means no limit
+	MaxSubscriptionsPerTenant = 0
+
+	// ErrQuotaExceeded is returned when a tenant is at its subscription limit
+	ErrQuotaExceeded = errors.New("subscription quota exceeded")
+)
+
+// subscribeMu makes the quota check and the subscription one step
+var subscribeMu sync.Mutex
+
+// tenantKey is the context key of the tenant a request is authenticated as
+type tenantKey struct{}
+
+// WithTenant returns a context for a request authenticated as tenant
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant a request is authenticated as, if it presented an API key
+func TenantFromContext(ctx context.Context) (string, bool) {
+	tenant, ok := ctx.Value(tenantKey{}).(string)
+	return tenant, ok
+}
+
+// CanReadAddress reports whether a request may read the transactions, contracts and alerts of an
+// address. Requests with an API key only see the addresses their tenant subscribes to; requests
+// without one, which are only accepted while RequireAPIKey is off, act for the default tenant.
+func CanReadAddress(ctx context.Context, address string) bool {
+	return ParserFor(ctx).CanRead(address)
+}
+
+// SubscribeTenant adds an address to the subscriptions of a tenant. Addresses already subscribed
+// don't count against MaxSubscriptionsPerTenant; contracts subscribed by AutoSubscribeContracts
+// bypass it.
+func SubscribeTenant(tenant string, address string) (bool, error) {
+	if !isValidEthereumAddress(address) {
+		return false, ErrInvalidAddress
+	}
+
+	subscribeMu.Lock()
+	defer subscribeMu.Unlock()
+	if MaxSubscriptionsPerTenant > 0 {
+		subscriptions := model.SharedStore().GetTenantSubscriptions(tenant)
+		if !subscriptions[strings.ToLower(address)] && len(subscriptions) >= MaxSubscriptionsPerTenant {
+			return false, ErrQuotaExceeded
+		}
+	}
+	return model.SharedStore().SubscribeTenant(tenant, address)
+}
+
+// UnsubscribeTenant removes an address from the subscriptions of a tenant
+func UnsubscribeTenant(tenant string, address string) (bool, error) {
+	if !isValidEthereumAddress(address) {
+		return false, ErrInvalidAddress
+	}
+	return model.SharedStore().UnsubscribeTenant(tenant, address)
+}
+
+// GetQuota returns the subscription usage of a tenant
+func GetQuota(tenant string) model.Quota {
+	return model.Quota{
+		Tenant: tenant,
+		Subscriptions: model.SubscriptionQuota{
+			Used:  len(model.SharedStore().GetTenantSubscriptions(tenant)),
+			Limit: MaxSubscriptionsPerTenant,
+		},
+	}
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"encoding/hex"
+	"ethereum-tx-parser/internal/model"
+	"math/big"
+	"strings"
+)
+
+// ERC-20 function selectors
+const (
+	transferSelector     = "a9059cbb" // transfer(address,uint256)
+	transferFromSelector = "23b872dd" // transferFrom(address,address,uint256)
+	approveSelector      = "095ea7b3" // approve(address,uint256)
+)
+
+// knownCalls are the functions DecodeCall recognises, by selector
+var knownCalls = map[string]struct {
+	method string
+	params []model.DecodedParam // Name and type of each argument
+}{
+	transferSelector:     {"transfer(address,uint256)", []model.DecodedParam{{Name: "to", Type: "address"}, {Name: "amount", Type: "uint256"}}},
+	transferFromSelector: {"transferFrom(address,address,uint256)", []model.DecodedParam{{Name: "from", Type: "address"}, {Name: "to", Type: "address"}, {Name: "amount", Type: "uint256"}}},
+	approveSelector:      {"approve(address,uint256)", []model.DecodedParam{{Name: "spender", Type: "address"}, {Name: "amount", Type: "uint256"}}},
+}
+
+// DecodeCall decodes a transaction's calldata if it calls one of the known ERC-20 functions
+func DecodeCall(tx model.Transaction) (model.DecodedCall, bool) {
+	input, err := hex.DecodeString(strings.TrimPrefix(tx.Input, "0x"))
+	if err != nil || len(input) < 4 {
+		return model.DecodedCall{}, false
+	}
+	selector, args := hex.EncodeToString(input[:4]), input[4:]
+
+	known, ok := knownCalls[selector]
+	if !ok || len(args) != 32*len(known.params) {
+		return model.DecodedCall{}, false
+	}
+
+	call := model.DecodedCall{Selector: "0x" + selector, Method: known.method}
+	for i, param := range known.params {
+		word := args[i*32 : (i+1)*32]
+		if param.Type == "address" {
+			param.Value = "0x" + hex.EncodeToString(word[12:])
+		} else {
+			param.Value = new(big.Int).SetBytes(word).String()
+		}
+		call.Params = append(call.Params, param)
+	}
+	return call, true
+}
+
+// DecodeTokenTransfer decodes a direct ERC-20 transfer or transferFrom call. Transfers made by
+// other contracts are not visible in calldata and are not detected.
+func DecodeTokenTransfer(tx model.Transaction) (model.TokenTransfer, bool) {
+	input, err := hex.DecodeString(strings.TrimPrefix(tx.Input, "0x"))
+	if err != nil || len(input) < 4 || tx.To == "" {
+		return model.TokenTransfer{}, false
+	}
+	selector, args := hex.EncodeToString(input[:4]), input[4:]
+
+	word := func(i int) []byte { return args[i*32 : (i+1)*32] }
+	address := func(i int) string { return "0x" + hex.EncodeToString(word(i)[12:]) }
+
+	transfer := model.TokenTransfer{Token: strings.ToLower(tx.To), TxHash: tx.Hash, BlockNumber: tx.BlockNumber}
+	switch {
+	case selector == transferSelector && len(args) == 64:
+		transfer.From = strings.ToLower(tx.From)
+		transfer.To = address(0)
+		transfer.Amount = new(big.Int).SetBytes(word(1)).String()
+	case selector == transferFromSelector && len(args) == 96:
+		transfer.From = address(0)
+		transfer.To = address(1)
+		transfer.Amount = new(big.Int).SetBytes(word(2)).String()
+	default:
+		return model.TokenTransfer{}, false
+	}
+	return transfer, true
+}
+
+// recordTokenTransfer stores and publishes a token transfer for each subscribed party of a mined
+// transfer call, skipping calls whose receipt shows they reverted
+func recordTokenTransfer(addressMap map[string]bool, tx model.Transaction) {
+	transfer, ok := DecodeTokenTransfer(tx)
+	if !ok || (!addressMap[transfer.From] && !addressMap[transfer.To]) {
+		return
+	}
+
+	receipt, err := GetTransactionReceipt(tx.Hash)
+	if err != nil {
+		processorLog.Warn("fetching receipt for token transfer failed", "block", tx.BlockNumber, "tx", tx.Hash, "error", err)
+		return
+	}
+	if receipt.Status == "0x0" {
+		return
+	}
+
+	for _, address := range []string{transfer.From, transfer.To} {
+		if addressMap[address] {
+			if err := model.SharedStore().SaveTokenTransfer(address, transfer); err != nil {
+				processorLog.Error("saving token transfer failed", "block", tx.BlockNumber, "tx", tx.Hash, "address", address, "error", err)
+				continue
+			}
+			publishEvent(model.Event{Type: model.EventTokenTransfer, Address: address, Data: transfer})
+		}
+		if transfer.From == transfer.To {
+			break
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"testing"
+
+	"ethereum-tx-parser/internal/model"
+)
+
+func TestDecodeTokenTransfer(t *testing.T) {
+	token := "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+	recipient := "000000000000000000000000abcdefabcdefabcdefabcdefabcdefabcdefabcd"
+	sender := "0000000000000000000000005555555555555555555555555555555555555555" // Token owner, not the spender sending the transaction
+	amount := "00000000000000000000000000000000000000000000000000000000000f4240" // 1000000
+
+	tests := []struct {
+		name     string
+		input    string
+		ok       bool
+		expected model.TokenTransfer
+	}{
+		{"transfer", "0xa9059cbb" + recipient + amount, true, model.TokenTransfer{
+			Token: token, From: subscribedAddress, To: "0xabcdefabcdefabcdefabcdefabcdefabcdefabcd", Amount: "1000000", TxHash: "0xt",
+		}},
+		{"transferFrom", "0x23b872dd" + sender + recipient + amount, true, model.TokenTransfer{
+			Token: token, From: "0x5555555555555555555555555555555555555555", To: "0xabcdefabcdefabcdefabcdefabcdefabcdefabcd", Amount: "1000000", TxHash: "0xt",
+		}},
+		{"truncated", "0xa9059cbb" + recipient, false, model.TokenTransfer{}},
+		{"other call", "0x095ea7b3" + recipient + amount, false, model.TokenTransfer{}},
+		{"plain transfer", "0x", false, model.TokenTransfer{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transfer, ok := DecodeTokenTransfer(model.Transaction{Hash: "0xt", From: subscribedAddress, To: token, Input: tt.input})
+			if ok != tt.ok || transfer != tt.expected {
+				t.Errorf("expected %+v (%v), got: %+v (%v)", tt.expected, tt.ok, transfer, ok)
+			}
+		})
+	}
+}
+
+func TestDecodeCall(t *testing.T) {
+	input := "0x095ea7b3" +
+		"000000000000000000000000abcdefabcdefabcdefabcdefabcdefabcdefabcd" +
+		"00000000000000000000000000000000000000000000000000000000000003e8"
+
+	call, ok := DecodeCall(model.Transaction{Input: input})
+	if !ok {
+		t.Fatalf("expected approve call to be decoded")
+	}
+	expected := []model.DecodedParam{
+		{Name: "spender", Type: "address", Value: "0xabcdefabcdefabcdefabcdefabcdefabcdefabcd"},
+		{Name: "amount", Type: "uint256", Value: "1000"},
+	}
+	if call.Method != "approve(address,uint256)" || call.Selector != "0x095ea7b3" || len(call.Params) != 2 || call.Params[0] != expected[0] || call.Params[1] != expected[1] {
+		t.Errorf("unexpected decoded call: %+v", call)
+	}
+
+	if _, ok := DecodeCall(model.Transaction{Input: "0xdeadbeef"}); ok {
+		t.Errorf("expected unknown selector not to be decoded")
+	}
+}
@@ -0,0 +1,9 @@
+package service
+
+import "ethereum-tx-parser/internal/tracing"
+
+// Tracers of the service components
+var (
+	processorTracer = tracing.Tracer("processor")
+	rpcTracer       = tracing.Tracer("rpc")
+)
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestRPCSpansPropagateTraceContext(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"busy"}}`))
+	}))
+	defer server.Close()
+	useRPCServer(t, server.URL)
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "process block")
+	if _, err := GetLatestETHBlockContext(ctx); err == nil {
+		t.Fatal("expected the node's error")
+	}
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 || spans[0].Name() != "eth_blockNumber" {
+		t.Fatalf("expected an eth_blockNumber span and its parent, got: %v", spans)
+	}
+	rpcSpan := spans[0]
+	if rpcSpan.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("expected the RPC span to be a child of the cycle span")
+	}
+	want := "00-" + rpcSpan.SpanContext().TraceID().String() + "-" + rpcSpan.SpanContext().SpanID().String() + "-01"
+	if traceparent != want {
+		t.Errorf("expected traceparent %s, got: %s", want, traceparent)
+	}
+
+	attributes := make(map[string]string)
+	for _, attribute := range rpcSpan.Attributes() {
+		attributes[string(attribute.Key)] = attribute.Value.Emit()
+	}
+	if attributes["rpc.method"] != "eth_blockNumber" || attributes["rpc.jsonrpc.error_code"] != "-32000" || attributes["http.response.status_code"] != "200" {
+		t.Errorf("unexpected RPC span attributes: %v", attributes)
+	}
+	if !strings.HasPrefix(server.URL, "http://"+attributes["server.address"]) {
+		t.Errorf("expected server.address to be the node's host, got: %s", attributes["server.address"])
+	}
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"encoding/hex"
+	"errors"
+	"ethereum-tx-parser/internal/crypto"
+	"ethereum-tx-parser/internal/model"
+	"ethereum-tx-parser/internal/rlp"
+	"fmt"
+	"strings"
+)
+
+// Transaction envelope types
+const (
+	txTypeLegacy     = 0
+	txTypeAccessList = 1 // EIP-2930
+	txTypeDynamicFee = 2 // EIP-1559
+	txTypeBlob       = 3 // EIP-4844
+	txTypeSetCode    = 4 // EIP-7702
+)
+
+// errUnsupportedTxType is returned for transaction types the encoder does not know
+var errUnsupportedTxType = errors.New("unsupported transaction type")
+
+// txEncoder RLP-encodes hex-encoded RPC fields, remembering the first decoding error
+type txEncoder struct {
+	err error
+}
+
+// quantity encodes a hex quantity such as a nonce or value
+func (e *txEncoder) quantity(value string) []byte {
+	if value == "" {
+		return rlp.EncodeBytes(nil)
+	}
+	n := parseHexBig(value)
+	if n == nil && e.err == nil {
+		e.err = fmt.Errorf("invalid quantity %q", value)
+	}
+	return rlp.EncodeBigInt(n)
+}
+
+// bytes encodes hex data such as an address or calldata
+func (e *txEncoder) bytes(value string) []byte {
+	b, err := hex.DecodeString(strings.TrimPrefix(value, "0x"))
+	if err != nil && e.err == nil {
+		e.err = fmt.Errorf("invalid hex data %q", value)
+	}
+	return rlp.EncodeBytes(b)
+}
+
+// byteList encodes a list of hex byte strings
+func (e *txEncoder) byteList(values []string) []byte {
+	items := make([][]byte, len(values))
+	for i, value := range values {
+		items[i] = e.bytes(value)
+	}
+	return rlp.EncodeList(items...)
+}
+
+// accessList encodes an EIP-2930 access list
+func (e *txEncoder) accessList(list []model.AccessTuple) []byte {
+	items := make([][]byte, len(list))
+	for i, tuple := range list {
+		items[i] = rlp.EncodeList(e.bytes(tuple.Address), e.byteList(tuple.StorageKeys))
+	}
+	return rlp.EncodeList(items...)
+}
+
+// authorizationList encodes an EIP-7702 authorization list
+func (e *txEncoder) authorizationList(list []model.Authorization) []byte {
+	items := make([][]byte, len(list))
+	for i, auth := range list {
+		items[i] = rlp.EncodeList(e.quantity(auth.ChainID), e.bytes(auth.Address), e.quantity(auth.Nonce), e.quantity(auth.YParity), e.quantity(auth.R), e.quantity(auth.S))
+	}
+	return rlp.EncodeList(items...)
+}
+
+// transactionType returns the numeric envelope type, treating a missing type as legacy
+func transactionType(tx model.Transaction) (uint64, error) {
+	if tx.Type == "" {
+		return txTypeLegacy, nil
+	}
+	txType, err := parseHexUint(tx.Type)
+	if err != nil || txType > txTypeSetCode {
+		return 0, fmt.Errorf("%w %q", errUnsupportedTxType, tx.Type)
+	}
+	return txType, nil
+}
+
+// payloadFields returns the encoded fields covered by the sender's signature, excluding the
+// EIP-155 chain id suffix of legacy transactions
+func payloadFields(e *txEncoder, tx model.Transaction, txType uint64) [][]byte {
+	switch txType {
+	case txTypeLegacy:
+		return [][]byte{e.quantity(tx.Nonce), e.quantity(tx.GasPrice), e.quantity(tx.Gas), e.bytes(tx.To), e.quantity(tx.Value), e.bytes(tx.Input)}
+	case txTypeAccessList:
+		return [][]byte{e.quantity(tx.ChainID), e.quantity(tx.Nonce), e.quantity(tx.GasPrice), e.quantity(tx.Gas), e.bytes(tx.To), e.quantity(tx.Value), e.bytes(tx.Input), e.accessList(tx.AccessList)}
+	case txTypeDynamicFee:
+		return [][]byte{e.quantity(tx.ChainID), e.quantity(tx.Nonce), e.quantity(tx.MaxPriorityFeePerGas), e.quantity(tx.MaxFeePerGas), e.quantity(tx.Gas), e.bytes(tx.To), e.quantity(tx.Value), e.bytes(tx.Input), e.accessList(tx.AccessList)}
+	case txTypeBlob:
+		return [][]byte{e.quantity(tx.ChainID), e.quantity(tx.Nonce), e.quantity(tx.MaxPriorityFeePerGas), e.quantity(tx.MaxFeePerGas), e.quantity(tx.Gas), e.bytes(tx.To), e.quantity(tx.Value), e.bytes(tx.Input), e.accessList(tx.AccessList), e.quantity(tx.MaxFeePerBlobGas), e.byteList(tx.BlobVersionedHashes)}
+	default:
+		return [][]byte{e.quantity(tx.ChainID), e.quantity(tx.Nonce), e.quantity(tx.MaxPriorityFeePerGas), e.quantity(tx.MaxFeePerGas), e.quantity(tx.Gas), e.bytes(tx.To), e.quantity(tx.Value), e.bytes(tx.Input), e.accessList(tx.AccessList), e.authorizationList(tx.AuthorizationList)}
+	}
+}
+
+// envelope wraps encoded fields as a legacy RLP list or a typed EIP-2718 envelope
+func envelope(txType uint64, fields [][]byte) []byte {
+	list := rlp.EncodeList(fields...)
+	if txType == txTypeLegacy {
+		return list
+	}
+	return append([]byte{byte(txType)}, list...)
+}
+
+// encodeTransaction returns the canonical signed encoding of a transaction, whose Keccak-256
+// hash is the transaction hash
+func encodeTransaction(tx model.Transaction) ([]byte, error) {
+	txType, err := transactionType(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &txEncoder{}
+	fields := payloadFields(e, tx, txType)
+	if txType == txTypeLegacy {
+		fields = append(fields, e.quantity(tx.V))
+	} else {
+		fields = append(fields, e.quantity(yParity(tx)))
+	}
+	fields = append(fields, e.quantity(tx.R), e.quantity(tx.S))
+	if e.err != nil {
+		return nil, e.err
+	}
+	return envelope(txType, fields), nil
+}
+
+// signingHash returns the hash signed by the sender. Legacy transactions derive their EIP-155
+// chain id from v, so they must already carry a signature.
+func signingHash(tx model.Transaction) ([]byte, error) {
+	txType, err := transactionType(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &txEncoder{}
+	fields := payloadFields(e, tx, txType)
+	if txType == txTypeLegacy {
+		v, err := parseHexUint(tx.V)
+		if err != nil {
+			return nil, fmt.Errorf("invalid v %q", tx.V)
+		}
+		// EIP-155 replay protection signs over (chainId, 0, 0) as well
+		if v >= 35 {
+			fields = append(fields, rlp.EncodeUint((v-35)/2), rlp.EncodeUint(0), rlp.EncodeUint(0))
+		}
+	}
+	if e.err != nil {
+		return nil, e.err
+	}
+	return crypto.Keccak256(envelope(txType, fields)), nil
+}
+
+// recoveryID returns the parity of the signature's nonce point
+func recoveryID(tx model.Transaction) (byte, error) {
+	txType, err := transactionType(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	if txType == txTypeLegacy {
+		v, err := parseHexUint(tx.V)
+		switch {
+		case err != nil:
+			return 0, fmt.Errorf("invalid v %q", tx.V)
+		case v == 27 || v == 28:
+			return byte(v - 27), nil
+		case v >= 35:
+			return byte((v - 35) % 2), nil
+		default:
+			return 0, fmt.Errorf("invalid v %q", tx.V)
+		}
+	}
+
+	parity, err := parseHexUint(yParity(tx))
+	if err != nil || parity > 1 {
+		return 0, fmt.Errorf("invalid y parity %q", yParity(tx))
+	}
+	return byte(parity), nil
+}
+
+// yParity returns the signature parity of a typed transaction; older nodes only report it as v
+func yParity(tx model.Transaction) string {
+	if tx.YParity != "" {
+		return tx.YParity
+	}
+	return tx.V
+}
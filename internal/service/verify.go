@@ -0,0 +1,96 @@
+package service
+
+import (
+	"encoding/hex"
+	"errors"
+	"ethereum-tx-parser/internal/crypto"
+	"ethereum-tx-parser/internal/model"
+	"fmt"
+	"strings"
+)
+
+// Transaction verification modes
+const (
+	VerifyOff    = "off"    // Trust the hash and sender reported by the RPC provider
+	VerifyFlag   = "flag"   // Store mismatching transactions with a VerificationError
+	VerifyReject = "reject" // Discard mismatching transactions
+)
+
+// VerificationMode controls how matched transactions are checked against their signed payload
+var VerificationMode = VerifyOff
+
+// HomesteadBlock is the first block requiring low-s signatures (EIP-2); legacy transactions of
+// earlier blocks may carry high-s signatures. Defaults to Ethereum mainnet.
+var HomesteadBlock = uint64(1150000)
+
+// VerifyTransaction recomputes the hash of a transaction from its fields and recovers the sender
+// from its signature, returning an error if either differs from what the RPC provider reported
+func VerifyTransaction(tx model.Transaction) error {
+	encoded, err := encodeTransaction(tx)
+	if err != nil {
+		return err
+	}
+	hash := "0x" + hex.EncodeToString(crypto.Keccak256(encoded))
+	if !strings.EqualFold(hash, tx.Hash) {
+		return fmt.Errorf("hash mismatch: reported %s, computed %s", tx.Hash, hash)
+	}
+
+	sigHash, err := signingHash(tx)
+	if err != nil {
+		return err
+	}
+	recID, err := recoveryID(tx)
+	if err != nil {
+		return err
+	}
+	r, s := parseHexBig(tx.R), parseHexBig(tx.S)
+	if r == nil || s == nil {
+		return errors.New("missing signature values")
+	}
+	if !crypto.ValidSignatureValues(r, s, postHomestead(tx)) {
+		return errors.New("invalid signature values")
+	}
+	sender, err := crypto.RecoverAddress(sigHash, r, s, recID)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(sender, tx.From) {
+		return fmt.Errorf("sender mismatch: reported %s, recovered %s", tx.From, sender)
+	}
+	return nil
+}
+
+// postHomestead reports whether the low-s rule applies to a transaction. Typed, EIP-155 and
+// pending transactions all postdate Homestead.
+func postHomestead(tx model.Transaction) bool {
+	if txType, err := transactionType(tx); err != nil || txType != txTypeLegacy {
+		return true
+	}
+	if v, err := parseHexUint(tx.V); err != nil || v >= 35 {
+		return true
+	}
+	block, err := parseHexUint(tx.BlockNumber)
+	return err != nil || block >= HomesteadBlock
+}
+
+// verifyMatchedTransaction applies VerificationMode to a transaction about to be stored.
+// It returns false if the transaction must be discarded. A transaction of a type the encoder does
+// not know cannot be verified and fails like a mismatching one.
+func verifyMatchedTransaction(tx *model.Transaction) bool {
+	if VerificationMode == VerifyOff {
+		return true
+	}
+
+	err := VerifyTransaction(*tx)
+	switch {
+	case err == nil:
+		return true
+	case VerificationMode == VerifyReject:
+		processorLog.Warn("rejecting transaction", "block", tx.BlockNumber, "tx", tx.Hash, "error", err)
+		return false
+	default:
+		processorLog.Warn("flagging transaction", "block", tx.BlockNumber, "tx", tx.Hash, "error", err)
+		tx.VerificationError = err.Error()
+		return true
+	}
+}
@@ -0,0 +1,274 @@
+package service
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ethereum-tx-parser/internal/crypto"
+	"ethereum-tx-parser/internal/model"
+)
+
+var testKey, _ = new(big.Int).SetString("4646464646464646464646464646464646464646464646464646464646464646", 16)
+
+// eip155Transaction is the example transaction from EIP-155, signed with testKey
+func eip155Transaction() model.Transaction {
+	raw, _ := hex.DecodeString("f86c098504a817c800825208943535353535353535353535353535353535353535880de0b6b3a76400008025a028ef61340bd939bc2195fe537567866003e1a15d3c71ff63e1590620aa636276a067cbe9d8997f761aecb703304b3800ccf555c9f3dc64214b297fb1966a3b6d83")
+	return model.Transaction{
+		Hash:     "0x" + hex.EncodeToString(crypto.Keccak256(raw)),
+		From:     "0x9d8a62f656a8d1615c1294fd71e9cfb3e4855a4f",
+		To:       "0x3535353535353535353535353535353535353535",
+		Value:    "0xde0b6b3a7640000",
+		Nonce:    "0x9",
+		GasPrice: "0x4a817c800",
+		Gas:      "0x5208",
+		Input:    "0x",
+		V:        "0x25",
+		R:        "0x28ef61340bd939bc2195fe537567866003e1a15d3c71ff63e1590620aa636276",
+		S:        "0x67cbe9d8997f761aecb703304b3800ccf555c9f3dc64214b297fb1966a3b6d83",
+	}
+}
+
+// signTransaction fills in a signature made with testKey, and the sender and hash of a typed
+// transaction
+func signTransaction(t *testing.T, tx model.Transaction, yParity, r, s string) model.Transaction {
+	tx.R = r
+	tx.S = s
+	tx.YParity = yParity
+	tx.V = yParity
+	tx.From = crypto.PrivateKeyToAddress(testKey)
+
+	encoded, err := encodeTransaction(tx)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	tx.Hash = "0x" + hex.EncodeToString(crypto.Keccak256(encoded))
+	return tx
+}
+
+func TestVerifyTransaction(t *testing.T) {
+	accessList := []model.AccessTuple{{
+		Address:     "0x3535353535353535353535353535353535353535",
+		StorageKeys: []string{"0x0000000000000000000000000000000000000000000000000000000000000001"},
+	}}
+	const sigR = "0x5ad2703f5b4f4b9dea4c28fa30d86d3781d28e09dd51aae1208de80bb6155bee"
+	typed := model.Transaction{
+		To: "0x3535353535353535353535353535353535353535", Value: "0x1", Nonce: "0x1", Gas: "0x5208", Input: "0x", ChainID: "0x1", AccessList: accessList,
+	}
+
+	accessListTx := typed
+	accessListTx.Type = "0x1"
+	accessListTx.GasPrice = "0x3b9aca00"
+
+	dynamicFeeTx := typed
+	dynamicFeeTx.Type = "0x2"
+	dynamicFeeTx.MaxFeePerGas = "0x77359400"
+	dynamicFeeTx.MaxPriorityFeePerGas = "0x3b9aca00"
+
+	blobTx := dynamicFeeTx
+	blobTx.Type = "0x3"
+	blobTx.MaxFeePerBlobGas = "0x1"
+	blobTx.BlobVersionedHashes = []string{"0x01a915e4d060149eb4365960e6a7a45f334393093061116b197e3240065ff2d8"}
+
+	setCodeTx := dynamicFeeTx
+	setCodeTx.Type = "0x4"
+	setCodeTx.AuthorizationList = []model.Authorization{{
+		ChainID: "0x1", Address: "0x3535353535353535353535353535353535353535", Nonce: "0x2", YParity: "0x1", R: "0x1234", S: "0x5678",
+	}}
+
+	tests := []struct {
+		name string
+		tx   model.Transaction
+	}{
+		{"Legacy EIP-155", eip155Transaction()},
+		{"EIP-7702", signTransaction(t, setCodeTx, "0x1", sigR, "0x41b9b41a82fe07f67fc768f237b324a90bf5e375123f35385ede775cf70cc3dc")},
+		{"EIP-2930", signTransaction(t, accessListTx, "0x0", sigR, "0x22e7d3d3e75bdd763e6d5c8f1bbb872c0093bf36d5c6cb7f4106fe58546248a9")},
+		{"EIP-1559", signTransaction(t, dynamicFeeTx, "0x0", sigR, "0x100d8f46b8fa7c05a68900d1c4b7cce61bcc7508e01a5f7b37f1bd1a1750e90e")},
+		{"EIP-4844", signTransaction(t, blobTx, "0x0", sigR, "0x7f8b003a84b5dd6a22ff0d7a86fe14a6e52b5e2cf6626ffaef98a1ab0cb82539")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := VerifyTransaction(tt.tx); err != nil {
+				t.Fatalf("expected valid transaction, got: %v", err)
+			}
+
+			wrongHash := tt.tx
+			wrongHash.Hash = "0x" + hex.EncodeToString(make([]byte, 32))
+			if err := VerifyTransaction(wrongHash); err == nil {
+				t.Errorf("expected hash mismatch to be detected")
+			}
+
+			wrongSender := tt.tx
+			wrongSender.From = "0x0000000000000000000000000000000000000001"
+			if err := VerifyTransaction(wrongSender); err == nil {
+				t.Errorf("expected sender mismatch to be detected")
+			}
+
+			tampered := tt.tx
+			tampered.Value = "0x2"
+			if err := VerifyTransaction(tampered); err == nil {
+				t.Errorf("expected tampered value to be detected")
+			}
+		})
+	}
+}
+
+func TestVerifyTransactionHighS(t *testing.T) {
+	curveN, _ := new(big.Int).SetString("fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141", 16)
+	lowS, _ := new(big.Int).SetString("34cf30cca305cc417bc058118bf2b43011e4af482224dd89f7c9fa094a5caffe", 16)
+
+	// A pre-EIP-155 legacy transaction signed with testKey, rewritten to the equivalent high-s
+	// signature, which flips the parity
+	tx := model.Transaction{
+		From:     crypto.PrivateKeyToAddress(testKey),
+		To:       "0x3535353535353535353535353535353535353535",
+		Value:    "0x1",
+		Nonce:    "0x0",
+		GasPrice: "0x4a817c800",
+		Gas:      "0x5208",
+		Input:    "0x",
+		V:        "0x1b",
+		R:        "0x5ad2703f5b4f4b9dea4c28fa30d86d3781d28e09dd51aae1208de80bb6155bee",
+		S:        fmt.Sprintf("0x%x", new(big.Int).Sub(curveN, lowS)),
+	}
+	encoded, err := encodeTransaction(tx)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	tx.Hash = "0x" + hex.EncodeToString(crypto.Keccak256(encoded))
+
+	tests := []struct {
+		block     string
+		expectErr bool
+	}{
+		{"0x1", false},
+		{fmt.Sprintf("0x%x", HomesteadBlock-1), false},
+		{fmt.Sprintf("0x%x", HomesteadBlock), true},
+		{"", true},
+	}
+
+	for _, tt := range tests {
+		tx.BlockNumber = tt.block
+		if err := VerifyTransaction(tx); (err != nil) != tt.expectErr {
+			t.Errorf("block %q: expected error %v, got: %v", tt.block, tt.expectErr, err)
+		}
+	}
+}
+
+func TestFilterTransactionsVerificationMode(t *testing.T) {
+	valid := eip155Transaction()
+	forged := eip155Transaction()
+	forged.Hash = "0xforged"
+
+	tests := []struct {
+		mode           string
+		expectedHashes int
+		expectFlagged  bool
+	}{
+		{VerifyOff, 2, false},
+		{VerifyFlag, 2, true},
+		{VerifyReject, 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			mockStore := &MockStore{subscriptions: map[string]bool{valid.From: true}}
+			model.InitializeStore(mockStore)
+			VerificationMode = tt.mode
+			defer func() { VerificationMode = VerifyOff }()
+
+			if err := FilterTransactionsByAddress([]model.Transaction{valid, forged}); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+
+			if len(mockStore.transactions) != tt.expectedHashes {
+				t.Fatalf("expected %d stored transactions, got: %d", tt.expectedHashes, len(mockStore.transactions))
+			}
+			if mockStore.transactions[0].VerificationError != "" {
+				t.Errorf("expected valid transaction not to be flagged, got: %s", mockStore.transactions[0].VerificationError)
+			}
+			if flagged := len(mockStore.transactions) > 1 && mockStore.transactions[1].VerificationError != ""; flagged != tt.expectFlagged {
+				t.Errorf("expected forged transaction flagged to be %v", tt.expectFlagged)
+			}
+		})
+	}
+}
+
+func TestFilterTransactionsUnknownType(t *testing.T) {
+	unknown := eip155Transaction()
+	unknown.Type = "0x5"
+
+	tests := []struct {
+		mode           string
+		expectedStored int
+		expectFlagged  bool
+	}{
+		{VerifyFlag, 1, true},
+		{VerifyReject, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			mockStore := &MockStore{subscriptions: map[string]bool{unknown.From: true}}
+			model.InitializeStore(mockStore)
+			VerificationMode = tt.mode
+			defer func() { VerificationMode = VerifyOff }()
+
+			if err := FilterTransactionsByAddress([]model.Transaction{unknown}); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if len(mockStore.transactions) != tt.expectedStored {
+				t.Fatalf("expected %d stored transactions, got: %d", tt.expectedStored, len(mockStore.transactions))
+			}
+			if tt.expectFlagged && !strings.Contains(mockStore.transactions[0].VerificationError, "unsupported transaction type") {
+				t.Errorf("expected the transaction to be flagged as unsupported, got: %q", mockStore.transactions[0].VerificationError)
+			}
+		})
+	}
+}
+
+func TestFilterTransactionsVerifiesTokenTransfers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(model.JsonRPCReceiptResponse{JsonRPC: "2.0", ID: 1, Result: model.Receipt{Status: "0x1"}})
+	}))
+	defer server.Close()
+	useRPCServer(t, server.URL)
+
+	// A forged transfer to a subscribed address, sent by and to unsubscribed addresses
+	forged := model.Transaction{
+		Hash:  "0xforged",
+		From:  "0x9999999999999999999999999999999999999999",
+		To:    "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48",
+		Input: "0xa9059cbb000000000000000000000000" + subscribedAddress[2:] + "0000000000000000000000000000000000000000000000000000000000000001",
+	}
+
+	tests := []struct {
+		mode              string
+		expectedTransfers int
+	}{
+		{VerifyOff, 1},
+		{VerifyReject, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			mockStore := &MockStore{subscriptions: map[string]bool{subscribedAddress: true}}
+			model.InitializeStore(mockStore)
+			VerificationMode = tt.mode
+			defer func() { VerificationMode = VerifyOff }()
+
+			if err := FilterTransactionsByAddress([]model.Transaction{forged}); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if transfers := mockStore.GetTokenTransfers(subscribedAddress); len(transfers) != tt.expectedTransfers {
+				t.Errorf("expected %d token transfers, got: %+v", tt.expectedTransfers, transfers)
+			}
+		})
+	}
+}
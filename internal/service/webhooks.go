@@ -0,0 +1,542 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"ethereum-tx-parser/internal/model"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Webhook request headers
+const (
+	WebhookIDHeader        = "X-Webhook-Id"        // Delivery ID, stable across retries
+	WebhookEventHeader     = "X-Webhook-Event"     // Event type
+	WebhookTimestampHeader = "X-Webhook-Timestamp" // Unix time the request was signed
+	WebhookSignatureHeader = "X-Webhook-Signature" // "sha256=" + hex HMAC of timestamp + "." + body
+)
+
+var (
+	// WebhookMaxAttempts is how many times a delivery is tried before it is moved to the dead-letter state
+	WebhookMaxAttempts = 8
+
+	// WebhookRetryBase is the delay before the first retry; each further retry doubles it
+	WebhookRetryBase = 5 * time.Second
+
+	// WebhookRetryMax caps the delay between retries
+	WebhookRetryMax = 10 * time.Minute
+
+	// WebhookTimeout bounds each delivery request
+	WebhookTimeout = 10 * time.Second
+
+	// WebhookDeliveredHistory is how many delivered deliveries are kept for inspection
+	WebhookDeliveredHistory = 1000
+
+	// WebhookDeadHistory is how many dead-lettered deliveries are kept for inspection and replay
+	WebhookDeadHistory = 1000
+
+	// WebhookAllowPrivate lets webhooks reach loopback, private and link-local addresses, for
+	// receivers on the same host or network. Off by default, so webhooks cannot probe internal services.
+	WebhookAllowPrivate = false
+
+	// WebhookStatePath is the file webhooks and their queue are persisted to; empty keeps them in memory only
+	WebhookStatePath = ""
+
+	// ErrWebhookNotFound is returned for an unknown webhook or delivery ID
+	ErrWebhookNotFound = errors.New("webhook not found")
+
+	// ErrInvalidWebhook is returned when a webhook registration is malformed
+	ErrInvalidWebhook = errors.New("invalid webhook")
+)
+
+var (
+	webhookMu         sync.Mutex
+	webhooks          = make(map[string]model.Webhook)
+	webhookDeliveries []*model.WebhookDelivery // Oldest first
+	webhookDirty      bool                     // Changed since the state was last saved
+
+	webhookSaveMu sync.Mutex // Serializes writes of the state file
+
+	// Deliveries connect to receivers directly, never through a proxy, so that checkWebhookDial
+	// sees the receiver's address
+	webhookClient = &http.Client{Transport: &http.Transport{
+		DialContext:         (&net.Dialer{Timeout: 30 * time.Second, Control: checkWebhookDial}).DialContext,
+		TLSHandshakeTimeout: 10 * time.Second,
+		MaxIdleConns:        100,
+		IdleConnTimeout:     90 * time.Second,
+	}}
+)
+
+// blockedWebhookNetworks are reserved ranges webhooks may not reach, beyond the loopback,
+// private, link-local and multicast ranges netip classifies
+var blockedWebhookNetworks = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),     // "This network"
+	netip.MustParsePrefix("100.64.0.0/10"), // Carrier-grade NAT, often internal in clouds
+}
+
+// knownEventTypes are the event types a webhook can select
+var knownEventTypes = map[string]bool{
+	model.EventAlert:         true,
+	model.EventTransaction:   true,
+	model.EventTokenTransfer: true,
+	model.EventBlock:         true,
+	model.EventReorg:         true,
+}
+
+// webhookState is the persisted form of the webhook registry and queue
+type webhookState struct {
+	Webhooks   []model.Webhook          `json:"webhooks"`
+	Deliveries []*model.WebhookDelivery `json:"deliveries"`
+}
+
+// CreateWebhook validates and registers a webhook. A signing secret is generated if none is given.
+func CreateWebhook(webhook model.Webhook) (model.Webhook, error) {
+	target, err := url.Parse(webhook.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return model.Webhook{}, fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidWebhook)
+	}
+	if !WebhookAllowPrivate && !publicWebhookHost(target.Hostname()) {
+		return model.Webhook{}, fmt.Errorf("%w: url must not point at a loopback, private or link-local address", ErrInvalidWebhook)
+	}
+
+	addresses := make([]string, 0, len(webhook.Addresses))
+	for _, address := range webhook.Addresses {
+		if !isValidEthereumAddress(address) {
+			return model.Webhook{}, fmt.Errorf("%w: %s is not an Ethereum address", ErrInvalidWebhook, address)
+		}
+		addresses = append(addresses, strings.ToLower(address))
+	}
+	for _, eventType := range webhook.Events {
+		if !knownEventTypes[eventType] {
+			return model.Webhook{}, fmt.Errorf("%w: unknown event type %q", ErrInvalidWebhook, eventType)
+		}
+	}
+
+	if webhook.Secret == "" {
+		webhook.Secret = randomID(32)
+	}
+	webhook.ID = randomID(8)
+	webhook.Addresses = addresses
+	webhook.CreatedAt = time.Now().UTC()
+
+	webhookMu.Lock()
+	defer webhookMu.Unlock()
+	webhooks[webhook.ID] = webhook
+	webhookDirty = true
+	return webhook, nil
+}
+
+// GetWebhooks returns the registered webhooks, oldest first, without their secrets
+func GetWebhooks() []model.Webhook {
+	webhookMu.Lock()
+	defer webhookMu.Unlock()
+
+	list := make([]model.Webhook, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		webhook.Secret = ""
+		list = append(list, webhook)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].CreatedAt.Equal(list[j].CreatedAt) {
+			return list[i].ID < list[j].ID
+		}
+		return list[i].CreatedAt.Before(list[j].CreatedAt)
+	})
+	return list
+}
+
+// DeleteWebhook removes a webhook and discards its queued deliveries
+func DeleteWebhook(id string) error {
+	webhookMu.Lock()
+	defer webhookMu.Unlock()
+
+	if _, ok := webhooks[id]; !ok {
+		return ErrWebhookNotFound
+	}
+	delete(webhooks, id)
+
+	kept := webhookDeliveries[:0]
+	for _, delivery := range webhookDeliveries {
+		if delivery.WebhookID != id {
+			kept = append(kept, delivery)
+		}
+	}
+	webhookDeliveries = kept
+	webhookDirty = true
+	return nil
+}
+
+// GetWebhookDeliveries returns the deliveries of a webhook, oldest first, optionally only those
+// with the given status
+func GetWebhookDeliveries(webhookID string, status string) ([]model.WebhookDelivery, error) {
+	webhookMu.Lock()
+	defer webhookMu.Unlock()
+
+	if _, ok := webhooks[webhookID]; !ok {
+		return nil, ErrWebhookNotFound
+	}
+
+	var list []model.WebhookDelivery
+	for _, delivery := range webhookDeliveries {
+		if delivery.WebhookID == webhookID && (status == "" || delivery.Status == status) {
+			list = append(list, *delivery)
+		}
+	}
+	return list, nil
+}
+
+// ReplayWebhookDelivery queues a delivery to be sent again on the next dispatch, with a fresh
+// attempt budget. It is typically used on dead-lettered deliveries once the receiver is fixed.
+func ReplayWebhookDelivery(webhookID string, deliveryID string) (model.WebhookDelivery, error) {
+	webhookMu.Lock()
+	defer webhookMu.Unlock()
+
+	for _, delivery := range webhookDeliveries {
+		if delivery.ID == deliveryID && delivery.WebhookID == webhookID {
+			delivery.Status = model.DeliveryPending
+			delivery.Attempts = 0
+			delivery.NextAttemptAt = time.Now().UTC()
+			delivery.DeliveredAt = time.Time{}
+			webhookDirty = true
+			return *delivery, nil
+		}
+	}
+	return model.WebhookDelivery{}, ErrWebhookNotFound
+}
+
+// SignWebhookPayload returns the signature header value for a request body sent at timestamp.
+// Receivers recompute it with their copy of the secret and should reject stale timestamps.
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// enqueueWebhookDeliveries queues an event for every webhook whose filters match it.
+// Address filters only apply to events about an address. The queue is saved later, by
+// SaveWebhooks, so publishing never waits on the disk.
+func enqueueWebhookDeliveries(event model.Event) {
+	webhookMu.Lock()
+	defer webhookMu.Unlock()
+
+	now := time.Now().UTC()
+	for _, webhook := range webhooks {
+		if !webhookMatches(webhook, event) {
+			continue
+		}
+		webhookDeliveries = append(webhookDeliveries, &model.WebhookDelivery{
+			ID:            randomID(8),
+			WebhookID:     webhook.ID,
+			Event:         event,
+			Status:        model.DeliveryPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		})
+		webhookDirty = true
+	}
+}
+
+// webhookMatches reports whether a webhook's filters select an event
+func webhookMatches(webhook model.Webhook, event model.Event) bool {
+	if len(webhook.Events) > 0 && !containsString(webhook.Events, event.Type) {
+		return false
+	}
+	if event.Address == "" {
+		return true
+	}
+	if len(webhook.Addresses) > 0 && !containsString(webhook.Addresses, strings.ToLower(event.Address)) {
+		return false
+	}
+	// A webhook never receives events about addresses its tenant, or the default tenant for
+	// webhooks created without an API key, does not subscribe to
+	return LocalParser{Tenant: webhook.Tenant}.CanRead(event.Address)
+}
+
+// DeliverDueWebhooks attempts every pending delivery whose next attempt is due and returns how
+// many were delivered. Failed deliveries are retried with exponential backoff and dead-lettered
+// after WebhookMaxAttempts attempts.
+func DeliverDueWebhooks() int {
+	type attempt struct {
+		delivery model.WebhookDelivery
+		webhook  model.Webhook
+	}
+
+	webhookMu.Lock()
+	now := time.Now()
+	var due []attempt
+	for _, delivery := range webhookDeliveries {
+		if delivery.Status == model.DeliveryPending && !delivery.NextAttemptAt.After(now) {
+			due = append(due, attempt{delivery: *delivery, webhook: webhooks[delivery.WebhookID]})
+		}
+	}
+	webhookMu.Unlock()
+
+	delivered := 0
+	for _, a := range due {
+		err := sendWebhook(a.webhook, a.delivery)
+
+		webhookMu.Lock()
+		delivery := findDelivery(a.delivery.ID)
+		if delivery != nil && delivery.Status == model.DeliveryPending {
+			delivery.Attempts++
+			if err == nil {
+				delivery.Status = model.DeliveryDelivered
+				delivery.DeliveredAt = time.Now().UTC()
+				delivery.LastError = ""
+				delivered++
+			} else {
+				delivery.LastError = err.Error()
+				if delivery.Attempts >= WebhookMaxAttempts {
+					delivery.Status = model.DeliveryDead
+					webhookLog.Warn("delivery dead-lettered", "delivery", delivery.ID, "webhook", a.webhook.ID, "url", a.webhook.URL, "attempts", delivery.Attempts, "error", err)
+				} else {
+					delivery.NextAttemptAt = time.Now().UTC().Add(retryDelay(delivery.Attempts))
+				}
+			}
+		}
+		webhookMu.Unlock()
+	}
+
+	if len(due) > 0 {
+		webhookMu.Lock()
+		pruneWebhookDeliveries(model.DeliveryDelivered, WebhookDeliveredHistory)
+		pruneWebhookDeliveries(model.DeliveryDead, WebhookDeadHistory)
+		webhookDirty = true
+		webhookMu.Unlock()
+	}
+	return delivered
+}
+
+// sendWebhook posts a signed event to a webhook and treats any non-2xx response as a failure
+func sendWebhook(webhook model.Webhook, delivery model.WebhookDelivery) error {
+	body, err := json.Marshal(delivery.Event)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), WebhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookIDHeader, delivery.ID)
+	req.Header.Set(WebhookEventHeader, delivery.Event.Type)
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(webhook.Secret, timestamp, body))
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("receiver responded %s", resp.Status)
+	}
+	return nil
+}
+
+// retryDelay is the backoff after the given number of failed attempts
+func retryDelay(attempts int) time.Duration {
+	delay := WebhookRetryBase
+	for i := 1; i < attempts && delay < WebhookRetryMax; i++ {
+		delay *= 2
+	}
+	if delay > WebhookRetryMax {
+		delay = WebhookRetryMax
+	}
+	return delay
+}
+
+// findDelivery returns the queued delivery with an ID. webhookMu must be held.
+func findDelivery(id string) *model.WebhookDelivery {
+	for _, delivery := range webhookDeliveries {
+		if delivery.ID == id {
+			return delivery
+		}
+	}
+	return nil
+}
+
+// pruneWebhookDeliveries drops the oldest deliveries with a status beyond the most recent keep.
+// Pending deliveries are never pruned. webhookMu must be held.
+func pruneWebhookDeliveries(status string, keep int) {
+	count := 0
+	for _, delivery := range webhookDeliveries {
+		if delivery.Status == status {
+			count++
+		}
+	}
+
+	excess := count - keep
+	if excess <= 0 {
+		return
+	}
+	kept := webhookDeliveries[:0]
+	for _, delivery := range webhookDeliveries {
+		if delivery.Status == status && excess > 0 {
+			excess--
+			continue
+		}
+		kept = append(kept, delivery)
+	}
+	webhookDeliveries = kept
+}
+
+// LoadWebhooks restores webhooks and their queue from WebhookStatePath, if it exists
+func LoadWebhooks() error {
+	if WebhookStatePath == "" {
+		return nil
+	}
+	data, err := os.ReadFile(WebhookStatePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var state webhookState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("reading %s: %w", WebhookStatePath, err)
+	}
+
+	webhookMu.Lock()
+	defer webhookMu.Unlock()
+	webhooks = make(map[string]model.Webhook)
+	for _, webhook := range state.Webhooks {
+		webhooks[webhook.ID] = webhook
+	}
+	webhookDeliveries = state.Deliveries
+	webhookDirty = false
+	return nil
+}
+
+// SaveWebhooks writes webhooks and their queue to WebhookStatePath if they changed since the
+// last save. The server calls it after each dispatch round and on shutdown. The file is replaced
+// atomically so a crash never leaves it half-written.
+func SaveWebhooks() error {
+	if WebhookStatePath == "" {
+		return nil
+	}
+	webhookSaveMu.Lock()
+	defer webhookSaveMu.Unlock()
+
+	// Copy the state under the lock, and encode and write it without holding up publishers
+	webhookMu.Lock()
+	if !webhookDirty {
+		webhookMu.Unlock()
+		return nil
+	}
+	state := webhookState{Deliveries: make([]*model.WebhookDelivery, len(webhookDeliveries))}
+	for i, delivery := range webhookDeliveries {
+		copied := *delivery
+		state.Deliveries[i] = &copied
+	}
+	for _, webhook := range webhooks {
+		state.Webhooks = append(state.Webhooks, webhook)
+	}
+	webhookDirty = false
+	webhookMu.Unlock()
+
+	err := writeWebhookState(state)
+	if err != nil {
+		webhookMu.Lock()
+		webhookDirty = true
+		webhookMu.Unlock()
+	}
+	return err
+}
+
+func writeWebhookState(state webhookState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	tmp := WebhookStatePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, WebhookStatePath)
+}
+
+// publicWebhookHost reports whether a URL host is allowed as a webhook receiver: any name other
+// than localhost, or an IP address outside the blocked ranges. Names are checked again once
+// resolved, when each delivery connects.
+func publicWebhookHost(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+	addr, err := netip.ParseAddr(host)
+	return err != nil || publicWebhookAddr(addr)
+}
+
+// publicWebhookAddr reports whether a webhook may connect to an IP address
+func publicWebhookAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() || addr.IsUnspecified() {
+		return false
+	}
+	for _, network := range blockedWebhookNetworks {
+		if network.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkWebhookDial refuses connections to blocked addresses, including those a public name
+// resolves to and those a receiver redirects to
+func checkWebhookDial(network string, address string, _ syscall.RawConn) error {
+	if WebhookAllowPrivate {
+		return nil
+	}
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !publicWebhookAddr(addrPort.Addr()) {
+		return fmt.Errorf("webhook receiver %s is a loopback, private or link-local address", addrPort.Addr())
+	}
+	return nil
+}
+
+// randomID returns n random bytes as hex
+func randomID(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
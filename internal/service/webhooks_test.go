@@ -0,0 +1,253 @@
+package service
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ethereum-tx-parser/internal/model"
+)
+
+// resetWebhooks clears registered webhooks, retries failed deliveries immediately and lets
+// webhooks reach the local test receivers
+func resetWebhooks(t *testing.T) {
+	webhookMu.Lock()
+	webhooks = make(map[string]model.Webhook)
+	webhookDeliveries = nil
+	webhookDirty = false
+	webhookMu.Unlock()
+
+	base, allowPrivate := WebhookRetryBase, WebhookAllowPrivate
+	WebhookRetryBase, WebhookAllowPrivate = 0, true
+	t.Cleanup(func() { WebhookRetryBase, WebhookAllowPrivate = base, allowPrivate })
+}
+
+func TestWebhookDeliveryIsSigned(t *testing.T) {
+	resetWebhooks(t)
+	InitializeModelLayer()
+	Subscribe(subscribedAddress)
+
+	received := make(chan *http.Request, 1)
+	var body []byte
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		received <- r
+	}))
+	defer receiver.Close()
+
+	webhook, err := CreateWebhook(model.Webhook{URL: receiver.URL, Secret: "s3cret", Events: []string{model.EventTransaction}})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	FilterTransactionsByAddress([]model.Transaction{{Hash: "0xabc", From: subscribedAddress, To: "0x0000000000000000000000000000000000000002"}})
+	RecordProcessedBlock(model.Block{Number: "0x1", Hash: "0xb1"}) // Filtered out by event type
+
+	if delivered := DeliverDueWebhooks(); delivered != 1 {
+		t.Fatalf("expected 1 delivery, got: %d", delivered)
+	}
+
+	r := <-received
+	timestamp, _ := strconv.ParseInt(r.Header.Get(WebhookTimestampHeader), 10, 64)
+	if r.Header.Get(WebhookSignatureHeader) != SignWebhookPayload("s3cret", timestamp, body) {
+		t.Errorf("signature does not match body and timestamp")
+	}
+	if r.Header.Get(WebhookEventHeader) != model.EventTransaction {
+		t.Errorf("expected event header %q, got: %q", model.EventTransaction, r.Header.Get(WebhookEventHeader))
+	}
+
+	deliveries, _ := GetWebhookDeliveries(webhook.ID, model.DeliveryDelivered)
+	if len(deliveries) != 1 || deliveries[0].ID != r.Header.Get(WebhookIDHeader) {
+		t.Errorf("expected the delivery to be recorded as delivered, got: %+v", deliveries)
+	}
+}
+
+func TestWebhookRetriesAndDeadLetter(t *testing.T) {
+	resetWebhooks(t)
+	maxAttempts := WebhookMaxAttempts
+	WebhookMaxAttempts = 3
+	defer func() { WebhookMaxAttempts = maxAttempts }()
+
+	var healthy atomic.Bool
+	var calls atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer receiver.Close()
+
+	webhook, _ := CreateWebhook(model.Webhook{URL: receiver.URL})
+	publishEvent(model.Event{Type: model.EventBlock})
+
+	for i := 0; i < 5; i++ {
+		DeliverDueWebhooks()
+	}
+	if calls.Load() != 3 {
+		t.Errorf("expected 3 attempts, got: %d", calls.Load())
+	}
+
+	dead, _ := GetWebhookDeliveries(webhook.ID, model.DeliveryDead)
+	if len(dead) != 1 || dead[0].Attempts != 3 || dead[0].LastError == "" {
+		t.Fatalf("expected one dead-lettered delivery, got: %+v", dead)
+	}
+
+	healthy.Store(true)
+	if _, err := ReplayWebhookDelivery(webhook.ID, dead[0].ID); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if delivered := DeliverDueWebhooks(); delivered != 1 {
+		t.Errorf("expected replayed delivery to succeed, got: %d", delivered)
+	}
+}
+
+func TestWebhookTimeout(t *testing.T) {
+	resetWebhooks(t)
+	timeout := WebhookTimeout
+	WebhookTimeout = 50 * time.Millisecond
+	defer func() { WebhookTimeout = timeout }()
+
+	release := make(chan struct{})
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer receiver.Close()
+	defer close(release)
+
+	// Concurrent sends share the client, so the timeout must not be set on it
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			errs <- sendWebhook(model.Webhook{URL: receiver.URL}, model.WebhookDelivery{Event: model.Event{Type: model.EventBlock}})
+		}()
+	}
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err == nil {
+			t.Errorf("expected a slow receiver to time out")
+		}
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	base, max := WebhookRetryBase, WebhookRetryMax
+	WebhookRetryBase, WebhookRetryMax = time.Second, 5*time.Second
+	defer func() { WebhookRetryBase, WebhookRetryMax = base, max }()
+
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, delay := range expected {
+		if got := retryDelay(i + 1); got != delay {
+			t.Errorf("attempt %d: expected %v, got: %v", i+1, delay, got)
+		}
+	}
+}
+
+func TestWebhookFilters(t *testing.T) {
+	webhook := model.Webhook{Addresses: []string{subscribedAddress}, Events: []string{model.EventTransaction, model.EventBlock}}
+
+	tests := []struct {
+		event    model.Event
+		expected bool
+	}{
+		{model.Event{Type: model.EventTransaction, Address: subscribedAddress}, true},
+		{model.Event{Type: model.EventTransaction, Address: "0x0000000000000000000000000000000000000002"}, false},
+		{model.Event{Type: model.EventBlock}, true},
+		{model.Event{Type: model.EventAlert, Address: subscribedAddress}, false},
+	}
+	for _, tt := range tests {
+		if got := webhookMatches(webhook, tt.event); got != tt.expected {
+			t.Errorf("%+v: expected %v, got: %v", tt.event, tt.expected, got)
+		}
+	}
+
+	if _, err := CreateWebhook(model.Webhook{URL: "ftp://example.com"}); err == nil {
+		t.Errorf("expected non-http URL to be rejected")
+	}
+	if _, err := CreateWebhook(model.Webhook{URL: "http://example.com", Events: []string{"bogus"}}); err == nil {
+		t.Errorf("expected unknown event type to be rejected")
+	}
+}
+
+func TestWebhookStatePersists(t *testing.T) {
+	resetWebhooks(t)
+	WebhookStatePath = filepath.Join(t.TempDir(), "webhooks.json")
+	defer func() { WebhookStatePath = "" }()
+
+	webhook, _ := CreateWebhook(model.Webhook{URL: "http://127.0.0.1:1/hook"})
+	publishEvent(model.Event{Type: model.EventBlock})
+	if _, err := os.Stat(WebhookStatePath); !os.IsNotExist(err) {
+		t.Errorf("expected publishing to leave saving to SaveWebhooks, got: %v", err)
+	}
+	if err := SaveWebhooks(); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	resetWebhooks(t)
+	if err := LoadWebhooks(); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	pending, err := GetWebhookDeliveries(webhook.ID, model.DeliveryPending)
+	if err != nil || len(pending) != 1 {
+		t.Errorf("expected the queued delivery to survive a reload, got: %+v, %v", pending, err)
+	}
+}
+
+func TestWebhookPrivateAddresses(t *testing.T) {
+	resetWebhooks(t)
+	WebhookAllowPrivate = false
+	for _, target := range []string{
+		"http://localhost:8080/hook",
+		"http://127.0.0.1/hook",
+		"http://10.0.0.1/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://[::1]/hook",
+		"http://[::ffff:192.168.1.1]/hook",
+		"http://100.64.0.1/hook",
+		"http://0.0.0.0/hook",
+	} {
+		if _, err := CreateWebhook(model.Webhook{URL: target}); err == nil {
+			t.Errorf("expected %s to be rejected", target)
+		}
+	}
+	if _, err := CreateWebhook(model.Webhook{URL: "https://93.184.216.34/hook"}); err != nil {
+		t.Errorf("expected a public address to be accepted, got: %v", err)
+	}
+
+	// Names that resolve to a blocked address are refused when the delivery connects
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("expected the receiver not to be reached")
+	}))
+	defer receiver.Close()
+	err := sendWebhook(model.Webhook{URL: receiver.URL}, model.WebhookDelivery{ID: "1", Event: model.Event{Type: model.EventBlock}})
+	if err == nil || !strings.Contains(err.Error(), "loopback, private or link-local") {
+		t.Errorf("expected the connection to be refused, got: %v", err)
+	}
+}
+
+func TestWebhookDeadHistory(t *testing.T) {
+	resetWebhooks(t)
+	previousAttempts, previousHistory := WebhookMaxAttempts, WebhookDeadHistory
+	WebhookMaxAttempts, WebhookDeadHistory = 1, 2
+	t.Cleanup(func() { WebhookMaxAttempts, WebhookDeadHistory = previousAttempts, previousHistory })
+
+	webhook, _ := CreateWebhook(model.Webhook{URL: "http://127.0.0.1:1/hook"})
+	for i := 0; i < 3; i++ {
+		publishEvent(model.Event{Type: model.EventBlock})
+		DeliverDueWebhooks()
+	}
+	dead, _ := GetWebhookDeliveries(webhook.ID, model.DeliveryDead)
+	if len(dead) != 2 {
+		t.Errorf("expected only the 2 most recent dead deliveries to be kept, got: %d", len(dead))
+	}
+}
@@ -0,0 +1,131 @@
+// Package tracing configures OpenTelemetry tracing: the exporter spans are sent to, sampling and
+// W3C trace context propagation
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Exporters
+const (
+	ExporterNone   = "none"   // Spans are not recorded, but trace context is still propagated
+	ExporterStdout = "stdout" // One JSON span per line on stdout, or in File
+	ExporterOTLP   = "otlp"   // OTLP over HTTP to Endpoint
+)
+
+// Config selects where spans go
+type Config struct {
+	Exporter string
+
+	// Endpoint is the OTLP/HTTP endpoint URL, e.g. http://localhost:4318. When empty, the
+	// OTEL_EXPORTER_OTLP_ENDPOINT environment variable or localhost is used.
+	Endpoint string
+
+	// File is where the stdout exporter appends spans instead of stdout
+	File string
+
+	// SampleRatio is the fraction of new traces recorded; traces started by a caller follow the
+	// caller's sampling decision
+	SampleRatio float64
+
+	ServiceName    string
+	ServiceVersion string
+}
+
+func init() {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+}
+
+// Setup installs the global tracer provider for cfg. The returned function flushes buffered
+// spans and releases the exporter; call it before exiting.
+func Setup(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	var (
+		exporter sdktrace.SpanExporter
+		closer   io.Closer
+		err      error
+	)
+	switch cfg.Exporter {
+	case ExporterNone, "":
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		var w io.Writer = os.Stdout
+		if cfg.File != "" {
+			file, err := os.OpenFile(cfg.File, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+			if err != nil {
+				return nil, fmt.Errorf("opening trace file: %w", err)
+			}
+			w, closer = file, file
+		}
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(w))
+	case ExporterOTLP:
+		var opts []otlptracehttp.Option
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+		}
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q, expected none, stdout or otlp", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("creating %s trace exporter: %w", cfg.Exporter, err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		attribute.String("service.name", cfg.ServiceName),
+		attribute.String("service.version", cfg.ServiceVersion),
+	))
+	if err != nil {
+		return nil, err
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closer != nil {
+			err = errors.Join(err, closer.Close())
+		}
+		return err
+	}, nil
+}
+
+// Tracer returns the tracer of a component. It follows the provider installed by Setup, even
+// when obtained before it.
+func Tracer(component string) trace.Tracer {
+	return otel.Tracer("ethereum-tx-parser/" + component)
+}
+
+// End records err, if any, as the outcome of a span and ends it
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Run runs fn in a child span of ctx named name
+func Run(ctx context.Context, tracer trace.Tracer, name string, fn func(context.Context) error) error {
+	ctx, span := tracer.Start(ctx, name)
+	err := fn(ctx)
+	End(span, err)
+	return err
+}
@@ -0,0 +1,66 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// recordSpans installs a provider that keeps ended spans in memory for the duration of a test
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func TestSetupRejectsUnknownExporter(t *testing.T) {
+	_, err := Setup(context.Background(), Config{Exporter: "zipkin"})
+	assert.Error(t, err)
+}
+
+func TestStdoutExporterWritesFile(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	path := filepath.Join(t.TempDir(), "spans.json")
+	shutdown, err := Setup(context.Background(), Config{Exporter: ExporterStdout, File: path, SampleRatio: 1, ServiceName: "test"})
+	assert.NoError(t, err)
+
+	_, span := Tracer("test").Start(context.Background(), "process block")
+	span.End()
+	assert.NoError(t, shutdown(context.Background()))
+
+	written, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(written), `"Name":"process block"`)
+	assert.Contains(t, string(written), `"Value":"test"`)
+}
+
+func TestRunRecordsChildSpans(t *testing.T) {
+	recorder := recordSpans(t)
+	tracer := Tracer("test")
+
+	ctx, parent := tracer.Start(context.Background(), "process block")
+	assert.NoError(t, Run(ctx, tracer, "fetch block", func(context.Context) error { return nil }))
+	failure := errors.New("node unavailable")
+	assert.Equal(t, failure, Run(ctx, tracer, "advance cursor", func(context.Context) error { return failure }))
+	parent.End()
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 3)
+	assert.Equal(t, "fetch block", spans[0].Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Len(t, spans[1].Events(), 1)
+}
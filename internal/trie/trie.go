@@ -0,0 +1,122 @@
+// Package trie computes Merkle-Patricia trie root hashes as used for Ethereum block commitments
+package trie
+
+import (
+	"bytes"
+	"sort"
+
+	"ethereum-tx-parser/internal/crypto"
+	"ethereum-tx-parser/internal/rlp"
+)
+
+// EmptyRoot is the root hash of a trie with no entries, keccak256(rlp(""))
+var EmptyRoot = crypto.Keccak256(rlp.EncodeBytes(nil))
+
+// entry is a key, expanded to nibbles, and its value
+type entry struct {
+	key   []byte
+	value []byte
+}
+
+// DeriveRoot returns the root hash of the trie containing the given key/value pairs
+func DeriveRoot(keys, values [][]byte) []byte {
+	if len(keys) == 0 {
+		return EmptyRoot
+	}
+
+	entries := make([]entry, len(keys))
+	for i := range keys {
+		entries[i] = entry{toNibbles(keys[i]), values[i]}
+	}
+	sort.Slice(entries, func(i, j int) bool { return bytes.Compare(entries[i].key, entries[j].key) < 0 })
+
+	return crypto.Keccak256(encodeNode(entries, 0))
+}
+
+// DeriveListRoot returns the root hash of a trie keyed by the RLP-encoded index of each item,
+// as used for the transactions, receipts and withdrawals roots
+func DeriveListRoot(items [][]byte) []byte {
+	keys := make([][]byte, len(items))
+	for i := range items {
+		keys[i] = rlp.EncodeUint(uint64(i))
+	}
+	return DeriveRoot(keys, items)
+}
+
+// encodeNode builds the RLP encoding of the node holding the sorted entries, whose keys share
+// their first depth nibbles
+func encodeNode(entries []entry, depth int) []byte {
+	if len(entries) == 1 {
+		return rlp.EncodeList(rlp.EncodeBytes(hexPrefix(entries[0].key[depth:], true)), rlp.EncodeBytes(entries[0].value))
+	}
+
+	// Keys sorted lexicographically share the prefix common to the first and last key
+	first, last := entries[0].key, entries[len(entries)-1].key
+	prefix := depth
+	for prefix < len(first) && prefix < len(last) && first[prefix] == last[prefix] {
+		prefix++
+	}
+	if prefix > depth {
+		child := encodeNode(entries, prefix)
+		return rlp.EncodeList(rlp.EncodeBytes(hexPrefix(first[depth:prefix], false)), reference(child))
+	}
+
+	// Branch node: sixteen children by next nibble plus the value of a key ending here
+	slots := make([][]byte, 17)
+	for i := range slots {
+		slots[i] = rlp.EncodeBytes(nil)
+	}
+	for start := 0; start < len(entries); {
+		if len(entries[start].key) == depth {
+			slots[16] = rlp.EncodeBytes(entries[start].value)
+			start++
+			continue
+		}
+		nibble := entries[start].key[depth]
+		end := start + 1
+		for end < len(entries) && entries[end].key[depth] == nibble {
+			end++
+		}
+		slots[nibble] = reference(encodeNode(entries[start:end], depth+1))
+		start = end
+	}
+	return rlp.EncodeList(slots...)
+}
+
+// reference embeds a child node smaller than 32 bytes directly and refers to larger ones by hash
+func reference(node []byte) []byte {
+	if len(node) < 32 {
+		return node
+	}
+	return rlp.EncodeBytes(crypto.Keccak256(node))
+}
+
+// toNibbles splits each byte of a key into two 4-bit nibbles
+func toNibbles(key []byte) []byte {
+	nibbles := make([]byte, len(key)*2)
+	for i, b := range key {
+		nibbles[i*2] = b >> 4
+		nibbles[i*2+1] = b & 0x0f
+	}
+	return nibbles
+}
+
+// hexPrefix compacts a nibble path, flagging whether it terminates in a leaf and has odd length
+func hexPrefix(nibbles []byte, leaf bool) []byte {
+	flag := byte(0)
+	if leaf {
+		flag = 2
+	}
+
+	var out []byte
+	if len(nibbles)%2 == 1 {
+		out = append(out, (flag+1)<<4|nibbles[0])
+		nibbles = nibbles[1:]
+	} else {
+		out = append(out, flag<<4)
+	}
+	for i := 0; i < len(nibbles); i += 2 {
+		out = append(out, nibbles[i]<<4|nibbles[i+1])
+	}
+	return out
+}
@@ -0,0 +1,40 @@
+package trie
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeriveRoot(t *testing.T) {
+	tests := []struct {
+		name     string
+		pairs    [][2]string
+		expected string
+	}{
+		{"empty", nil, "56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421"},
+		{"dogs", [][2]string{{"doe", "reindeer"}, {"dog", "puppy"}, {"dogglesworth", "cat"}}, "8aad789dff2f538bca5d8ea56e8abe10f4c7ba3a5dea95fea4cd6e7c3a1168d3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var keys, values [][]byte
+			for _, pair := range tt.pairs {
+				keys = append(keys, []byte(pair[0]))
+				values = append(values, []byte(pair[1]))
+			}
+			assert.Equal(t, tt.expected, hex.EncodeToString(DeriveRoot(keys, values)))
+		})
+	}
+}
+
+func TestDeriveRootIsOrderIndependent(t *testing.T) {
+	keys := [][]byte{[]byte("do"), []byte("dog"), []byte("doge"), []byte("horse")}
+	values := [][]byte{[]byte("verb"), []byte("puppy"), []byte("coin"), []byte("stallion")}
+	reversedKeys := [][]byte{keys[3], keys[2], keys[1], keys[0]}
+	reversedValues := [][]byte{values[3], values[2], values[1], values[0]}
+
+	assert.Equal(t, "5991bb8c6514148a29db676a14ac506cd2cd5775ace63c30a4fe457715e9ac84", hex.EncodeToString(DeriveRoot(keys, values)))
+	assert.Equal(t, DeriveRoot(keys, values), DeriveRoot(reversedKeys, reversedValues))
+}
@@ -0,0 +1,64 @@
+package websocket
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAcceptKey(t *testing.T) {
+	// Example from RFC 6455 section 1.3
+	assert.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", acceptKey("dGhlIHNhbXBsZSBub25jZQ=="))
+}
+
+func TestEchoAndClose(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrade(w, r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer conn.Close()
+		for {
+			message, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if string(message) == "bye" {
+				conn.WriteClose(CloseNormal, "done")
+				return
+			}
+			conn.WriteText(message)
+		}
+	}))
+	defer server.Close()
+
+	conn, err := Dial("ws" + strings.TrimPrefix(server.URL, "http"))
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	for _, message := range [][]byte{[]byte("hello"), bytes.Repeat([]byte("x"), 200), bytes.Repeat([]byte("y"), 70000-MaxMessageSize+100)} {
+		assert.NoError(t, conn.WriteText(message))
+		echoed, err := conn.ReadMessage()
+		assert.NoError(t, err)
+		assert.Equal(t, message, echoed)
+	}
+
+	assert.NoError(t, conn.WriteText([]byte("bye")))
+	_, err = conn.ReadMessage()
+	var closeErr *CloseError
+	assert.True(t, errors.As(err, &closeErr))
+	assert.Equal(t, CloseNormal, closeErr.Code)
+	assert.Equal(t, "done", closeErr.Reason)
+}
+
+func TestUpgradeRejectsPlainRequests(t *testing.T) {
+	rec := httptest.NewRecorder()
+	_, err := Upgrade(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Error(t, err)
+}
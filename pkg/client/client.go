@@ -0,0 +1,372 @@
+// Package client is a Go client for the parser's /v1 HTTP API. Client implements parser.Parser,
+// so code written against the interface can run against a remote server.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"ethereum-tx-parser/internal/model"
+	"ethereum-tx-parser/internal/parser"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Aliases of the model types the API returns, so callers outside this module can name them
+type (
+	Transaction = model.Transaction
+	Alert       = model.Alert
+	Quota       = model.Quota
+	BackfillJob = model.BackfillJob
+)
+
+// Client calls the /v1 API of a parser server. It is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+	maxRetries int
+	retryBase  time.Duration
+	retryMax   time.Duration
+}
+
+var _ parser.Parser = (*Client)(nil)
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient sends requests with the given HTTP client instead of one with a 30 second timeout
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) { c.httpClient = httpClient }
+}
+
+// WithAPIKey authenticates every request with an API key issued by the server's admin API
+func WithAPIKey(key string) Option {
+	return func(c *Client) { c.apiKey = key }
+}
+
+// WithRetries sets how many times a failed request is retried (default 3). See Client.do for
+// which failures are retried.
+func WithRetries(maxRetries int) Option {
+	return func(c *Client) { c.maxRetries = maxRetries }
+}
+
+// WithRetryBackoff sets the delay before the first retry and the cap on later delays, which
+// double on each attempt (default 200ms up to 5s)
+func WithRetryBackoff(base time.Duration, max time.Duration) Option {
+	return func(c *Client) { c.retryBase, c.retryMax = base, max }
+}
+
+// New returns a client for the server at baseURL, such as "http://localhost:8080"
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		maxRetries: 3,
+		retryBase:  200 * time.Millisecond,
+		retryMax:   5 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// GetCurrentBlock returns the last block the server has parsed
+func (c *Client) GetCurrentBlock() (int, error) {
+	return c.GetCurrentBlockContext(context.Background())
+}
+
+// GetCurrentBlockContext is GetCurrentBlock with a context
+func (c *Client) GetCurrentBlockContext(ctx context.Context) (int, error) {
+	var block struct {
+		Number int `json:"number"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/v1/blocks/current", nil, &block, nil); err != nil {
+		return 0, err
+	}
+	return block.Number, nil
+}
+
+// Subscribe subscribes an address. Returns false if it was already subscribed.
+func (c *Client) Subscribe(address string) (bool, error) {
+	return c.SubscribeContext(context.Background(), address)
+}
+
+// SubscribeContext is Subscribe with a context
+func (c *Client) SubscribeContext(ctx context.Context, address string) (bool, error) {
+	body, err := json.Marshal(map[string]string{"address": address})
+	if err != nil {
+		return false, err
+	}
+	err = c.do(ctx, http.MethodPost, "/v1/subscriptions", body, nil, nil)
+	if errors.Is(err, ErrConflict) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// SubscribeFromBlock subscribes an address, if it isn't already, and backfills its transactions
+// from a past block on. It returns the backfill job, or nil when the server had not processed
+// that block yet and there is nothing to backfill.
+func (c *Client) SubscribeFromBlock(ctx context.Context, address string, block uint64) (*BackfillJob, error) {
+	return c.subscribeWithBackfill(ctx, map[string]interface{}{"address": address, "fromBlock": block})
+}
+
+// SubscribeFromTime is SubscribeFromBlock starting at the first block mined at or after t
+func (c *Client) SubscribeFromTime(ctx context.Context, address string, t time.Time) (*BackfillJob, error) {
+	return c.subscribeWithBackfill(ctx, map[string]interface{}{"address": address, "fromTimestamp": t.Unix()})
+}
+
+func (c *Client) subscribeWithBackfill(ctx context.Context, request map[string]interface{}) (*BackfillJob, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	var created struct {
+		Backfill *BackfillJob `json:"backfill"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/v1/subscriptions", body, &created, nil); err != nil {
+		return nil, err
+	}
+	return created.Backfill, nil
+}
+
+// GetBackfill returns the progress of a backfill job
+func (c *Client) GetBackfill(ctx context.Context, id string) (BackfillJob, error) {
+	var job BackfillJob
+	err := c.do(ctx, http.MethodGet, "/v1/backfills/"+url.PathEscape(id), nil, &job, nil)
+	return job, err
+}
+
+// Unsubscribe stops observing an address. Returns false if it was not subscribed.
+func (c *Client) Unsubscribe(address string) (bool, error) {
+	return c.UnsubscribeContext(context.Background(), address)
+}
+
+// UnsubscribeContext is Unsubscribe with a context
+func (c *Client) UnsubscribeContext(ctx context.Context, address string) (bool, error) {
+	err := c.do(ctx, http.MethodDelete, "/v1/subscriptions/"+url.PathEscape(address), nil, nil, nil)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// GetTransactions returns every stored transaction of an address
+func (c *Client) GetTransactions(address string) ([]Transaction, error) {
+	return c.GetTransactionsContext(context.Background(), address)
+}
+
+// GetTransactionsContext is GetTransactions with a context. It follows page cursors until the
+// last page.
+func (c *Client) GetTransactionsContext(ctx context.Context, address string) ([]Transaction, error) {
+	transactions := []Transaction{}
+	query := TransactionQuery{Limit: 500}
+	for {
+		page, err := c.ListTransactions(ctx, address, query)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, page.Transactions...)
+
+		if page.NextCursor == "" {
+			return transactions, nil
+		}
+		query.Cursor = page.NextCursor
+	}
+}
+
+// TransactionQuery filters and pages ListTransactions. Zero values leave a filter unset.
+type TransactionQuery struct {
+	Direction    string    // "in" or "out" relative to the address
+	FromBlock    *uint64   // Inclusive lower block bound
+	ToBlock      *uint64   // Inclusive upper block bound
+	FromTime     time.Time // Inclusive lower time bound
+	ToTime       time.Time // Inclusive upper time bound
+	MinValue     *big.Int  // Inclusive lower value bound in wei
+	MaxValue     *big.Int  // Inclusive upper value bound in wei
+	Counterparty string    // Address on the other side of the transfer
+	Status       string    // "pending", "mined", "dropped" or "replaced"
+	Sort         string    // "block" (default) or "time"
+	Order        string    // "asc" (default) or "desc"
+	Limit        int       // Page size, 1-500 (server default 50)
+	Cursor       string    // NextCursor of the previous page
+}
+
+// values encodes the query as URL parameters
+func (q TransactionQuery) values() url.Values {
+	values := url.Values{}
+	set := func(key string, value string) {
+		if value != "" {
+			values.Set(key, value)
+		}
+	}
+	set("direction", q.Direction)
+	if q.FromBlock != nil {
+		set("fromBlock", strconv.FormatUint(*q.FromBlock, 10))
+	}
+	if q.ToBlock != nil {
+		set("toBlock", strconv.FormatUint(*q.ToBlock, 10))
+	}
+	if !q.FromTime.IsZero() {
+		set("fromTime", strconv.FormatInt(q.FromTime.Unix(), 10))
+	}
+	if !q.ToTime.IsZero() {
+		set("toTime", strconv.FormatInt(q.ToTime.Unix(), 10))
+	}
+	if q.MinValue != nil {
+		set("minValue", q.MinValue.String())
+	}
+	if q.MaxValue != nil {
+		set("maxValue", q.MaxValue.String())
+	}
+	set("counterparty", q.Counterparty)
+	set("status", q.Status)
+	set("sort", q.Sort)
+	set("order", q.Order)
+	if q.Limit > 0 {
+		set("limit", strconv.Itoa(q.Limit))
+	}
+	set("cursor", q.Cursor)
+	return values
+}
+
+// TransactionPage is one page of ListTransactions results
+type TransactionPage struct {
+	Transactions []Transaction
+	NextCursor   string // Empty on the last page
+}
+
+// ListTransactions returns one page of the stored transactions of an address
+func (c *Client) ListTransactions(ctx context.Context, address string, query TransactionQuery) (TransactionPage, error) {
+	var page TransactionPage
+	var meta struct {
+		NextCursor string `json:"nextCursor"`
+	}
+	path := "/v1/addresses/" + url.PathEscape(address) + "/transactions"
+	if values := query.values(); len(values) > 0 {
+		path += "?" + values.Encode()
+	}
+	if err := c.do(ctx, http.MethodGet, path, nil, &page.Transactions, &meta); err != nil {
+		return TransactionPage{}, err
+	}
+	page.NextCursor = meta.NextCursor
+	return page, nil
+}
+
+// GetContracts returns the contracts deployed by an address
+func (c *Client) GetContracts(ctx context.Context, address string) ([]string, error) {
+	contracts := []string{}
+	err := c.do(ctx, http.MethodGet, "/v1/addresses/"+url.PathEscape(address)+"/contracts", nil, &contracts, nil)
+	return contracts, err
+}
+
+// GetAlerts returns the pending transaction findings, for one address or all when address is empty
+func (c *Client) GetAlerts(ctx context.Context, address string) ([]Alert, error) {
+	path := "/v1/alerts"
+	if address != "" {
+		path += "?" + url.Values{"address": {address}}.Encode()
+	}
+	alerts := []Alert{}
+	err := c.do(ctx, http.MethodGet, path, nil, &alerts, nil)
+	return alerts, err
+}
+
+// GetQuota returns the caller's subscription count and remaining request budget
+func (c *Client) GetQuota(ctx context.Context) (Quota, error) {
+	var quota Quota
+	err := c.do(ctx, http.MethodGet, "/v1/quota", nil, &quota, nil)
+	return quota, err
+}
+
+// do sends a request and decodes the data and meta of the response envelope into the given
+// values. Requests the server rejected as overloaded (429, 502, 503, 504) are retried; GET and
+// DELETE requests are also retried after a transport error, since sending them twice is harmless.
+func (c *Client) do(ctx context.Context, method string, path string, body []byte, data interface{}, meta interface{}) error {
+	var err error
+	for attempt := 0; ; attempt++ {
+		var retry bool
+		var wait time.Duration
+		retry, wait, err = c.attempt(ctx, method, path, body, data, meta)
+		if !retry || attempt >= c.maxRetries {
+			return err
+		}
+
+		if backoff := c.backoff(attempt); wait < backoff {
+			wait = backoff
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// attempt sends a request once. It reports whether a failure may be retried and how long the
+// server asked the client to wait first.
+func (c *Client) attempt(ctx context.Context, method string, path string, body []byte, data interface{}, meta interface{}) (bool, time.Duration, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return false, 0, err
+	}
+	req.Header.Set("Accept", "application/json")
+	c.authorize(req)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return false, 0, ctx.Err()
+		}
+		return method == http.MethodGet || method == http.MethodDelete, 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		apiErr := readAPIError(resp)
+		return apiErr.temporary(), apiErr.RetryAfter, apiErr
+	}
+	if data == nil && meta == nil {
+		return false, 0, nil
+	}
+
+	envelope := struct {
+		Data interface{} `json:"data"`
+		Meta interface{} `json:"meta"`
+	}{data, meta}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return false, 0, fmt.Errorf("decoding %s response: %w", path, err)
+	}
+	return false, 0, nil
+}
+
+// authorize adds the API key, if any, to a request
+func (c *Client) authorize(req *http.Request) {
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+}
+
+// backoff returns the delay before retry number attempt+1
+func (c *Client) backoff(attempt int) time.Duration {
+	delay := c.retryBase << attempt
+	if delay > c.retryMax || delay <= 0 {
+		return c.retryMax
+	}
+	return delay
+}
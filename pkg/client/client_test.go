@@ -0,0 +1,245 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ethereum-tx-parser/internal/api"
+	"ethereum-tx-parser/internal/model"
+	"ethereum-tx-parser/internal/service"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testAddress = "0x1234567890abcdef1234567890abcdef12345678"
+
+// startServer serves the real API over a fresh store with one subscribed address
+func startServer(t *testing.T) *Client {
+	t.Helper()
+	service.InitializeModelLayer()
+	service.SaveLatestBlock("0x11")
+	service.Subscribe(testAddress)
+	service.FilterTransactionsByAddress([]model.Transaction{
+		{Hash: "0xaaa", From: testAddress, To: "0xbbb", Value: "0x1", BlockNumber: "0x10"},
+		{Hash: "0xccc", From: "0xddd", To: testAddress, Value: "0x2", BlockNumber: "0x11"},
+	})
+
+	server := httptest.NewServer(api.NewRouter())
+	t.Cleanup(server.Close)
+	return New(server.URL, WithRetryBackoff(time.Millisecond, 10*time.Millisecond))
+}
+
+func TestParserMethods(t *testing.T) {
+	c := startServer(t)
+
+	block, err := c.GetCurrentBlock()
+	assert.NoError(t, err)
+	assert.Equal(t, 17, block)
+
+	subscribed, err := c.Subscribe(testAddress)
+	assert.NoError(t, err)
+	assert.False(t, subscribed)
+
+	transactions, err := c.GetTransactions(testAddress)
+	assert.NoError(t, err)
+	assert.Len(t, transactions, 2)
+	assert.Equal(t, "0xaaa", transactions[0].Hash)
+
+	unsubscribed, err := c.Unsubscribe(testAddress)
+	assert.NoError(t, err)
+	assert.True(t, unsubscribed)
+	unsubscribed, err = c.Unsubscribe(testAddress)
+	assert.NoError(t, err)
+	assert.False(t, unsubscribed)
+
+	subscribed, err = c.Subscribe(testAddress)
+	assert.NoError(t, err)
+	assert.True(t, subscribed)
+}
+
+func TestTypedErrors(t *testing.T) {
+	c := startServer(t)
+
+	_, err := c.Subscribe("invalid")
+	assert.ErrorIs(t, err, ErrBadRequest)
+	var apiErr *APIError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+	assert.Equal(t, "/v1/subscriptions", apiErr.Instance)
+	assert.NotEmpty(t, apiErr.Detail)
+
+	_, err = c.ListTransactions(context.Background(), testAddress, TransactionQuery{Direction: "sideways"})
+	assert.ErrorIs(t, err, ErrBadRequest)
+}
+
+func TestListTransactions(t *testing.T) {
+	c := startServer(t)
+	var many []model.Transaction
+	for i := 0; i < 600; i++ {
+		many = append(many, model.Transaction{Hash: fmt.Sprintf("0x%x", 0x1000+i), From: testAddress, To: "0xbbb", BlockNumber: "0x12"})
+	}
+	service.FilterTransactionsByAddress(many)
+
+	fromBlock := uint64(0x11)
+	page, err := c.ListTransactions(context.Background(), testAddress, TransactionQuery{FromBlock: &fromBlock, Direction: "in", Limit: 10})
+	assert.NoError(t, err)
+	assert.Len(t, page.Transactions, 1)
+	assert.Equal(t, "0xccc", page.Transactions[0].Hash)
+	assert.Empty(t, page.NextCursor)
+
+	page, err = c.ListTransactions(context.Background(), testAddress, TransactionQuery{Limit: 10})
+	assert.NoError(t, err)
+	assert.Len(t, page.Transactions, 10)
+	assert.NotEmpty(t, page.NextCursor)
+
+	transactions, err := c.GetTransactions(testAddress)
+	assert.NoError(t, err)
+	assert.Len(t, transactions, 602)
+}
+
+func TestGetContractsAndAlerts(t *testing.T) {
+	c := startServer(t)
+
+	contracts, err := c.GetContracts(context.Background(), testAddress)
+	assert.NoError(t, err)
+	assert.Empty(t, contracts)
+
+	alerts, err := c.GetAlerts(context.Background(), "")
+	assert.NoError(t, err)
+	assert.Empty(t, alerts)
+}
+
+func TestRetries(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		api.NewRouter().ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	service.InitializeModelLayer()
+
+	c := New(server.URL, WithRetryBackoff(time.Millisecond, time.Millisecond))
+	_, err := c.GetCurrentBlock()
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+
+	atomic.StoreInt32(&calls, -10)
+	c = New(server.URL, WithRetries(1), WithRetryBackoff(time.Millisecond, time.Millisecond))
+	_, err = c.GetCurrentBlock()
+	assert.ErrorIs(t, err, ErrServer)
+	assert.Equal(t, int32(-8), atomic.LoadInt32(&calls))
+}
+
+func TestContextCancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	c := New(server.URL, WithRetries(100), WithRetryBackoff(time.Second, time.Second))
+	_, err := c.GetCurrentBlockContext(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestWatchTransactions(t *testing.T) {
+	c := startServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	received := make(chan Transaction, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- c.WatchTransactions(ctx, []string{testAddress}, func(tx Transaction) error {
+			received <- tx
+			return nil
+		})
+	}()
+
+	// The stream only carries events published after it connects, so publish until one arrives
+	var tx Transaction
+	for i := 0; tx.Hash == ""; i++ {
+		service.FilterTransactionsByAddress([]model.Transaction{{Hash: fmt.Sprintf("0x%x", 0x2000+i), From: testAddress, To: "0xbbb"}})
+		select {
+		case tx = <-received:
+		case <-time.After(50 * time.Millisecond):
+			if i == 40 {
+				t.Fatal("timed out waiting for a watched transaction")
+			}
+		}
+	}
+	assert.Equal(t, testAddress, tx.From)
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestWatchTransactionsStopsOnError(t *testing.T) {
+	c := startServer(t)
+
+	err := c.WatchTransactions(context.Background(), nil, func(Transaction) error { return nil })
+	assert.ErrorIs(t, err, ErrBadRequest)
+}
+
+func TestWithAPIKey(t *testing.T) {
+	service.InitializeModelLayer()
+	_, key, err := service.IssueAPIKey("client-test", "")
+	assert.NoError(t, err)
+	service.RequireAPIKey = true
+	t.Cleanup(func() { service.RequireAPIKey = false })
+
+	server := httptest.NewServer(api.NewRouter())
+	t.Cleanup(server.Close)
+
+	_, err = New(server.URL).GetCurrentBlock()
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	c := New(server.URL, WithAPIKey(key))
+	subscribed, err := c.Subscribe(testAddress)
+	assert.NoError(t, err)
+	assert.True(t, subscribed)
+
+	// The subscription belongs to the key's tenant only
+	_, err = c.GetContracts(context.Background(), "0x0000000000000000000000000000000000000002")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestSubscribeFromBlock(t *testing.T) {
+	c := startServer(t)
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"number":"0x1","timestamp":"0x1","transactions":[]}}`))
+	}))
+	t.Cleanup(node.Close)
+	previous := service.EthereumRPCURL
+	service.EthereumRPCURL = node.URL
+	t.Cleanup(func() { service.EthereumRPCURL = previous })
+
+	job, err := c.SubscribeFromBlock(context.Background(), testAddress, 0x11)
+	assert.NoError(t, err)
+	assert.Nil(t, job, "block 0x11 is the next to process, so nothing is backfilled")
+
+	job, err = c.SubscribeFromBlock(context.Background(), testAddress, 0x0e)
+	assert.NoError(t, err)
+	if assert.NotNil(t, job) {
+		assert.EqualValues(t, 0x0e, job.FromBlock)
+		assert.EqualValues(t, 0x10, job.ToBlock)
+		assert.Eventually(t, func() bool {
+			got, err := c.GetBackfill(context.Background(), job.ID)
+			return err == nil && got.State == model.TaskDone
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+
+	_, err = c.GetBackfill(context.Background(), "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
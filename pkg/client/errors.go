@@ -0,0 +1,95 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Errors matched by APIError with errors.Is, by response status
+var (
+	ErrBadRequest   = errors.New("bad request")  // 400, including invalid addresses
+	ErrUnauthorized = errors.New("unauthorized") // 401, a missing, invalid or revoked API key
+	ErrForbidden    = errors.New("forbidden")    // 403, including a full subscription quota
+	ErrNotFound     = errors.New("not found")    // 404
+	ErrConflict     = errors.New("conflict")     // 409
+	ErrRateLimited  = errors.New("rate limited") // 429
+	ErrServer       = errors.New("server error") // 5xx
+)
+
+// APIError is an error response from the server. Its fields come from the RFC 7807 problem
+// details body when the server sent one.
+type APIError struct {
+	StatusCode int
+	Title      string
+	Detail     string
+	Instance   string
+
+	// RetryAfter is the delay the server asked for with a Retry-After header, if any
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	if e.Detail == "" {
+		return fmt.Sprintf("%d %s", e.StatusCode, e.Title)
+	}
+	return fmt.Sprintf("%d %s: %s", e.StatusCode, e.Title, e.Detail)
+}
+
+// Is reports whether the error's status matches one of the package's status errors
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrServer:
+		return e.StatusCode >= 500
+	}
+	return false
+}
+
+// temporary reports whether the request may succeed if sent again
+func (e *APIError) temporary() bool {
+	switch e.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// readAPIError builds an APIError from a failed response
+func readAPIError(resp *http.Response) *APIError {
+	apiErr := &APIError{StatusCode: resp.StatusCode}
+	var problem struct {
+		Title    string `json:"title"`
+		Detail   string `json:"detail"`
+		Instance string `json:"instance"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&problem); err == nil {
+		apiErr.Title, apiErr.Detail, apiErr.Instance = problem.Title, problem.Detail, problem.Instance
+	}
+	if apiErr.Title == "" {
+		apiErr.Title = http.StatusText(resp.StatusCode)
+	}
+
+	if after := resp.Header.Get("Retry-After"); after != "" {
+		if seconds, err := strconv.Atoi(after); err == nil {
+			apiErr.RetryAfter = time.Duration(seconds) * time.Second
+		} else if at, err := http.ParseTime(after); err == nil {
+			apiErr.RetryAfter = time.Until(at)
+		}
+	}
+	return apiErr
+}
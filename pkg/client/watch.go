@@ -0,0 +1,111 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"ethereum-tx-parser/internal/model"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// WatchTransactions calls fn for each transaction event of the addresses, as streamed by the
+// server, until ctx is done, fn returns an error or the server rejects the stream. A dropped
+// connection is resumed from the last event received, so no event is missed or repeated.
+// It returns ctx.Err() when the context ends the watch. ErrUnauthorized and ErrNotFound mean
+// the key is not accepted or its tenant does not subscribe to one of the addresses.
+func (c *Client) WatchTransactions(ctx context.Context, addresses []string, fn func(Transaction) error) error {
+	lastEventID := ""
+	for attempt := 0; ; attempt++ {
+		received, err := c.stream(ctx, addresses, &lastEventID, fn)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		var callbackErr *callbackError
+		if errors.As(err, &callbackErr) {
+			return callbackErr.err
+		}
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && !apiErr.temporary() {
+			return err
+		}
+		if received {
+			attempt = 0
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(c.backoff(attempt)):
+		}
+	}
+}
+
+// stream reads one connection of the transaction event stream and reports whether any event
+// arrived on it
+func (c *Client) stream(ctx context.Context, addresses []string, lastEventID *string, fn func(Transaction) error) (bool, error) {
+	query := url.Values{"address": {strings.Join(addresses, ",")}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/v1/stream/transactions?"+query.Encode(), nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	c.authorize(req)
+	if *lastEventID != "" {
+		req.Header.Set("Last-Event-ID", *lastEventID)
+	}
+
+	// The stream is long-lived, so it must not be cut off by the client's request timeout
+	httpClient := *c.httpClient
+	httpClient.Timeout = 0
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, readAPIError(resp)
+	}
+
+	received := false
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	id := ""
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "id: "):
+			id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "data: "):
+			var event struct {
+				Type string      `json:"type"`
+				Data Transaction `json:"data"`
+			}
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event); err != nil {
+				return received, fmt.Errorf("decoding stream event: %w", err)
+			}
+			received = true
+			if event.Type == model.EventTransaction {
+				if err := fn(event.Data); err != nil {
+					return received, &callbackError{err}
+				}
+			}
+			*lastEventID = id
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return received, err
+	}
+	return received, io.ErrUnexpectedEOF
+}
+
+// callbackError wraps an error returned by a WatchTransactions callback, which ends the watch
+type callbackError struct{ err error }
+
+func (e *callbackError) Error() string { return e.err.Error() }